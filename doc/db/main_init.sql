@@ -15,6 +15,26 @@ CREATE TYPE vsb AS ENUM (
   'public', 'follower', 'direct'
 );
 
+CREATE TYPE ntf AS ENUM (
+  'follow', 'like', 'share', 'reply', 'mention', 'poll', 'quote'
+);
+
+CREATE TYPE rpl AS ENUM (
+  'everyone', 'followers', 'mentioned', 'nobody'
+);
+
+CREATE TYPE lrp AS ENUM (
+  'followed', 'list', 'none'
+);
+
+CREATE TYPE flc AS ENUM (
+  'home', 'notifications', 'public', 'thread', 'profile'
+);
+
+CREATE TYPE fla AS ENUM (
+  'hide', 'warn'
+);
+
 CREATE TYPE kp AS (
   "pub" text,
   "pri" text
@@ -32,20 +52,35 @@ CREATE TABLE IF NOT EXISTS users (
   "createdAt" timestamp NOT NULL,
   "avatar" text,
   "keys" kp, -- NOT NULL
-  "preferences" jsonb DEFAULT '{"postVsb":"public","shareVsb":"public"}'
+  "preferences" jsonb DEFAULT '{"postVsb":"public","shareVsb":"public","sensitive":false}',
+  "search" tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', "username" || ' ' || "nickname" || ' ' || COALESCE("summary", ''))
+  ) STORED
 );
 
 CREATE INDEX user_pf_postVsb ON users USING gin(("preferences"->'postVsb'));
 CREATE INDEX user_pf_shareVsb ON users USING gin(("preferences"->'shareVsb'));
+CREATE INDEX users_search ON users USING gin("search");
 
 CREATE TABLE IF NOT EXISTS follow (
   "from" text,
   "to" varchar(60) CHECK ("to" <> "from"),
+  "date" timestamp NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
   PRIMARY KEY ("from", "to")
 );
 
-CREATE INDEX user_follows ON follow ("from");
-CREATE INDEX user_be_followed ON follow ("to");
+CREATE INDEX user_follows ON follow ("from", "date" DESC, "to" DESC);
+CREATE INDEX user_be_followed ON follow ("to", "date" DESC, "from" DESC);
+
+CREATE TABLE IF NOT EXISTS mutes (
+  "user" varchar(60),
+  "target" varchar(60) CHECK ("target" <> "user"),
+  PRIMARY KEY ("user", "target")
+);
+
+CREATE TABLE IF NOT EXISTS silenced_domains (
+  "domain" text PRIMARY KEY
+);
 
 CREATE VIEW follow_info ("user", "followings", "followers") AS
   WITH followings AS (
@@ -77,10 +112,75 @@ CREATE TABLE IF NOT EXISTS posts (
   "content" text NOT NULL,
   "media" img[] DEFAULT array[]::img[],
   "likes" text[] DEFAULT array[]::text[],
-  "shares" text[] DEFAULT array[]::text[]
+  "shares" text[] DEFAULT array[]::text[],
+  "recipients" text[] DEFAULT array[]::text[],
+  "conversation" bigint,
+  "source" text,
+  "edited" timestamp,
+  "spoiler" text NOT NULL DEFAULT '',
+  "sensitive" boolean NOT NULL DEFAULT FALSE,
+  "quoting" text,
+  "quotable" boolean NOT NULL DEFAULT TRUE,
+  "reply_policy" rpl NOT NULL DEFAULT 'everyone',
+  "search" tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', "spoiler" || ' ' || "content")
+  ) STORED
 );
 
 CREATE INDEX posters ON posts ("user");
+CREATE INDEX posts_public ON posts ("date" DESC, "id" DESC)
+  WHERE "vsb" = 'public' AND "replying" IS NULL;
+CREATE INDEX posts_public_local ON posts ("date" DESC, "id" DESC)
+  WHERE "vsb" = 'public' AND "replying" IS NULL AND STRPOS("user", '@') = 0;
+CREATE INDEX posts_recipients ON posts USING gin("recipients")
+  WHERE "vsb" = 'direct';
+CREATE INDEX posts_conversation ON posts ("conversation", "date" DESC, "id" DESC)
+  WHERE "conversation" IS NOT NULL;
+CREATE INDEX posts_search ON posts USING gin("search");
+
+CREATE TABLE IF NOT EXISTS post_revisions (
+  "id" bigserial PRIMARY KEY,
+  "post" varchar(36) NOT NULL REFERENCES posts("id") ON DELETE CASCADE,
+  "content" text NOT NULL,
+  "source" text,
+  "media" img[] DEFAULT array[]::img[],
+  "spoiler" text NOT NULL DEFAULT '',
+  "sensitive" boolean NOT NULL DEFAULT FALSE,
+  "date" timestamp NOT NULL
+);
+
+CREATE INDEX post_history ON post_revisions ("post", "date" DESC);
+
+CREATE TABLE IF NOT EXISTS polls (
+  "post" varchar(36) PRIMARY KEY REFERENCES posts("id") ON DELETE CASCADE,
+  "options" text[] NOT NULL,
+  "multiple" boolean NOT NULL DEFAULT FALSE,
+  "hide_totals" boolean NOT NULL DEFAULT FALSE,
+  "expires" timestamp NOT NULL,
+  "closed" boolean NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX polls_open ON polls ("expires")
+  WHERE NOT "closed";
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+  "post" varchar(36) NOT NULL REFERENCES polls("post") ON DELETE CASCADE,
+  "user" varchar(60) NOT NULL,
+  "choices" int[] NOT NULL,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("post", "user")
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_posts (
+  "id" varchar(36) PRIMARY KEY,
+  "user" varchar(20) NOT NULL REFERENCES users("username") ON DELETE CASCADE,
+  "date" timestamp NOT NULL,
+  "params" jsonb NOT NULL,
+  "claimed" timestamp NULL
+);
+
+CREATE INDEX scheduled_due ON scheduled_posts ("date");
+CREATE INDEX user_scheduled ON scheduled_posts ("user", "date");
 
 CREATE TABLE IF NOT EXISTS shares (
   "id" varchar(36) NOT NULL,
@@ -91,4 +191,155 @@ CREATE TABLE IF NOT EXISTS shares (
   FOREIGN KEY ("id") REFERENCES posts("id")
 );
 
-CREATE INDEX sharers ON shares ("user");
\ No newline at end of file
+CREATE INDEX sharers ON shares ("user");
+CREATE INDEX post_shares ON shares ("id", "date" DESC, "user" DESC);
+CREATE INDEX recent_shares ON shares ("date");
+
+CREATE TABLE IF NOT EXISTS likes (
+  "id" varchar(36) NOT NULL,
+  "user" varchar(60) NOT NULL,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("id", "user"),
+  FOREIGN KEY ("id") REFERENCES posts("id") ON DELETE CASCADE
+);
+
+CREATE INDEX post_likes ON likes ("id", "date" DESC, "user" DESC);
+CREATE INDEX recent_likes ON likes ("date");
+
+CREATE TABLE IF NOT EXISTS mentions (
+  "id" varchar(36) NOT NULL REFERENCES posts("id") ON DELETE CASCADE,
+  "user" varchar(60) NOT NULL,
+  "index" int NOT NULL,
+  PRIMARY KEY ("id", "user")
+);
+
+CREATE INDEX user_mentioned ON mentions ("user");
+
+CREATE TABLE IF NOT EXISTS post_tags (
+  "id" varchar(36) NOT NULL REFERENCES posts("id") ON DELETE CASCADE,
+  "tag" varchar(100) NOT NULL,
+  "index" int NOT NULL,
+  "date" timestamp NOT NULL,
+  "search" tsvector GENERATED ALWAYS AS (to_tsvector('simple', "tag")) STORED,
+  PRIMARY KEY ("id", "tag")
+);
+
+CREATE INDEX tag_posts ON post_tags ("tag", "date" DESC, "id" DESC);
+CREATE INDEX tags_search ON post_tags USING gin("search");
+CREATE INDEX recent_tags ON post_tags ("date");
+
+CREATE TABLE IF NOT EXISTS tag_follows (
+  "user" varchar(20) NOT NULL REFERENCES users("username") ON DELETE CASCADE,
+  "tag" varchar(100) NOT NULL,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("user", "tag")
+);
+
+CREATE INDEX tag_followers ON tag_follows ("tag");
+
+CREATE TABLE IF NOT EXISTS post_links (
+  "id" varchar(36) NOT NULL REFERENCES posts("id") ON DELETE CASCADE,
+  "url" text NOT NULL,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("id", "url")
+);
+
+CREATE INDEX recent_links ON post_links ("date");
+
+CREATE TABLE IF NOT EXISTS trend_reviews (
+  "kind" varchar(4) NOT NULL,
+  "key" text NOT NULL,
+  "approved" boolean NOT NULL,
+  "admin" varchar(20) NOT NULL,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("kind", "key")
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+  "user" varchar(20) NOT NULL REFERENCES users("username") ON DELETE CASCADE,
+  "post" varchar(36) NOT NULL REFERENCES posts("id") ON DELETE CASCADE,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("user", "post")
+);
+
+CREATE INDEX user_bookmarks ON bookmarks ("user", "date" DESC, "post" DESC);
+
+CREATE TABLE IF NOT EXISTS reactions (
+  "post" varchar(36) NOT NULL REFERENCES posts("id") ON DELETE CASCADE,
+  "user" varchar(60) NOT NULL,
+  "emoji" text NOT NULL,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("post", "user", "emoji")
+);
+
+CREATE TABLE IF NOT EXISTS custom_emojis (
+  "shortcode" varchar(30) NOT NULL,
+  "domain" text NOT NULL DEFAULT '',
+  "url" text NOT NULL,
+  "remote_url" text,
+  "category" text NOT NULL DEFAULT '',
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("shortcode", "domain")
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+  "id" bigserial PRIMARY KEY,
+  "user" varchar(20) NOT NULL REFERENCES users("username") ON DELETE CASCADE,
+  "type" ntf NOT NULL,
+  "actor" varchar(60) NOT NULL,
+  "post" varchar(36),
+  "date" timestamp NOT NULL,
+  "read" boolean NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX user_notifications ON notifications ("user", "id" DESC);
+
+CREATE TABLE IF NOT EXISTS conversations (
+  "id" bigserial PRIMARY KEY,
+  "participants" text[] NOT NULL
+);
+
+CREATE INDEX conversation_participants ON conversations ("participants");
+
+CREATE TABLE IF NOT EXISTS conversation_members (
+  "conversation" bigint NOT NULL REFERENCES conversations("id") ON DELETE CASCADE,
+  "user" varchar(20) NOT NULL REFERENCES users("username") ON DELETE CASCADE,
+  "date" timestamp NOT NULL,
+  "unread" boolean NOT NULL DEFAULT FALSE,
+  PRIMARY KEY ("conversation", "user")
+);
+
+CREATE INDEX user_conversations ON conversation_members ("user", "date" DESC, "conversation" DESC);
+
+CREATE TABLE IF NOT EXISTS lists (
+  "id" varchar(36) PRIMARY KEY,
+  "user" varchar(20) NOT NULL REFERENCES users("username") ON DELETE CASCADE,
+  "title" varchar(100) NOT NULL,
+  "replies" lrp NOT NULL DEFAULT 'list',
+  "date" timestamp NOT NULL
+);
+
+CREATE INDEX user_lists ON lists ("user");
+
+CREATE TABLE IF NOT EXISTS list_members (
+  "list" varchar(36) NOT NULL REFERENCES lists("id") ON DELETE CASCADE,
+  "member" varchar(60) NOT NULL,
+  "date" timestamp NOT NULL,
+  PRIMARY KEY ("list", "member")
+);
+
+CREATE INDEX member_lists ON list_members ("member");
+CREATE INDEX list_members_date ON list_members ("list", "date" DESC, "member" DESC);
+
+CREATE TABLE IF NOT EXISTS filters (
+  "id" varchar(36) PRIMARY KEY,
+  "user" varchar(20) NOT NULL REFERENCES users("username") ON DELETE CASCADE,
+  "phrase" varchar(100) NOT NULL,
+  "contexts" flc[] NOT NULL,
+  "whole_word" boolean NOT NULL DEFAULT TRUE,
+  "action" fla NOT NULL DEFAULT 'warn',
+  "expires" timestamp NULL,
+  "date" timestamp NOT NULL
+);
+
+CREATE INDEX user_filters ON filters ("user");
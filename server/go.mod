@@ -9,17 +9,21 @@ require (
 	github.com/joho/godotenv v1.5.1 // direct
 	github.com/redis/go-redis/v9 v9.5.3 // direct
 	github.com/lib/pq v1.10.9 // direct
+	github.com/gofiber/contrib/websocket v1.3.0 // direct
+	golang.org/x/net v0.18.0 // direct
 )
 
 require (
 	github.com/andybalholm/brotli v1.0.5 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/klauspost/compress v1.17.0 // indirect
+	github.com/fasthttp/websocket v1.5.7 // indirect
+	github.com/klauspost/compress v1.17.3 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/savsgio/gotils v0.0.0-20230208104028-c358bd845dee // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
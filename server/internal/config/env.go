@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/kidommoc/gustrody/internal/utils"
@@ -41,6 +42,14 @@ func loadEnv() {
 	}
 	config.HmacKey = hmacKey
 
+	// usernames of admins, comma-separated. default: none
+	config.Admins = []string{}
+	for _, v := range strings.Split(envmap["ADMINS"], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			config.Admins = append(config.Admins, v)
+		}
+	}
+
 	// logfile path. default: "./logging.log"
 	logfile := envmap["LOGFILE"]
 	if logfile == "" {
@@ -101,4 +110,11 @@ func loadEnv() {
 		mip = 4
 	}
 	config.MaxImgInPost = mip
+
+	// max entries of a cached timeline. default: 800
+	tll, err := strconv.Atoi(envmap["TIMELINE_LENGTH"])
+	if err != nil || tll < 1 {
+		tll = 800
+	}
+	config.TimelineLength = tll
 }
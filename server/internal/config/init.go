@@ -6,6 +6,9 @@ type Config struct {
 	Port    int    `json:"port"`
 	HmacKey string `json:"hmacKey"`
 
+	// users managing the site
+	Admins []string `json:"admins"`
+
 	// logging
 	Logfile  string `json:"logfile"`
 	LogSplit int    `json:"logSplit"`
@@ -22,6 +25,9 @@ type Config struct {
 	// perference
 	MaxContentLength int `json:"maxCotentLength"`
 	MaxImgInPost     int `json:"maxImgInPost"`
+
+	// timeline
+	TimelineLength int `json:"timelineLength"`
 }
 
 var config *Config
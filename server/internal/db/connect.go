@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"sync"
 
 	"github.com/kidommoc/gustrody/internal/config"
 	"github.com/kidommoc/gustrody/internal/logging"
@@ -35,6 +36,7 @@ func (c *absConn[C]) Closed() bool {
 // connection pool
 
 type ConnPool[C Conn] struct {
+	mu       sync.Mutex
 	lg       logging.Logger
 	capacity int
 	using    int
@@ -44,6 +46,8 @@ type ConnPool[C Conn] struct {
 
 // should be async
 func (p *ConnPool[C]) Open() (c C, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.using >= p.capacity {
 		return c, ErrNoConn
 	}
@@ -56,6 +60,8 @@ func (p *ConnPool[C]) Open() (c C, err error) {
 }
 
 func (p *ConnPool[C]) returnConn() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.using > 0 {
 		p.using -= 1
 	}
@@ -76,6 +82,36 @@ func AuthPool(cfg *config.Config, lg logging.Logger) *ConnPool[*RdConn] {
 	return authPoolIns
 }
 
+// timeline pool
+
+var timelinePoolIns *ConnPool[*RdConn] = nil
+
+func TimelinePool(cfg *config.Config, lg logging.Logger) *ConnPool[*RdConn] {
+	if timelinePoolIns != nil {
+		return timelinePoolIns
+	}
+	if cfg == nil || lg == nil {
+		return nil
+	}
+	timelinePoolIns = newRdConnPool(*cfg, lg, redis_timeline)
+	return timelinePoolIns
+}
+
+// stream pool
+
+var streamPoolIns *ConnPool[*RdConn] = nil
+
+func StreamPool(cfg *config.Config, lg logging.Logger) *ConnPool[*RdConn] {
+	if streamPoolIns != nil {
+		return streamPoolIns
+	}
+	if cfg == nil || lg == nil {
+		return nil
+	}
+	streamPoolIns = newRdConnPool(*cfg, lg, redis_stream)
+	return streamPoolIns
+}
+
 // main pool
 
 var mainPoolIns *ConnPool[*PqConn] = nil
@@ -96,6 +132,10 @@ func Init() {
 	logger := logging.Get()
 	AuthPool(&cfg, logger)
 	logger.Info("[Db]Initailized AuthPool")
+	TimelinePool(&cfg, logger)
+	logger.Info("[Db]Initailized TimelinePool")
+	StreamPool(&cfg, logger)
+	logger.Info("[Db]Initailized StreamPool")
 	MainPool(&cfg, logger)
 	logger.Info("[Db]Initailized MainPool")
 }
@@ -91,7 +91,7 @@ func (c *PqConn) BeginTx() (tx *Tx, err error) {
 	logger := c.lg
 	t, e := c.client.Begin()
 	if e != nil {
-		logger.Error("[Db] Cannot start transaction", err)
+		logger.Error("[Db] Cannot start transaction", e)
 		return nil, ErrDbInternal
 	}
 	return &Tx{lg: c.lg, tx: t}, nil
@@ -122,6 +122,17 @@ func (t *Tx) Exec(q string, args ...any) (affected int64, err error) {
 	return
 }
 
+// a no-op once committed, so it can be deferred right after BeginTx
+func (t *Tx) Rollback() error {
+	logger := t.lg
+	err := t.tx.Rollback()
+	if err != nil && err != sql.ErrTxDone {
+		logger.Error("[Db] Cannot rollback transaction", err)
+		return ErrDbInternal
+	}
+	return nil
+}
+
 func (t *Tx) Commit() error {
 	logger := t.lg
 	err := t.tx.Commit()
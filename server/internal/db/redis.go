@@ -2,6 +2,8 @@ package db
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/kidommoc/gustrody/internal/config"
 	"github.com/kidommoc/gustrody/internal/logging"
@@ -9,12 +11,14 @@ import (
 )
 
 const (
-	redis_addr string = "localhost"
-	redis_auth int    = 0
+	redis_addr     string = "localhost"
+	redis_auth     int    = 0
+	redis_timeline int    = 1
+	redis_stream   int    = 2
 )
 
-var redis_conn = []int{2}
-var redis_port = []int{6739}
+var redis_conn = []int{2, 8, 4}
+var redis_port = []int{6739, 6739, 6739}
 
 type RdConn struct {
 	absConn[*RdConn]
@@ -73,6 +77,165 @@ func (c *RdConn) SetString(key string, value string) error {
 	return nil
 }
 
+func (c *RdConn) Exists(key string) (bool, error) {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return false, ErrConnClosed
+	}
+	n, e := c.client.Exists(defaultCtx, key).Result()
+	if e != nil {
+		logger.Error("[Model.Redis] Cannot check key", e)
+		return false, ErrDbInternal
+	}
+	return n > 0, nil
+}
+
+func (c *RdConn) Del(key string) error {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return ErrConnClosed
+	}
+	if e := c.client.Del(defaultCtx, key).Err(); e != nil {
+		logger.Error("[Model.Redis] Cannot delete key", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+func (c *RdConn) Expire(key string, exp time.Duration) error {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return ErrConnClosed
+	}
+	if e := c.client.Expire(defaultCtx, key, exp).Err(); e != nil {
+		logger.Error("[Model.Redis] Cannot set expiration", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+type Z = redis.Z
+
+// add members to a sorted set, then keep only the max members with highest scores.
+// max <= 0 means no trimming
+func (c *RdConn) ZAddTrim(key string, max int64, members ...Z) error {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return ErrConnClosed
+	}
+	pipe := c.client.TxPipeline()
+	pipe.ZAdd(defaultCtx, key, members...)
+	if max > 0 {
+		pipe.ZRemRangeByRank(defaultCtx, key, 0, -(max + 1))
+	}
+	if _, e := pipe.Exec(defaultCtx); e != nil {
+		logger.Error("[Model.Redis] Cannot add to sorted set", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+func (c *RdConn) ZRem(key string, members ...string) error {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return ErrConnClosed
+	}
+	args := make([]interface{}, len(members))
+	for i, v := range members {
+		args[i] = v
+	}
+	if e := c.client.ZRem(defaultCtx, key, args...).Err(); e != nil {
+		logger.Error("[Model.Redis] Cannot remove from sorted set", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// members with score < max, descending by score
+func (c *RdConn) ZRevRangeBefore(key string, max float64, count int64) (list []Z, err error) {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return nil, ErrConnClosed
+	}
+	list, e := c.client.ZRevRangeByScoreWithScores(defaultCtx, key, &redis.ZRangeBy{
+		Max:   "(" + strconv.FormatFloat(max, 'f', -1, 64),
+		Min:   "-inf",
+		Count: count,
+	}).Result()
+	if e != nil {
+		logger.Error("[Model.Redis] Cannot range sorted set", e)
+		return nil, ErrDbInternal
+	}
+	return list, nil
+}
+
+// members with the score, in reverse lexicographical order
+func (c *RdConn) ZRevRangeAt(key string, score float64) (list []Z, err error) {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return nil, ErrConnClosed
+	}
+	s := strconv.FormatFloat(score, 'f', -1, 64)
+	list, e := c.client.ZRevRangeByScoreWithScores(defaultCtx, key, &redis.ZRangeBy{
+		Max: s,
+		Min: s,
+	}).Result()
+	if e != nil {
+		logger.Error("[Model.Redis] Cannot range sorted set", e)
+		return nil, ErrDbInternal
+	}
+	return list, nil
+}
+
+// publish messages to channels, in one round trip
+func (c *RdConn) Publish(channels []string, msg string) error {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return ErrConnClosed
+	}
+	pipe := c.client.Pipeline()
+	for _, ch := range channels {
+		pipe.Publish(defaultCtx, ch, msg)
+	}
+	if _, e := pipe.Exec(defaultCtx); e != nil {
+		logger.Error("[Model.Redis] Cannot publish", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// subscribe channels matching the pattern and handle messages.
+// blocks until the subscription fails
+func (c *RdConn) Listen(pattern string, handler func(channel, payload string)) error {
+	logger := c.lg
+	if c.client == nil {
+		logger.Error("[Model.Redis] Connection is closed.", nil)
+		return ErrConnClosed
+	}
+	ps := c.client.PSubscribe(defaultCtx, pattern)
+	defer ps.Close()
+	if _, e := ps.Receive(defaultCtx); e != nil {
+		logger.Error("[Model.Redis] Cannot subscribe", e)
+		return ErrDbInternal
+	}
+	for {
+		m, e := ps.ReceiveMessage(defaultCtx)
+		if e != nil {
+			logger.Error("[Model.Redis] Subscription broken", e)
+			return ErrDbInternal
+		}
+		handler(m.Channel, m.Payload)
+	}
+}
+
 func newRdConnPool(cfg config.Config, lg logging.Logger, db int) *ConnPool[*RdConn] {
 	p := ConnPool[*RdConn]{
 		lg:       lg,
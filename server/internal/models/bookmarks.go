@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// db
+
+type IPostBookmark interface {
+	// bookmarking twice is ok
+	SetBookmark(user, id string, date time.Time) error
+	RemoveBookmark(user, id string) error
+	// posts bookmarked by the user, descending by date of bookmarking
+	QueryBookmarks(user string, page utils.Page) (list []*Post, err error)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "post"
+func (db *PostDb) SetBookmark(user, id string, date time.Time) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Bookmarks] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+	if !db.IsPostExist(id) {
+		return ErrNotFound
+	}
+
+	qs := ` INSERT INTO bookmarks("user", "post", "date")
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING;`
+	if _, e := conn.Exec(qs, user, id, date.UTC()); e != nil {
+		logger.Error("[Model.Bookmarks] Failed to execute", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "bookmark"
+func (db *PostDb) RemoveBookmark(user, id string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Bookmarks] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM bookmarks
+			WHERE "user" = $1 AND "post" = $2;`
+	r, e := conn.Exec(qs, user, id)
+	if e != nil {
+		logger.Error("[Model.Bookmarks] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryBookmarks(user string, page utils.Page) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Bookmarks] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  posts."id", posts."url", posts."user", posts."date",
+			  posts."vsb", posts."content", posts."media",
+			  CARDINALITY(posts."likes") as "likes",
+			  CARDINALITY(posts."shares") as "shares",
+			  p2."user" AS "replyTo", NULL AS "sharedBy",
+			  b."date" AS "act", posts."recipients", posts."edited",
+			  posts."spoiler", posts."sensitive",
+			  posts."quoting", posts."quotable", posts."reply_policy"
+			FROM bookmarks AS b
+			  JOIN posts ON posts."id" = b."post"
+			  LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			WHERE b."user" = $1 AND %s;`
+	clause, args := pageClause(page, `b."date"`, `b."post"`, []interface{}{user})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Bookmarks] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return reversePage(page, scanPostsWithAct(logger, r)), nil
+}
@@ -0,0 +1,304 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/utils"
+	"github.com/lib/pq"
+)
+
+// models
+
+// direct posts among a set of participants, and replies to them
+type Conversation struct {
+	ID           int64          `json:"id"`
+	Participants pq.StringArray `json:"participants"` // authors and recipients, sorted
+	LastPost     string         `json:"lastPost"`     // id of the newest post readable by the user
+	Date         time.Time      `json:"date"`         // date of the newest post
+	Unread       bool           `json:"unread"`
+}
+
+func (c *Conversation) Cursor() utils.Cursor {
+	return utils.Cursor{Date: c.Date, ID: strconv.FormatInt(c.ID, 10)}
+}
+
+// db
+
+type IConversation interface {
+	IsConversationMember(id int64, user string) bool
+	// add a direct post saved already to the conversation. when id is 0, the conversation of
+	// exactly the participants is used, or created if absent.
+	// it's marked unread for participants of this site except the author
+	SetConversationPost(id int64, post, author string, participants []string, date time.Time) (conversation int64, err error)
+	// conversations of the user, descending by date of the newest post
+	QueryConversations(user string, page utils.Page) (list []*Conversation, err error)
+	// posts of the conversation readable by the user, descending by date
+	QueryConversationPosts(id int64, user string, page utils.Page) (list []*Post, err error)
+	MarkConversationRead(id int64, user string) error
+}
+
+// should implemented with Postgre
+type ConversationDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.PqConn]
+}
+
+var conversationIns *ConversationDb = nil
+
+func ConversationInstance(lg logging.Logger) *ConversationDb {
+	if conversationIns == nil {
+		conversationIns = &ConversationDb{
+			lg:   lg,
+			pool: _db.MainPool(nil, nil),
+		}
+	}
+	return conversationIns
+}
+
+// sorted and deduplicated
+func normalizeParticipants(us []string) pq.StringArray {
+	m := make(map[string]bool)
+	list := make([]string, 0, len(us))
+	for _, u := range us {
+		if !m[u] {
+			m[u] = true
+			list = append(list, u)
+		}
+	}
+	sort.Strings(list)
+	return pq.StringArray(list)
+}
+
+// functions
+
+func (db *ConversationDb) IsConversationMember(id int64, user string) bool {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Conversation] Failed to open a connection", err)
+		return false
+	}
+	defer conn.Close()
+
+	qs := `SELECT 1
+		   FROM conversation_members
+		   WHERE "conversation" = $1 AND "user" = $2;`
+	var n int
+	if e := conn.QueryOne(qs, id, user).Scan(&n); e != nil {
+		if e != sql.ErrNoRows {
+			logger.Error("[Model.Conversation] Cannot query", e)
+		}
+		return false
+	}
+	return true
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "conversation", "post"
+func (db *ConversationDb) SetConversationPost(id int64, post, author string, participants []string, date time.Time) (conversation int64, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Conversation] Failed to open a connection", err)
+		return 0, ErrDbInternal
+	}
+	defer conn.Close()
+
+	ps := normalizeParticipants(append([]string{author}, participants...))
+	tx, e := conn.BeginTx()
+	if e != nil {
+		logger.Error("[Model.Conversation] Cannot start transaction", e)
+		return 0, ErrDbInternal
+	}
+	defer tx.Rollback()
+
+	if id == 0 {
+		qs := ` SELECT "id"
+				FROM conversations
+				WHERE "participants" = $1
+				ORDER BY "id" ASC
+				LIMIT 1;`
+		e := tx.QueryOne(qs, ps).Scan(&id)
+		switch e {
+		case nil:
+		case sql.ErrNoRows:
+			qs = `  INSERT INTO conversations("participants")
+					VALUES ($1)
+					RETURNING "id";`
+			if e := tx.QueryOne(qs, ps).Scan(&id); e != nil {
+				logger.Error("[Model.Conversation] Failed to execute", e)
+				return 0, ErrDbInternal
+			}
+		default:
+			logger.Error("[Model.Conversation] Cannot query", e)
+			return 0, ErrDbInternal
+		}
+	} else {
+		// replies may bring in new participants
+		qs := ` UPDATE conversations
+				SET "participants" = ARRAY(
+				  SELECT DISTINCT UNNEST("participants" || $2::text[]) ORDER BY 1
+				)
+				WHERE "id" = $1;`
+		r, e := tx.Exec(qs, id, ps)
+		if e != nil {
+			logger.Error("[Model.Conversation] Failed to execute", e)
+			return 0, ErrDbInternal
+		}
+		if r == 0 {
+			return 0, ErrNotFound
+		}
+	}
+
+	// local users have no domain part
+	qs := ` INSERT INTO conversation_members("conversation", "user", "date", "unread")
+			SELECT $1, u, $3, u <> $2
+			FROM UNNEST($4::text[]) AS u
+			WHERE STRPOS(u, '@') = 0
+			ON CONFLICT ("conversation", "user") DO UPDATE
+			SET
+			  "date" = GREATEST(conversation_members."date", EXCLUDED."date"),
+			  "unread" = EXCLUDED."unread";`
+	if _, e := tx.Exec(qs, id, author, date.UTC(), ps); e != nil {
+		logger.Error("[Model.Conversation] Failed to execute", e)
+		return 0, ErrDbInternal
+	}
+
+	qs = `  UPDATE posts
+			SET "conversation" = $1
+			WHERE "id" = $2;`
+	r, e := tx.Exec(qs, id, post)
+	if e != nil {
+		logger.Error("[Model.Conversation] Failed to execute", e)
+		return 0, ErrDbInternal
+	}
+	if r == 0 {
+		return 0, ErrNotFound
+	}
+
+	if e := tx.Commit(); e != nil {
+		logger.Error("[Model.Conversation] Cannot commit", e)
+		return 0, ErrDbInternal
+	}
+	return id, nil
+}
+
+// conversations without posts readable by the user are skipped
+//
+// ERRORS
+//
+//   - DbInternal
+func (db *ConversationDb) QueryConversations(user string, page utils.Page) (list []*Conversation, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Conversation] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  m."conversation", c."participants", lp."id", m."date", m."unread"
+			FROM conversation_members AS m
+			  JOIN conversations AS c ON c."id" = m."conversation"
+			  CROSS JOIN LATERAL (
+			    SELECT "id"
+			    FROM posts
+			    WHERE
+			      "conversation" = m."conversation"
+			      AND ("user" = $1 OR $1 = ANY("recipients"))
+			    ORDER BY "date" DESC
+			    LIMIT 1
+			  ) AS lp
+			WHERE m."user" = $1 AND %s;`
+	clause, args := pageClause(page, `m."date"`, `m."conversation"::text`, []interface{}{user})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Conversation] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+
+	list = make([]*Conversation, 0)
+	for r.Next() {
+		c := Conversation{}
+		if e := r.Scan(&c.ID, &c.Participants, &c.LastPost, &c.Date, &c.Unread); e != nil {
+			logger.Error("[Model.Conversation] Cannot scan row", e)
+			continue
+		}
+		list = append(list, &c)
+	}
+	return reversePage(page, list), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *ConversationDb) QueryConversationPosts(id int64, user string, page utils.Page) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Conversation] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  posts."id", posts."url", posts."user", posts."date",
+			  posts."vsb", posts."content", posts."media",
+			  CARDINALITY(posts."likes") as "likes",
+			  CARDINALITY(posts."shares") as "shares",
+			  p2."user" AS "replyTo", NULL AS "sharedBy",
+			  posts."date" AS "act", posts."recipients", posts."edited",
+			  posts."spoiler", posts."sensitive",
+			  posts."quoting", posts."quotable", posts."reply_policy"
+			FROM posts
+			  LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			WHERE
+			  posts."conversation" = $1
+			  AND (posts."user" = $2 OR $2 = ANY(posts."recipients"))
+			  AND %s;`
+	clause, args := pageClause(page, `posts."date"`, `posts."id"`, []interface{}{id, user})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Conversation] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return reversePage(page, scanPostsWithAct(logger, r)), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "conversation"
+func (db *ConversationDb) MarkConversationRead(id int64, user string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Conversation] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` UPDATE conversation_members
+			SET "unread" = FALSE
+			WHERE "conversation" = $1 AND "user" = $2;`
+	r, e := conn.Exec(qs, id, user)
+	if e != nil {
+		logger.Error("[Model.Conversation] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/test"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+var cvtcfg = config.Config{
+	PqUser:   "penguin",
+	PqSecret: "postgres",
+	RdSecret: "redis",
+}
+
+// unread of the conversation for the user
+func cvtUnread(t *testing.T, cvDb *ConversationDb, user string, id int64) bool {
+	list, err := cvDb.QueryConversations(user, utils.Page{Limit: 20})
+	test.AssertNoError(t, err, "Error when query: %+v")
+	for _, v := range list {
+		if v.ID == id {
+			return v.Unread
+		}
+	}
+	t.Fatalf("Conversation %d not found for %s", id, user)
+	return false
+}
+
+func TestConversationUnread(t *testing.T) {
+	d := time.Now().UTC()
+
+	logger := test.NewMockingLogger(t)
+	mp := db.MainPool(&cvtcfg, logger)
+	userDb := &UserDb{logger, mp}
+	postDb := &PostDb{lg: logger, pool: mp}
+	cvDb := &ConversationDb{lg: logger, pool: mp}
+
+	users := []string{"cva", "cvb"}
+	posts := []string{"cv1", "cv2"}
+	var id int64
+	t.Cleanup(func() {
+		conn, _ := mp.Open()
+		conn.Exec("DELETE FROM conversations WHERE \"id\" = $1;", id)
+		for _, v := range posts {
+			conn.Exec("DELETE FROM posts WHERE \"id\" = $1;", v)
+		}
+		for _, v := range users {
+			conn.Exec("DELETE FROM users WHERE \"username\" = $1;", v)
+		}
+	})
+
+	for _, v := range users {
+		u := User{Username: v, Nickname: v}
+		u.Keys.Pub, u.Keys.Pri = utils.NewKeyPair()
+		err := userDb.SetUser(&u)
+		test.AssertNoError(t, err, "Error when set user: %+v")
+	}
+
+	// remote participants are not members
+	p := Post{ID: posts[0], User: "cva", Date: d, Vsb: utils.Vsb_DIRECT,
+		Content: "hi", Recipients: []string{"cvb", "r@remote.example"}}
+	err := postDb.SetPost(&p, nil)
+	test.AssertNoError(t, err, "Error when set post: %+v")
+	id, err = cvDb.SetConversationPost(0, p.ID, p.User, p.Recipients, p.Date)
+	test.AssertNoError(t, err, "Error when join: %+v")
+	test.AssertEqual(t, false, cvtUnread(t, cvDb, "cva", id))
+	test.AssertEqual(t, true, cvtUnread(t, cvDb, "cvb", id))
+	test.AssertEqual(t, false, cvDb.IsConversationMember(id, "r@remote.example"))
+
+	got, err := postDb.QueryPostByID(p.ID)
+	test.AssertNoError(t, err, "Error when query post: %+v")
+	test.AssertEqual(t, id, got.Conversation)
+
+	err = cvDb.MarkConversationRead(id, "cvb")
+	test.AssertNoError(t, err, "Error when mark read: %+v")
+	test.AssertEqual(t, false, cvtUnread(t, cvDb, "cvb", id))
+
+	// a reply marks it unread for the others
+	r := Post{ID: posts[1], User: "cvb", Date: d.Add(time.Minute), Vsb: utils.Vsb_DIRECT,
+		Replying: p.ID, Content: "hey", Recipients: []string{"cva", "r@remote.example"}}
+	err = postDb.SetPost(&r, nil)
+	test.AssertNoError(t, err, "Error when set post: %+v")
+	got2, err := cvDb.SetConversationPost(id, r.ID, r.User, r.Recipients, r.Date)
+	test.AssertNoError(t, err, "Error when join: %+v")
+	test.AssertEqual(t, id, got2)
+	test.AssertEqual(t, true, cvtUnread(t, cvDb, "cva", id))
+	test.AssertEqual(t, false, cvtUnread(t, cvDb, "cvb", id))
+
+	// posts not saved cannot join
+	_, err = cvDb.SetConversationPost(0, "cvx", "cva", []string{"cvb", "r@remote.example"}, d)
+	test.AssertEqual(t, ErrNotFound, err)
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/lib/pq"
+)
+
+// models
+
+type CustomEmoji struct {
+	Shortcode string    `json:"shortcode"` // without colons
+	Domain    string    `json:"domain"`    // empty if of this site
+	Url       string    `json:"url"`       // image stored on this site
+	RemoteUrl string    `json:"remoteUrl"` // image on the site of it. empty if of this site
+	Category  string    `json:"category"`
+	Date      time.Time `json:"date"` // of uploading or caching
+}
+
+// db
+
+type ICustomEmoji interface {
+	SetEmoji(e *CustomEmoji) error
+	// replace the emoji of the same shortcode and domain, or set it if absent.
+	// used when caching emoji of other sites
+	ReplaceEmoji(e *CustomEmoji) error
+	RemoveEmoji(shortcode, domain string) error
+	// emoji of the domain, ordered by category and shortcode
+	QueryEmojis(domain string) (list []*CustomEmoji, err error)
+	// emoji of the domain by shortcodes. ones not found are absent
+	QueryEmojisByShortcodes(domain string, shortcodes []string) (list []*CustomEmoji, err error)
+}
+
+// should implemented with Postgre
+type EmojiDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.PqConn]
+}
+
+var emojiIns *EmojiDb = nil
+
+func EmojiInstance(lg logging.Logger) *EmojiDb {
+	if emojiIns == nil {
+		emojiIns = &EmojiDb{
+			lg:   lg,
+			pool: _db.MainPool(nil, nil),
+		}
+	}
+	return emojiIns
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+//   - Dunplicate "emoji"
+func (db *EmojiDb) SetEmoji(e *CustomEmoji) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Emoji] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO custom_emojis(
+			  "shortcode", "domain", "url", "remote_url", "category", "date"
+			)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+			ON CONFLICT DO NOTHING;`
+	r, x := conn.Exec(qs, e.Shortcode, e.Domain, e.Url, e.RemoteUrl, e.Category, e.Date.UTC())
+	if x != nil {
+		logger.Error("[Model.Emoji] Failed to execute", x)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrDunplicate
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *EmojiDb) ReplaceEmoji(e *CustomEmoji) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Emoji] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO custom_emojis(
+			  "shortcode", "domain", "url", "remote_url", "category", "date"
+			)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+			ON CONFLICT ("shortcode", "domain") DO UPDATE
+			SET
+			  "url" = EXCLUDED."url", "remote_url" = EXCLUDED."remote_url",
+			  "category" = EXCLUDED."category", "date" = EXCLUDED."date";`
+	if _, x := conn.Exec(qs, e.Shortcode, e.Domain, e.Url, e.RemoteUrl, e.Category, e.Date.UTC()); x != nil {
+		logger.Error("[Model.Emoji] Failed to execute", x)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "emoji"
+func (db *EmojiDb) RemoveEmoji(shortcode, domain string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Emoji] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM custom_emojis
+			WHERE "shortcode" = $1 AND "domain" = $2;`
+	r, e := conn.Exec(qs, shortcode, domain)
+	if e != nil {
+		logger.Error("[Model.Emoji] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *EmojiDb) QueryEmojis(domain string) (list []*CustomEmoji, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Emoji] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  "shortcode", "domain", "url", "remote_url", "category", "date"
+			FROM custom_emojis
+			WHERE "domain" = $1
+			ORDER BY "category" ASC, "shortcode" ASC;`
+	r, e := conn.Query(qs, domain)
+	if e != nil {
+		logger.Error("[Model.Emoji] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanEmojis(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *EmojiDb) QueryEmojisByShortcodes(domain string, shortcodes []string) (list []*CustomEmoji, err error) {
+	logger := db.lg
+	if len(shortcodes) == 0 {
+		return make([]*CustomEmoji, 0), nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Emoji] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  "shortcode", "domain", "url", "remote_url", "category", "date"
+			FROM custom_emojis
+			WHERE "domain" = $1 AND "shortcode" = ANY($2);`
+	r, e := conn.Query(qs, domain, pq.Array(shortcodes))
+	if e != nil {
+		logger.Error("[Model.Emoji] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanEmojis(logger, r), nil
+}
+
+func scanEmojis(logger logging.Logger, r *sql.Rows) (list []*CustomEmoji) {
+	list = make([]*CustomEmoji, 0)
+	for r.Next() {
+		e := CustomEmoji{}
+		var rmt sql.NullString
+		if x := r.Scan(
+			&e.Shortcode, &e.Domain, &e.Url, &rmt, &e.Category, &e.Date,
+		); x != nil {
+			logger.Error("[Model.Emoji] Cannot scan row", x)
+			continue
+		}
+		if rmt.Valid {
+			e.RemoteUrl = rmt.String
+		}
+		list = append(list, &e)
+	}
+	return list
+}
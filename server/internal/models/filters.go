@@ -0,0 +1,298 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/lib/pq"
+)
+
+// models
+
+// where a filter applies
+type FilterContext string
+
+const (
+	Flc_HOME          FilterContext = "home" // home and list timelines
+	Flc_NOTIFICATIONS FilterContext = "notifications"
+	Flc_PUBLIC        FilterContext = "public" // public and hashtag timelines
+	Flc_THREAD        FilterContext = "thread"
+	Flc_PROFILE       FilterContext = "profile"
+)
+
+func GetFilterContext(literal string) (c FilterContext, ok bool) {
+	switch c = FilterContext(literal); c {
+	case Flc_HOME, Flc_NOTIFICATIONS, Flc_PUBLIC, Flc_THREAD, Flc_PROFILE:
+		return c, true
+	}
+	return "", false
+}
+
+// what to do with posts matching a filter
+type FilterAction string
+
+const (
+	Fla_HIDE FilterAction = "hide" // removed from results
+	Fla_WARN FilterAction = "warn" // annotated, for clients to collapse
+)
+
+func GetFilterAction(literal string) (a FilterAction, ok bool) {
+	switch a = FilterAction(literal); a {
+	case Fla_HIDE, Fla_WARN:
+		return a, true
+	}
+	return "", false
+}
+
+// a word or phrase filtering posts for a user
+type Filter struct {
+	ID        string         `json:"id"`
+	User      string         `json:"user"`
+	Phrase    string         `json:"phrase"`
+	Contexts  pq.StringArray `json:"contexts"`
+	WholeWord bool           `json:"wholeWord"`
+	Action    FilterAction   `json:"action"`
+	Expires   time.Time      `json:"expires"` // zero if never
+	Date      time.Time      `json:"date"`    // of creating
+}
+
+// db
+
+type IFilter interface {
+	// filters of the user including expired ones, descending by date
+	QueryFilters(user string) (list []*Filter, err error)
+	// filters of the user in the context, not expired at now
+	QueryActiveFilters(user string, context FilterContext, now time.Time) (list []*Filter, err error)
+	QueryFilterByID(id string) (f *Filter, err error)
+	SetFilter(f *Filter) error
+	// uses: Filter.ID, Filter.Phrase, Filter.Contexts, Filter.WholeWord, Filter.Action, Filter.Expires
+	UpdateFilter(f *Filter) error
+	RemoveFilter(id string) error
+}
+
+// should implemented with Postgre
+type FilterDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.PqConn]
+}
+
+var filterIns *FilterDb = nil
+
+func FilterInstance(lg logging.Logger) *FilterDb {
+	if filterIns == nil {
+		filterIns = &FilterDb{
+			lg:   lg,
+			pool: _db.MainPool(nil, nil),
+		}
+	}
+	return filterIns
+}
+
+// zero time is stored as NULL
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *FilterDb) QueryFilters(user string) (list []*Filter, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Filter] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  "id", "user", "phrase", "contexts", "whole_word",
+			  "action", "expires", "date"
+			FROM filters
+			WHERE "user" = $1
+			ORDER BY "date" DESC, "id" DESC;`
+	r, e := conn.Query(qs, user)
+	if e != nil {
+		logger.Error("[Model.Filter] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanFilters(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *FilterDb) QueryActiveFilters(user string, context FilterContext, now time.Time) (list []*Filter, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Filter] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  "id", "user", "phrase", "contexts", "whole_word",
+			  "action", "expires", "date"
+			FROM filters
+			WHERE
+			  "user" = $1 AND $2 = ANY("contexts")
+			  AND ("expires" IS NULL OR "expires" > $3);`
+	r, e := conn.Query(qs, user, string(context), now.UTC())
+	if e != nil {
+		logger.Error("[Model.Filter] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanFilters(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "filter"
+func (db *FilterDb) QueryFilterByID(id string) (f *Filter, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Filter] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  "id", "user", "phrase", "contexts", "whole_word",
+			  "action", "expires", "date"
+			FROM filters
+			WHERE "id" = $1;`
+	r, e := conn.Query(qs, id)
+	if e != nil {
+		logger.Error("[Model.Filter] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	list := scanFilters(logger, r)
+	if len(list) == 0 {
+		return nil, ErrNotFound
+	}
+	return list[0], nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - Dunplicate "filter"
+func (db *FilterDb) SetFilter(f *Filter) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Filter] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO filters(
+			  "id", "user", "phrase", "contexts", "whole_word",
+			  "action", "expires", "date"
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT DO NOTHING;`
+	r, e := conn.Exec(qs,
+		f.ID, f.User, f.Phrase, f.Contexts, f.WholeWord,
+		string(f.Action), nullTime(f.Expires), f.Date.UTC(),
+	)
+	if e != nil {
+		logger.Error("[Model.Filter] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrDunplicate
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "filter"
+func (db *FilterDb) UpdateFilter(f *Filter) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Filter] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` UPDATE filters
+			SET
+			  "phrase" = $2, "contexts" = $3, "whole_word" = $4,
+			  "action" = $5, "expires" = $6
+			WHERE "id" = $1;`
+	r, e := conn.Exec(qs,
+		f.ID, f.Phrase, f.Contexts, f.WholeWord,
+		string(f.Action), nullTime(f.Expires),
+	)
+	if e != nil {
+		logger.Error("[Model.Filter] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "filter"
+func (db *FilterDb) RemoveFilter(id string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Filter] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM filters
+			WHERE "id" = $1;`
+	r, e := conn.Exec(qs, id)
+	if e != nil {
+		logger.Error("[Model.Filter] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanFilters(logger logging.Logger, r *sql.Rows) (list []*Filter) {
+	list = make([]*Filter, 0)
+	for r.Next() {
+		f := Filter{}
+		var exp sql.NullTime
+		if e := r.Scan(
+			&f.ID, &f.User, &f.Phrase, &f.Contexts, &f.WholeWord,
+			&f.Action, &exp, &f.Date,
+		); e != nil {
+			logger.Error("[Model.Filter] Cannot scan row", e)
+			continue
+		}
+		if exp.Valid {
+			f.Expires = exp.Time
+		}
+		list = append(list, &f)
+	}
+	return list
+}
@@ -18,6 +18,24 @@ func Init() {
 	logger.Info("[Models] Initailized UserDb")
 	PostInstance(logger)
 	logger.Info("[Models] Initailized PostDb")
+	TimelineInstance(logger)
+	logger.Info("[Models] Initailized TimelineDb")
+	NotificationInstance(logger)
+	logger.Info("[Models] Initailized NotificationDb")
+	StreamInstance(logger)
+	logger.Info("[Models] Initailized StreamDb")
+	ConversationInstance(logger)
+	logger.Info("[Models] Initailized ConversationDb")
+	EmojiInstance(logger)
+	logger.Info("[Models] Initailized EmojiDb")
+	ListInstance(logger)
+	logger.Info("[Models] Initailized ListDb")
+	FilterInstance(logger)
+	logger.Info("[Models] Initailized FilterDb")
+	TrendInstance(logger)
+	logger.Info("[Models] Initailized TrendDb")
+	TrendCacheInstance(logger)
+	logger.Info("[Models] Initailized TrendCacheDb")
 }
 
 func registerTestUsers(db IAuthDb) {
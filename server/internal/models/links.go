@@ -0,0 +1,54 @@
+package models
+
+import (
+	"github.com/lib/pq"
+)
+
+// db
+
+type IPostLink interface {
+	// replace urls linked in the post
+	SetLinks(id string, urls []string) error
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) SetLinks(id string, urls []string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Links] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	tx, e := conn.BeginTx()
+	if e != nil {
+		logger.Error("[Model.Links] Cannot start transaction", e)
+		return ErrDbInternal
+	}
+	defer tx.Rollback()
+	qs := ` DELETE FROM post_links
+			WHERE "id" = $1;`
+	if _, e := tx.Exec(qs, id); e != nil {
+		logger.Error("[Model.Links] Failed to execute", e)
+		return ErrDbInternal
+	}
+	qs = `  INSERT INTO post_links("id", "url", "date")
+			SELECT $1, u, posts."date"
+			FROM UNNEST($2::text[]) AS m(u)
+			  JOIN posts ON posts."id" = $1
+			ON CONFLICT DO NOTHING;`
+	if _, e := tx.Exec(qs, id, pq.Array(urls)); e != nil {
+		logger.Error("[Model.Links] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if e := tx.Commit(); e != nil {
+		logger.Error("[Model.Links] Cannot commit", e)
+		return ErrDbInternal
+	}
+	return nil
+}
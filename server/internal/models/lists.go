@@ -0,0 +1,390 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/utils"
+	"github.com/lib/pq"
+)
+
+// models
+
+// which replies by members land in a list timeline
+type RepliesPolicy string
+
+const (
+	Lrp_FOLLOWED RepliesPolicy = "followed" // replies to users the owner follows, and to the owner
+	Lrp_LIST     RepliesPolicy = "list"     // replies to members of the list
+	Lrp_NONE     RepliesPolicy = "none"
+)
+
+func GetRepliesPolicy(literal string) (p RepliesPolicy, ok bool) {
+	switch p = RepliesPolicy(literal); p {
+	case Lrp_FOLLOWED, Lrp_LIST, Lrp_NONE:
+		return p, true
+	}
+	return "", false
+}
+
+// a named group of users followed by its owner, read as a timeline
+type List struct {
+	ID      string        `json:"id"`
+	User    string        `json:"user"` // owner
+	Title   string        `json:"title"`
+	Replies RepliesPolicy `json:"replies"`
+	Date    time.Time     `json:"date"` // of creating
+}
+
+// db
+
+type IList interface {
+	// lists of the user, ordered by title
+	QueryLists(user string) (list []*List, err error)
+	QueryListByID(id string) (l *List, err error)
+	SetList(l *List) error
+	// uses: List.ID, List.Title, List.Replies
+	UpdateList(l *List) error
+	// members are removed with the list
+	RemoveList(id string) error
+}
+
+type IListMember interface {
+	// members of the list, descending by date of adding
+	QueryListMembers(id string, page utils.Page) (list []*UserAct, err error)
+	// ids of lists having the member, into which a post by the member fans out.
+	// replyTo is the user replied, empty if not a reply, when the replies policies apply
+	QueryListsOfMember(member, replyTo string) (ids []string, err error)
+	// members already in the list are skipped
+	SetListMembers(id string, members []string, date time.Time) error
+	RemoveListMembers(id string, members []string) error
+	// remove the member from all lists of the owner, such as when unfollowed.
+	// ids are of the lists the member was in
+	RemoveMemberFromLists(owner, member string) (ids []string, err error)
+}
+
+// should implemented with Postgre
+type ListDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.PqConn]
+}
+
+var listIns *ListDb = nil
+
+func ListInstance(lg logging.Logger) *ListDb {
+	if listIns == nil {
+		listIns = &ListDb{
+			lg:   lg,
+			pool: _db.MainPool(nil, nil),
+		}
+	}
+	return listIns
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *ListDb) QueryLists(user string) (list []*List, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.List] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "id", "user", "title", "replies", "date"
+			FROM lists
+			WHERE "user" = $1
+			ORDER BY "title", "id";`
+	r, e := conn.Query(qs, user)
+	if e != nil {
+		logger.Error("[Model.List] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+
+	list = make([]*List, 0)
+	for r.Next() {
+		l := List{}
+		if e := r.Scan(&l.ID, &l.User, &l.Title, &l.Replies, &l.Date); e != nil {
+			logger.Error("[Model.List] Cannot scan row", e)
+			continue
+		}
+		list = append(list, &l)
+	}
+	return list, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "list"
+func (db *ListDb) QueryListByID(id string) (l *List, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.List] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "id", "user", "title", "replies", "date"
+			FROM lists
+			WHERE "id" = $1;`
+	l = &List{}
+	if e := conn.QueryOne(qs, id).Scan(&l.ID, &l.User, &l.Title, &l.Replies, &l.Date); e != nil {
+		switch e {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			logger.Error("[Model.List] Cannot query", e)
+			return nil, ErrDbInternal
+		}
+	}
+	return l, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - Dunplicate "list"
+func (db *ListDb) SetList(l *List) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.List] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO lists("id", "user", "title", "replies", "date")
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT DO NOTHING;`
+	r, e := conn.Exec(qs, l.ID, l.User, l.Title, string(l.Replies), l.Date.UTC())
+	if e != nil {
+		logger.Error("[Model.List] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrDunplicate
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "list"
+func (db *ListDb) UpdateList(l *List) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.List] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` UPDATE lists
+			SET "title" = $2, "replies" = $3
+			WHERE "id" = $1;`
+	r, e := conn.Exec(qs, l.ID, l.Title, string(l.Replies))
+	if e != nil {
+		logger.Error("[Model.List] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "list"
+func (db *ListDb) RemoveList(id string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.List] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM lists
+			WHERE "id" = $1;`
+	r, e := conn.Exec(qs, id)
+	if e != nil {
+		logger.Error("[Model.List] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *ListDb) QueryListMembers(id string, page utils.Page) (list []*UserAct, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.ListMember] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "member" AS "user", "date"
+			FROM list_members
+			WHERE "list" = $1 AND %s;`
+	clause, args := pageClause(page, `"date"`, `"member"`, []interface{}{id})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.ListMember] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return reversePage(page, scanUserActs(logger, r)), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *ListDb) QueryListsOfMember(member, replyTo string) (ids []string, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.ListMember] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT lists."id"
+			FROM list_members AS m
+			  JOIN lists ON lists."id" = m."list"
+			WHERE
+			  m."member" = $1
+			  AND (
+			    $2 = ''
+			    OR (lists."replies" = 'followed' AND (
+			      $2 = lists."user"
+			      OR EXISTS (
+			        SELECT 1 FROM follow WHERE "from" = lists."user" AND "to" = $2
+			      )
+			    ))
+			    OR (lists."replies" = 'list' AND EXISTS (
+			      SELECT 1 FROM list_members WHERE "list" = lists."id" AND "member" = $2
+			    ))
+			  );`
+	r, e := conn.Query(qs, member, replyTo)
+	if e != nil {
+		logger.Error("[Model.ListMember] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+
+	ids = make([]string, 0)
+	for r.Next() {
+		var id string
+		if e := r.Scan(&id); e != nil {
+			logger.Error("[Model.ListMember] Cannot scan row", e)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *ListDb) SetListMembers(id string, members []string, date time.Time) error {
+	logger := db.lg
+	if len(members) == 0 {
+		return nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.ListMember] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO list_members("list", "member", "date")
+			SELECT $1, m, $3
+			FROM UNNEST($2::text[]) AS m
+			ON CONFLICT DO NOTHING;`
+	if _, e := conn.Exec(qs, id, pq.Array(members), date.UTC()); e != nil {
+		logger.Error("[Model.ListMember] Failed to execute", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *ListDb) RemoveListMembers(id string, members []string) error {
+	logger := db.lg
+	if len(members) == 0 {
+		return nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.ListMember] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM list_members
+			WHERE "list" = $1 AND "member" = ANY($2);`
+	if _, e := conn.Exec(qs, id, pq.Array(members)); e != nil {
+		logger.Error("[Model.ListMember] Failed to execute", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *ListDb) RemoveMemberFromLists(owner, member string) (ids []string, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.ListMember] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM list_members
+			WHERE
+			  "member" = $2
+			  AND "list" IN (SELECT "id" FROM lists WHERE "user" = $1)
+			RETURNING "list";`
+	r, e := conn.Query(qs, owner, member)
+	if e != nil {
+		logger.Error("[Model.ListMember] Failed to execute", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+
+	ids = make([]string, 0)
+	for r.Next() {
+		var id string
+		if e := r.Scan(&id); e != nil {
+			logger.Error("[Model.ListMember] Cannot scan row", e)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
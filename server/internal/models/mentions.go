@@ -0,0 +1,117 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// db
+
+type IPostMention interface {
+	IsPostMentioning(id, user string) bool
+	// replace users mentioned by the post
+	SetMentions(id string, users []string) error
+	// users mentioned by each post, in order of mentioning. posts mentioning nobody are absent
+	QueryMentions(ids []string) (mentions map[string][]string, err error)
+}
+
+// functions
+
+func (db *PostDb) IsPostMentioning(id, user string) bool {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Mentions] Failed to open a connection", err)
+		return false
+	}
+	defer conn.Close()
+
+	qs := `SELECT 1
+		   FROM mentions
+		   WHERE "id" = $1 AND "user" = $2;`
+	var n int
+	if e := conn.QueryOne(qs, id, user).Scan(&n); e != nil {
+		if e != sql.ErrNoRows {
+			logger.Error("[Model.Mentions] Cannot query", e)
+		}
+		return false
+	}
+	return true
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) SetMentions(id string, users []string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Mentions] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	tx, e := conn.BeginTx()
+	if e != nil {
+		logger.Error("[Model.Mentions] Cannot start transaction", e)
+		return ErrDbInternal
+	}
+	defer tx.Rollback()
+	qs := ` DELETE FROM mentions
+			WHERE "id" = $1;`
+	if _, e := tx.Exec(qs, id); e != nil {
+		logger.Error("[Model.Mentions] Failed to execute", e)
+		return ErrDbInternal
+	}
+	qs = `  INSERT INTO mentions("id", "user", "index")
+			SELECT $1, u, i
+			FROM UNNEST($2::text[]) WITH ORDINALITY AS m(u, i)
+			ON CONFLICT DO NOTHING;`
+	if _, e := tx.Exec(qs, id, pq.Array(users)); e != nil {
+		logger.Error("[Model.Mentions] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if e := tx.Commit(); e != nil {
+		logger.Error("[Model.Mentions] Cannot commit", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryMentions(ids []string) (mentions map[string][]string, err error) {
+	logger := db.lg
+	mentions = make(map[string][]string)
+	if len(ids) == 0 {
+		return mentions, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Mentions] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "id", "user"
+			FROM mentions
+			WHERE "id" = ANY($1)
+			ORDER BY "id", "index" ASC;`
+	r, e := conn.Query(qs, pq.Array(ids))
+	if e != nil {
+		logger.Error("[Model.Mentions] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		var id, user string
+		if e := r.Scan(&id, &user); e != nil {
+			logger.Error("[Model.Mentions] Cannot scan row", e)
+			continue
+		}
+		mentions[id] = append(mentions[id], user)
+	}
+	return mentions, nil
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/lib/pq"
+)
+
+// models
+
+type NtfType string
+
+const (
+	Ntf_FOLLOW  NtfType = "follow"
+	Ntf_LIKE    NtfType = "like"
+	Ntf_SHARE   NtfType = "share"
+	Ntf_REPLY   NtfType = "reply"
+	Ntf_MENTION NtfType = "mention"
+	Ntf_POLL    NtfType = "poll"
+	Ntf_QUOTE   NtfType = "quote"
+)
+
+func GetNtfType(literal string) (t NtfType, ok bool) {
+	switch t = NtfType(literal); t {
+	case Ntf_FOLLOW, Ntf_LIKE, Ntf_SHARE, Ntf_REPLY, Ntf_MENTION, Ntf_POLL, Ntf_QUOTE:
+		return t, true
+	}
+	return "", false
+}
+
+type Notification struct {
+	ID    int64     `json:"id"`
+	User  string    `json:"user"` // who is notified
+	Type  NtfType   `json:"type"`
+	Actor string    `json:"actor"`
+	Post  string    `json:"post"` // post id. empty when follow
+	Date  time.Time `json:"date"`
+	Read  bool      `json:"read"`
+}
+
+// db
+
+type INotification interface {
+	// notifications to the actor self or to users not of this site are ignored
+	SetNotification(n *Notification) error
+	// notifications with id < before, descending by id. before <= 0 means from the newest
+	QueryNotifications(user string, types []NtfType, before int64, limit int) (list []*Notification, err error)
+	// mark notifications with id <= upTo as read
+	MarkNotificationsRead(user string, upTo int64) error
+	ClearNotifications(user string) error
+}
+
+type NotificationDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.PqConn]
+}
+
+var notificationIns *NotificationDb = nil
+
+func NotificationInstance(lg logging.Logger) *NotificationDb {
+	if notificationIns == nil {
+		notificationIns = &NotificationDb{
+			lg:   lg,
+			pool: _db.MainPool(nil, nil),
+		}
+	}
+	return notificationIns
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *NotificationDb) SetNotification(n *Notification) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Notification] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	if n.Date.IsZero() {
+		n.Date = time.Now()
+	}
+	qs := ` INSERT INTO notifications(
+			  "user", "type", "actor", "post", "date"
+			)
+			SELECT $1, $2, $3, NULLIF($4, ''), $5
+			WHERE
+			  $1 <> $3
+			  AND EXISTS (SELECT 1 FROM users WHERE "username" = $1)
+			RETURNING "id";`
+	r := conn.QueryOne(qs, n.User, string(n.Type), n.Actor, n.Post, n.Date.UTC())
+	if e := r.Scan(&n.ID); e != nil {
+		switch e {
+		case sql.ErrNoRows:
+			return nil
+		default:
+			logger.Error("[Model.Notification] Failed to execute", e)
+			return ErrDbInternal
+		}
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *NotificationDb) QueryNotifications(user string, types []NtfType, before int64, limit int) (list []*Notification, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Notification] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	ts := make([]string, 0, len(types))
+	for _, v := range types {
+		ts = append(ts, string(v))
+	}
+	qs := ` SELECT
+			  "id", "user", "type", "actor",
+			  "post", "date", "read"
+			FROM notifications
+			WHERE
+			  "user" = $1
+			  AND ($2 <= 0 OR "id" < $2)
+			  AND (CARDINALITY($3::ntf[]) = 0 OR "type" = ANY($3::ntf[]))
+			ORDER BY "id" DESC
+			LIMIT $4;`
+	r, e := conn.Query(qs, user, before, pq.Array(ts), limit)
+	if e != nil {
+		logger.Error("[Model.Notification] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+
+	list = make([]*Notification, 0)
+	for r.Next() {
+		n := Notification{}
+		var t string
+		var post sql.NullString
+		if e := r.Scan(
+			&n.ID, &n.User, &t, &n.Actor,
+			&post, &n.Date, &n.Read,
+		); e != nil {
+			logger.Error("[Model.Notification] Cannot scan row", e)
+			continue
+		}
+		n.Type = NtfType(t)
+		if post.Valid {
+			n.Post = post.String
+		}
+		list = append(list, &n)
+	}
+	return list, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *NotificationDb) MarkNotificationsRead(user string, upTo int64) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Notification] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` UPDATE notifications
+			SET "read" = TRUE
+			WHERE "user" = $1 AND "id" <= $2 AND NOT "read";`
+	if _, e := conn.Exec(qs, user, upTo); e != nil {
+		logger.Error("[Model.Notification] Failed to execute", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *NotificationDb) ClearNotifications(user string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Notification] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM notifications
+			WHERE "user" = $1;`
+	if _, e := conn.Exec(qs, user); e != nil {
+		logger.Error("[Model.Notification] Failed to execute", e)
+		return ErrDbInternal
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// a user in a list of follows, likes or shares, and when it happened
+type UserAct struct {
+	User string
+	Date time.Time
+}
+
+func (a *UserAct) Cursor() utils.Cursor {
+	return utils.Cursor{Date: a.Date, ID: a.User}
+}
+
+// id of a post, or "<postID>|<sharer>" of a share, at the act date
+func (p *Post) Cursor() utils.Cursor {
+	c := utils.Cursor{Date: p.Date, ID: p.ID}
+	if act, e := time.Parse(time.RFC3339Nano, p.ActDate); e == nil {
+		c.Date = act
+	}
+	if p.SharedBy != "" {
+		c.ID += "|" + p.SharedBy
+	}
+	return c
+}
+
+func (t *TagUse) Cursor() utils.Cursor {
+	return utils.Cursor{Date: t.Date, ID: t.Tag}
+}
+
+// scan rows of "user" and "date"
+func scanUserActs(logger logging.Logger, r *sql.Rows) (list []*UserAct) {
+	list = make([]*UserAct, 0)
+	for r.Next() {
+		a := UserAct{}
+		if e := r.Scan(&a.User, &a.Date); e != nil {
+			logger.Error("[Models] Cannot scan row", e)
+			continue
+		}
+		list = append(list, &a)
+	}
+	return list
+}
+
+// conditions, order and limit of a page on the columns of date and id.
+// the parameters are appended to args
+//
+// rows are ascending when paging by Min. use reversePage on the result
+func pageClause(p utils.Page, date, id string, args []interface{}) (clause string, newArgs []interface{}) {
+	cond := "TRUE"
+	if !p.Max.IsZero() {
+		args = append(args, p.Max.Date.UTC(), p.Max.ID)
+		cond += fmt.Sprintf(" AND (%s, %s) < ($%d, $%d)", date, id, len(args)-1, len(args))
+	}
+	after := p.Since
+	order := "DESC"
+	if !p.Min.IsZero() {
+		after = p.Min
+		order = "ASC"
+	}
+	if !after.IsZero() {
+		args = append(args, after.Date.UTC(), after.ID)
+		cond += fmt.Sprintf(" AND (%s, %s) > ($%d, $%d)", date, id, len(args)-1, len(args))
+	}
+	args = append(args, p.Limit)
+	clause = fmt.Sprintf(
+		"%s ORDER BY %s %s, %s %s LIMIT $%d",
+		cond, date, order, id, order, len(args),
+	)
+	return clause, args
+}
+
+// make the result of a page descending
+func reversePage[T any](p utils.Page, list []T) []T {
+	if p.Min.IsZero() {
+		return list
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return list
+}
@@ -0,0 +1,282 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// models
+
+type Poll struct {
+	Post       string         `json:"post"`
+	Options    pq.StringArray `json:"options"`
+	Multiple   bool           `json:"multiple"`   // whether more than one option may be chosen
+	HideTotals bool           `json:"hideTotals"` // counts are hidden until closed
+	Expires    time.Time      `json:"expires"`
+	Closed     bool           `json:"closed"`
+	Counts     []int64        `json:"counts"` // votes of each option, temporary field
+	Voters     int64          `json:"voters"` // count, temporary field
+}
+
+// db
+
+type IPostPoll interface {
+	SetPoll(p *Poll) error
+	// polls of each post, with counts. posts without are absent
+	QueryPolls(ids []string) (polls map[string]*Poll, err error)
+	// choices of the user in each poll. polls not voted are absent
+	QueryVotes(ids []string, user string) (votes map[string][]int64, err error)
+	// one vote per user
+	SetVote(id, user string, choices []int64, date time.Time) error
+	// posts of polls expired before the date but not closed yet
+	QueryExpiredPolls(before time.Time, limit int) (ids []string, err error)
+	// close the poll, returning the voters
+	ClosePoll(id string) (voters []string, err error)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+//   - Dunplicate
+func (db *PostDb) SetPoll(p *Poll) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Polls] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO polls(
+			  "post", "options", "multiple", "hide_totals", "expires"
+			)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT DO NOTHING;`
+	r, e := conn.Exec(qs, p.Post, p.Options, p.Multiple, p.HideTotals, p.Expires.UTC())
+	if e != nil {
+		logger.Error("[Model.Polls] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrDunplicate
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryPolls(ids []string) (polls map[string]*Poll, err error) {
+	logger := db.lg
+	polls = make(map[string]*Poll)
+	if len(ids) == 0 {
+		return polls, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Polls] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  "post", "options", "multiple", "hide_totals", "expires", "closed",
+			  (SELECT COUNT(*) FROM poll_votes v WHERE v."post" = polls."post")
+			FROM polls
+			WHERE "post" = ANY($1);`
+	r, e := conn.Query(qs, pq.Array(ids))
+	if e != nil {
+		logger.Error("[Model.Polls] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		p := Poll{}
+		if e := r.Scan(
+			&p.Post, &p.Options, &p.Multiple, &p.HideTotals, &p.Expires, &p.Closed,
+			&p.Voters,
+		); e != nil {
+			logger.Error("[Model.Polls] Cannot scan row", e)
+			continue
+		}
+		p.Counts = make([]int64, len(p.Options))
+		polls[p.Post] = &p
+	}
+
+	qs = `  SELECT "post", c, COUNT(*)
+			FROM poll_votes, UNNEST("choices") AS c
+			WHERE "post" = ANY($1)
+			GROUP BY "post", c;`
+	cr, e := conn.Query(qs, pq.Array(ids))
+	if e != nil {
+		logger.Error("[Model.Polls] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer cr.Close()
+	for cr.Next() {
+		var id string
+		var choice, count int64
+		if e := cr.Scan(&id, &choice, &count); e != nil {
+			logger.Error("[Model.Polls] Cannot scan row", e)
+			continue
+		}
+		p := polls[id]
+		if p == nil || choice < 0 || choice >= int64(len(p.Counts)) {
+			continue
+		}
+		p.Counts[choice] = count
+	}
+	return polls, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryVotes(ids []string, user string) (votes map[string][]int64, err error) {
+	logger := db.lg
+	votes = make(map[string][]int64)
+	if len(ids) == 0 || user == "" {
+		return votes, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Polls] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "post", "choices"
+			FROM poll_votes
+			WHERE "post" = ANY($1) AND "user" = $2;`
+	r, e := conn.Query(qs, pq.Array(ids), user)
+	if e != nil {
+		logger.Error("[Model.Polls] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		var id string
+		var choices pq.Int64Array
+		if e := r.Scan(&id, &choices); e != nil {
+			logger.Error("[Model.Polls] Cannot scan row", e)
+			continue
+		}
+		votes[id] = choices
+	}
+	return votes, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - Dunplicate "voted"
+func (db *PostDb) SetVote(id, user string, choices []int64, date time.Time) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Polls] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO poll_votes("post", "user", "choices", "date")
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING;`
+	r, e := conn.Exec(qs, id, user, pq.Int64Array(choices), date.UTC())
+	if e != nil {
+		logger.Error("[Model.Polls] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrDunplicate
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryExpiredPolls(before time.Time, limit int) (ids []string, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Polls] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "post"
+			FROM polls
+			WHERE NOT "closed" AND "expires" <= $1
+			ORDER BY "expires" ASC
+			LIMIT $2;`
+	r, e := conn.Query(qs, before.UTC(), limit)
+	if e != nil {
+		logger.Error("[Model.Polls] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	ids = make([]string, 0)
+	for r.Next() {
+		var id string
+		if e := r.Scan(&id); e != nil {
+			logger.Error("[Model.Polls] Cannot scan row", e)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "poll not found or closed already"
+func (db *PostDb) ClosePoll(id string) (voters []string, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Polls] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` UPDATE polls
+			SET "closed" = TRUE
+			WHERE "post" = $1 AND NOT "closed"
+			RETURNING "post";`
+	var post string
+	if e := conn.QueryOne(qs, id).Scan(&post); e != nil {
+		switch e {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			logger.Error("[Model.Polls] Failed to execute", e)
+			return nil, ErrDbInternal
+		}
+	}
+
+	qs = `  SELECT "user"
+			FROM poll_votes
+			WHERE "post" = $1;`
+	r, e := conn.Query(qs, id)
+	if e != nil {
+		logger.Error("[Model.Polls] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	voters = make([]string, 0)
+	for r.Next() {
+		var u string
+		if e := r.Scan(&u); e != nil {
+			logger.Error("[Model.Polls] Cannot scan row", e)
+			continue
+		}
+		voters = append(voters, u)
+	}
+	return voters, nil
+}
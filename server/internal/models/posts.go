@@ -45,49 +45,98 @@ func (img *Img) Scan(src interface{}) error {
 }
 
 type Post struct {
-	ID       string           `json:"id"`
-	Url      string           `json:"url"`
-	User     string           `json:"user"`
-	Date     time.Time        `json:"date"`
-	Vsb      utils.Vsb        `json:"vsb"`
-	Content  string           `json:"content"`
-	Media    Array[Img, *Img] `json:"media"`    // magic but sucks
-	Replying string           `json:"replying"` // post id
-	ReplyTo  string           `json:"replyTo"`  // user id, temporary field
-	SharedBy string           `json:"sharedBy"` // user id, temporary field
-	Likes    int64            `json:"likes"`    // count, temporary field
-	Shares   int64            `json:"shares"`   // count, temporary field
-	ActDate  string           `json:"actDate"`  // temporary field, used in sort
-	Level    int              `json:"level"`    // temporary field, used in replying and replies
+	ID           string           `json:"id"`
+	Url          string           `json:"url"`
+	User         string           `json:"user"`
+	Date         time.Time        `json:"date"`
+	Vsb          utils.Vsb        `json:"vsb"`
+	Content      string           `json:"content"`      // html
+	Source       string           `json:"source"`       // plain text the content rendered from. empty if not of this site
+	Media        Array[Img, *Img] `json:"media"`        // magic but sucks
+	Replying     string           `json:"replying"`     // post id
+	Recipients   pq.StringArray   `json:"recipients"`   // users addressed by a direct post, besides the author
+	Conversation int64            `json:"conversation"` // of a direct post
+	Edited       time.Time        `json:"edited"`       // zero if never edited
+	Spoiler      string           `json:"spoiler"`      // content warning
+	Sensitive    bool             `json:"sensitive"`    // media are sensitive
+	Quoting      string           `json:"quoting"`      // post id
+	Quotable     bool             `json:"quotable"`     // others can quote it
+	ReplyPolicy  ReplyPolicy      `json:"replyPolicy"`  // who can reply to it
+	ReplyTo      string           `json:"replyTo"`      // user id, temporary field
+	SharedBy     string           `json:"sharedBy"`     // user id, temporary field
+	Likes        int64            `json:"likes"`        // count, temporary field
+	Shares       int64            `json:"shares"`       // count, temporary field
+	ActDate      string           `json:"actDate"`      // temporary field, used in sort
+	Level        int              `json:"level"`        // temporary field, distance in a thread
+	Guarded      pq.StringArray   `json:"guarded"`      // temporary field, non-public posts down to a descendant
+}
+
+// who can reply to a post besides its author
+type ReplyPolicy string
+
+const (
+	Rpl_EVERYONE  ReplyPolicy = "everyone"
+	Rpl_FOLLOWERS ReplyPolicy = "followers" // followers of the author and users mentioned
+	Rpl_MENTIONED ReplyPolicy = "mentioned" // users mentioned, and recipients of a direct post
+	Rpl_NOBODY    ReplyPolicy = "nobody"
+)
+
+func GetReplyPolicy(literal string) (p ReplyPolicy, ok bool) {
+	switch p = ReplyPolicy(literal); p {
+	case Rpl_EVERYONE, Rpl_FOLLOWERS, Rpl_MENTIONED, Rpl_NOBODY:
+		return p, true
+	}
+	return "", false
 }
 
 // db
 
 type IPostQuery interface {
 	IsPostExist(id string) bool
+	IsPostRecipient(id, user string) bool
 	QueryPostByID(id string) (post Post, err error)
-	QueryPostReplies(id string) (replyings []*Post, replies []*Post, err error)
-	QueryPostsAndSharesByUser(user string, asec bool) (list []*Post, err error)
+	QueryPostAncestors(id string, limit int) (list []*Post, err error)
+	QueryPostDescendants(id string, maxDepth int, page utils.Page) (list []*Post, err error)
+	QueryPostsAndSharesByUser(user string, page utils.Page) (list []*Post, err error)
+	QueryPostsByIDs(ids []string) (list []*Post, err error)
 }
 
 type IPostSet interface {
 	SetPost(p *Post, attachments []Img) error
+	// uses: Post.ID, Post.Content, Post.Source, Post.Edited, Post.Spoiler, Post.Sensitive, Post.Quotable,
+	// Post.ReplyPolicy.
+	// the current version is kept as a revision
 	UpdatePost(p *Post, attachments []Img) error
 	RemovePost(id string) error
 }
 
 type IPostLike interface {
-	QueryLikes(id string) (list []string, owner string, vsb utils.Vsb, err error)
-	SetLike(user, id string) error
+	// users liking the post, descending by date
+	QueryLikes(id string, page utils.Page) (list []*UserAct, owner string, vsb utils.Vsb, err error)
+	SetLike(user, id string, date time.Time) error
 	RemoveLike(user, id string) error
 }
 
 type IPostShare interface {
-	QueryShares(id string) (list []string, owner string, vsb utils.Vsb, err error)
+	// users sharing the post, descending by date
+	QueryShares(id string, page utils.Page) (list []*UserAct, owner string, vsb utils.Vsb, err error)
 	SetShare(user, id string, date time.Time, vsb utils.Vsb) error
 	RemoveShare(user, id string) error
 }
 
+type IPostTimeline interface {
+	// posts and shares in user's home timeline before (date, id), descending by act date
+	// in milliseconds then by id, as cached timelines are. see TimelineItem.ID.
+	// public posts with hashtags the user follows are included
+	QueryHomeTimeline(user string, before time.Time, beforeID string, limit int) (list []*Post, err error)
+	// posts and shares of members of the list before (date, id), ordered as home timelines.
+	// replies are filtered by the replies policy of the list
+	QueryListTimeline(id string, before time.Time, beforeID string, limit int) (list []*Post, err error)
+	// public posts not replying before (date, id), descending.
+	// excludes silenced domains and users muted by viewer
+	QueryPublicTimeline(viewer string, local bool, before time.Time, beforeID string, limit int) (list []*Post, err error)
+}
+
 type PostDb struct {
 	lg   logging.Logger
 	pool *_db.ConnPool[*_db.PqConn]
@@ -133,6 +182,32 @@ func (db *PostDb) IsPostExist(id string) bool {
 	return true
 }
 
+func (db *PostDb) IsPostRecipient(id, user string) bool {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Posts] Failed to open a connection", err)
+		return false
+	}
+	defer conn.Close()
+
+	qs := `SELECT 1
+		   FROM posts
+		   WHERE "id" = $1 AND $2 = ANY("recipients");`
+	r := conn.QueryOne(qs, id, user)
+	var n int
+	if e := r.Scan(&n); e != nil {
+		switch e {
+		case sql.ErrNoRows:
+			return false
+		default:
+			logger.Error("[Model.Posts] Cannot query", e)
+			return false
+		}
+	}
+	return true
+}
+
 // ERRORS
 //
 //   - DbInternal
@@ -150,17 +225,28 @@ func (db *PostDb) QueryPostByID(id string) (post Post, err error) {
 			  "id", "url", "user", "date",
 			  "vsb", "content", "media",
 			  CARDINALITY("likes") as "likes",
-			  CARDINALITY("shares") as "shares"
+			  CARDINALITY("shares") as "shares",
+			  "replying", "recipients", "conversation",
+			  "source", "edited", "spoiler", "sensitive",
+			  "quoting", "quotable", "reply_policy"
 			FROM posts
 			WHERE "id" = $1;`
 	r := conn.QueryOne(qs, id)
 
 	post = Post{}
 	var vsb string
+	var rpy sql.NullString
+	var cvs sql.NullInt64
+	var src sql.NullString
+	var edt sql.NullTime
+	var qtg sql.NullString
 	if e := r.Scan(
 		&post.ID, &post.Url, &post.User, &post.Date,
 		&vsb, &post.Content, post.Media.ToPqArray(),
 		&post.Likes, &post.Shares,
+		&rpy, &post.Recipients, &cvs,
+		&src, &edt, &post.Spoiler, &post.Sensitive,
+		&qtg, &post.Quotable, &post.ReplyPolicy,
 	); e != nil {
 		switch e {
 		case sql.ErrNoRows:
@@ -170,24 +256,42 @@ func (db *PostDb) QueryPostByID(id string) (post Post, err error) {
 			return post, ErrDbInternal
 		}
 	}
+	if rpy.Valid {
+		post.Replying = rpy.String
+	}
+	if cvs.Valid {
+		post.Conversation = cvs.Int64
+	}
+	if src.Valid {
+		post.Source = src.String
+	}
+	if edt.Valid {
+		post.Edited = edt.Time
+	}
+	if qtg.Valid {
+		post.Quoting = qtg.String
+	}
 	post.Vsb, _ = utils.GetVsb(vsb)
 	return post, nil
 }
 
+// ancestors of a post nearest to it, no more than the limit, from the farthest.
+// Post.Replying and Post.Level are set
+//
 // ERRORS
 //
 //   - DbInternal
 //   - NotFound "post"
-func (db *PostDb) QueryPostReplies(id string) (replyings []*Post, replies []*Post, err error) {
+func (db *PostDb) QueryPostAncestors(id string, limit int) (list []*Post, err error) {
 	logger := db.lg
 	conn, err := db.pool.Open()
 	if err != nil {
 		logger.Error("[Model.Reply] Failed to open a connection", err)
-		return nil, nil, ErrDbInternal
+		return nil, ErrDbInternal
 	}
 	defer conn.Close()
 	if !db.IsPostExist(id) {
-		return nil, nil, ErrNotFound
+		return nil, ErrNotFound
 	}
 
 	qs := ` WITH RECURSIVE rt AS (
@@ -197,95 +301,132 @@ func (db *PostDb) QueryPostReplies(id string) (replyings []*Post, replies []*Pos
 			    SELECT posts."id", posts."replying", rt."level" + 1
 			    FROM posts
 			      JOIN rt ON posts."id" = rt."replying"
+			    WHERE rt."level" < $2
 			)
 			SELECT
-  			  posts."id", posts."url", posts."user", posts."date",
-  			  posts."vsb", posts."content", posts."media",
+			  posts."id", posts."url", posts."user", posts."date",
+			  posts."vsb", posts."content", posts."media",
 			  CARDINALITY(posts."likes") as "likes",
 			  CARDINALITY(posts."shares") as "shares",
-			  posts."replying", rt."level"
+			  p2."user" AS "replyTo", posts."replying", posts."recipients", posts."edited",
+			  posts."spoiler", posts."sensitive",
+			  posts."quoting", posts."quotable", posts."reply_policy",
+			  rt."level", ARRAY[]::text[] AS "guarded"
 			FROM posts
 			  JOIN rt ON posts."id" = rt."id"
-			ORDER BY "level" ASC, "date" DESC;
-	`
-	r, e := conn.Query(qs, id)
+			  LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			WHERE rt."level" > 0
+			ORDER BY rt."level" DESC;`
+	r, e := conn.Query(qs, id, limit)
 	if e != nil {
 		logger.Error("[Model.Reply] Cannot query", e)
-		return nil, nil, ErrDbInternal
+		return nil, ErrDbInternal
 	}
+	defer r.Close()
+	return scanThread(logger, r), nil
+}
 
-	replyings = make([]*Post, 0)
-	for r.Next() {
-		p := Post{}
-		var rpy sql.NullString
-		var vsb string
-		if e := r.Scan(
-			&p.ID, &p.Url, &p.User, &p.Date,
-			&vsb, &p.Content, p.Media.ToPqArray(),
-			&p.Likes, &p.Shares,
-			&rpy, &p.Level,
-		); e != nil {
-			logger.Error("[Model.Reply] Cannot scan row", e)
-			continue
-		}
-		if rpy.Valid {
-			p.Replying = rpy.String
-		}
-		p.Vsb, _ = utils.GetVsb(vsb)
-		replyings = append(replyings, &p)
+// descendants of a post no deeper than the depth, descending by date.
+// Post.Replying, Post.Level and Post.Guarded are set
+//
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "post"
+func (db *PostDb) QueryPostDescendants(id string, maxDepth int, page utils.Page) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Reply] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+	if !db.IsPostExist(id) {
+		return nil, ErrNotFound
 	}
 
-	qs = `  WITH RECURSIVE rs AS (
-			    SELECT "id", "replying", 0 AS "level" FROM posts
+	// "guarded" collects non-public posts on the way down, since replies to
+	// posts not permitted are not permitted either
+	qs := ` WITH RECURSIVE rs AS (
+			    SELECT "id", 0 AS "level", ARRAY[]::text[] AS "guarded" FROM posts
 			    WHERE "id" = $1
 			  UNION ALL
-			    SELECT posts."id", posts."replying", rs."level" + 1
+			    SELECT posts."id", rs."level" + 1,
+			      CASE WHEN posts."vsb" = 'public' THEN rs."guarded"
+			      ELSE rs."guarded" || posts."id" END
 			    FROM posts
 			      JOIN rs ON rs."id" = posts."replying"
+			    WHERE rs."level" < $2
 			)
 			SELECT
-  			  posts."id", posts."url", posts."user", posts."date",
-  			  posts."vsb", posts."content", posts."media",
+			  posts."id", posts."url", posts."user", posts."date",
+			  posts."vsb", posts."content", posts."media",
 			  CARDINALITY(posts."likes") as "likes",
 			  CARDINALITY(posts."shares") as "shares",
-			  posts."replying", rs."level"
+			  p2."user" AS "replyTo", posts."replying", posts."recipients", posts."edited",
+			  posts."spoiler", posts."sensitive",
+			  posts."quoting", posts."quotable", posts."reply_policy",
+			  rs."level", rs."guarded"
 			FROM posts
 			  JOIN rs ON posts."id" = rs."id"
-			ORDER BY "level" ASC, "date" DESC;
-	`
-	r, e = conn.Query(qs, id)
+			  LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			WHERE rs."level" > 0 AND %s;`
+	clause, args := pageClause(page, `posts."date"`, `posts."id"`, []interface{}{id, maxDepth})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
 	if e != nil {
 		logger.Error("[Model.Reply] Cannot query", e)
-		return nil, nil, ErrDbInternal
+		return nil, ErrDbInternal
 	}
-	replies = make([]*Post, 0)
+	defer r.Close()
+	return reversePage(page, scanThread(logger, r)), nil
+}
+
+// scan rows of posts with "replyTo", "replying", "recipients", "edited", "spoiler", "sensitive",
+// "quoting", "quotable", "reply_policy", "level" and "guarded"
+func scanThread(logger logging.Logger, r *sql.Rows) (list []*Post) {
+	list = make([]*Post, 0)
 	for r.Next() {
 		p := Post{}
+		var rpt sql.NullString
 		var rpy sql.NullString
+		var edt sql.NullTime
+		var qtg sql.NullString
 		var vsb string
 		if e := r.Scan(
 			&p.ID, &p.Url, &p.User, &p.Date,
 			&vsb, &p.Content, p.Media.ToPqArray(),
 			&p.Likes, &p.Shares,
-			&rpy, &p.Level,
+			&rpt, &rpy, &p.Recipients, &edt,
+			&p.Spoiler, &p.Sensitive, &qtg, &p.Quotable, &p.ReplyPolicy,
+			&p.Level, &p.Guarded,
 		); e != nil {
 			logger.Error("[Model.Reply] Cannot scan row", e)
 			continue
 		}
+		if rpt.Valid {
+			p.ReplyTo = rpt.String
+		}
 		if rpy.Valid {
 			p.Replying = rpy.String
 		}
+		if edt.Valid {
+			p.Edited = edt.Time
+		}
+		if qtg.Valid {
+			p.Quoting = qtg.String
+		}
 		p.Vsb, _ = utils.GetVsb(vsb)
-		replies = append(replies, &p)
+		list = append(list, &p)
 	}
-
-	return replyings, replies, nil
+	return list
 }
 
+// posts and shares of the user, descending by act date
+//
 // ERRORS
 //
 //   - DbInternal
-func (db *PostDb) QueryPostsAndSharesByUser(user string, asc bool) (list []*Post, err error) {
+func (db *PostDb) QueryPostsAndSharesByUser(user string, page utils.Page) (list []*Post, err error) {
 	logger := db.lg
 	conn, err := db.pool.Open()
 	if err != nil {
@@ -294,62 +435,74 @@ func (db *PostDb) QueryPostsAndSharesByUser(user string, asc bool) (list []*Post
 	}
 	defer conn.Close()
 
-	qs := `   WITH rr AS (
-			    SELECT p1."id", p2."user" as "user"
-			    FROM posts AS p1, posts AS p2
-			    WHERE p1."user" = $1 AND p2."id" = p1."replying"
-			  )
-			  SELECT
-    		    posts."id", posts."url", posts."user", posts."date",
-    		    posts."vsb", posts."content", posts."media",
-			    CARDINALITY("likes") as "likes",
-			    CARDINALITY("shares") as "shares",
-			    rr."user" AS "replyTo", NULL AS "sharedBy",
-			    posts."date" AS "act"
-			  FROM posts, rr
-			  WHERE posts."user" = $1 AND posts."id" = rr."id"
-			UNION ALL
-			  SELECT
-    		    "id", "url", "user", "date",
-    		    "vsb", "content", "media",
-			    CARDINALITY("likes") as "likes",
-			    CARDINALITY("shares") as "shares",
-			    NULL AS "replyTo", NULL AS "sharedBy",
-			    "date" AS "act"
-			  FROM posts
-			  WHERE "user" = $1 AND "replying" IS NULL
-			UNION ALL
-			  SELECT
-  			    posts."id", posts."url", posts."user", posts."date",
-  			    shares."vsb", posts."content", posts."media",
-			    CARDINALITY("likes") as "likes",
-			    CARDINALITY("shares") as "shares",
-			    NULL AS "replyTo", shares."user" as "sharedBy",
-			    shares."date" AS "act"
-			  FROM posts, shares
-			  WHERE shares."user" = $1 AND posts."id" = shares."id"
-			ORDER BY "act" %s;`
-	if asc {
-		qs = fmt.Sprintf(qs, "ASC")
-	} else {
-		qs = fmt.Sprintf(qs, "DESC")
-	}
-	r, e := conn.Query(qs, user)
+	// "key" is the id of a post, or "<postID>|<sharer>" of a share
+	qs := ` SELECT
+			  "id", "url", "user", "date",
+			  "vsb", "content", "media",
+			  "likes", "shares",
+			  "replyTo", "sharedBy", "act",
+			  "recipients", "edited", "spoiler", "sensitive",
+			  "quoting", "quotable", "reply_policy"
+			FROM (
+			    SELECT
+			      posts."id", posts."url", posts."user", posts."date",
+			      posts."vsb", posts."content", posts."media",
+			      CARDINALITY(posts."likes") as "likes",
+			      CARDINALITY(posts."shares") as "shares",
+			      p2."user" AS "replyTo", NULL AS "sharedBy",
+			      posts."date" AS "act", posts."recipients", posts."edited",
+			      posts."spoiler", posts."sensitive",
+			      posts."quoting", posts."quotable", posts."reply_policy",
+			      posts."id" AS "key"
+			    FROM posts
+			      LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			    WHERE posts."user" = $1 AND posts."vsb" <> 'direct'
+			  UNION ALL
+			    SELECT
+			      posts."id", posts."url", posts."user", posts."date",
+			      shares."vsb", posts."content", posts."media",
+			      CARDINALITY(posts."likes") as "likes",
+			      CARDINALITY(posts."shares") as "shares",
+			      NULL AS "replyTo", shares."user" as "sharedBy",
+			      shares."date" AS "act", posts."recipients", posts."edited",
+			      posts."spoiler", posts."sensitive",
+			      posts."quoting", posts."quotable", posts."reply_policy",
+			      posts."id" || '|' || shares."user" AS "key"
+			    FROM shares
+			      JOIN posts ON posts."id" = shares."id"
+			    WHERE
+			      shares."user" = $1
+			      AND shares."vsb" <> 'direct' AND posts."vsb" <> 'direct'
+			) AS l
+			WHERE %s;`
+	clause, args := pageClause(page, `"act"`, `"key"`, []interface{}{user})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
 	if e != nil {
-		logger.Error("[Model.Reply] Cannot query", e)
+		logger.Error("[Model.Posts] Cannot query", e)
 		return nil, ErrDbInternal
 	}
+	defer r.Close()
+	return reversePage(page, scanPostsWithAct(logger, r)), nil
+}
+
+// scan rows of posts with "replyTo", "sharedBy", "act", "recipients", "edited", "spoiler", "sensitive",
+// "quoting", "quotable" and "reply_policy"
+func scanPostsWithAct(logger logging.Logger, r *sql.Rows) (list []*Post) {
 	list = make([]*Post, 0)
 	for r.Next() {
 		p := Post{}
 		var rpt sql.NullString
 		var shb sql.NullString
+		var edt sql.NullTime
+		var qtg sql.NullString
 		var vsb string
 		if e := r.Scan(
 			&p.ID, &p.Url, &p.User, &p.Date,
 			&vsb, &p.Content, p.Media.ToPqArray(),
 			&p.Likes, &p.Shares,
 			&rpt, &shb, &p.ActDate,
+			&p.Recipients, &edt,
+			&p.Spoiler, &p.Sensitive, &qtg, &p.Quotable, &p.ReplyPolicy,
 		); e != nil {
 			logger.Error("[Model.Posts] Cannot scan row", e)
 			continue
@@ -360,10 +513,282 @@ func (db *PostDb) QueryPostsAndSharesByUser(user string, asc bool) (list []*Post
 		if shb.Valid {
 			p.SharedBy = shb.String
 		}
+		if edt.Valid {
+			p.Edited = edt.Time
+		}
+		if qtg.Valid {
+			p.Quoting = qtg.String
+		}
 		p.Vsb, _ = utils.GetVsb(vsb)
 		list = append(list, &p)
 	}
-	return list, nil
+	return list
+}
+
+// posts not found are skipped. the order of result is not guaranteed
+//
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryPostsByIDs(ids []string) (list []*Post, err error) {
+	logger := db.lg
+	if len(ids) == 0 {
+		return make([]*Post, 0), nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Posts] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  posts."id", posts."url", posts."user", posts."date",
+			  posts."vsb", posts."content", posts."media",
+			  CARDINALITY(posts."likes") as "likes",
+			  CARDINALITY(posts."shares") as "shares",
+			  p2."user" AS "replyTo", NULL AS "sharedBy",
+			  posts."date" AS "act", posts."recipients", posts."edited",
+			  posts."spoiler", posts."sensitive",
+			  posts."quoting", posts."quotable", posts."reply_policy"
+			FROM posts
+			  LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			WHERE posts."id" = ANY($1);`
+	r, e := conn.Query(qs, pq.Array(ids))
+	if e != nil {
+		logger.Error("[Model.Posts] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanPostsWithAct(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryHomeTimeline(user string, before time.Time, beforeID string, limit int) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	if before.IsZero() {
+		before = time.Now()
+		beforeID = ""
+	}
+	qs := ` WITH fo AS (
+			  SELECT "to" AS "user"
+			  FROM follow
+			  WHERE "from" = $1
+			)
+			SELECT
+			  "id", "url", "user", "date",
+			  "vsb", "content", "media",
+			  "likes", "shares",
+			  "replyTo", "sharedBy", "act",
+			  "recipients", "edited", "spoiler", "sensitive",
+			  "quoting", "quotable", "reply_policy"
+			FROM (
+			    SELECT
+			      posts."id", posts."url", posts."user", posts."date",
+			      posts."vsb", posts."content", posts."media",
+			      CARDINALITY(posts."likes") as "likes",
+			      CARDINALITY(posts."shares") as "shares",
+			      p2."user" AS "replyTo", NULL AS "sharedBy",
+			      posts."date" AS "act", posts."recipients", posts."edited",
+			      posts."spoiler", posts."sensitive",
+			      posts."quoting", posts."quotable", posts."reply_policy",
+			      posts."id" COLLATE "C" AS "key"
+			    FROM posts
+			      LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			    WHERE
+			      posts."date" < $2::timestamp + INTERVAL '1 millisecond'
+			      AND (
+			        posts."user" = $1
+			        OR (posts."vsb" <> 'direct' AND posts."user" IN (SELECT "user" FROM fo))
+			        OR (posts."vsb" = 'direct' AND $1 = ANY(posts."recipients"))
+			        OR (posts."vsb" <> 'direct' AND posts."id" IN (
+			          SELECT "id" FROM mentions WHERE "user" = $1
+			        ))
+			        OR (posts."vsb" = 'public' AND posts."id" IN (
+			          SELECT t."id"
+			          FROM post_tags AS t
+			            JOIN tag_follows AS f ON f."tag" = t."tag"
+			          WHERE f."user" = $1
+			        ))
+			      )
+			  UNION ALL
+			    SELECT
+			      posts."id", posts."url", posts."user", posts."date",
+			      posts."vsb", posts."content", posts."media",
+			      CARDINALITY(posts."likes") as "likes",
+			      CARDINALITY(posts."shares") as "shares",
+			      NULL AS "replyTo", shares."user" as "sharedBy",
+			      shares."date" AS "act", posts."recipients", posts."edited",
+			      posts."spoiler", posts."sensitive",
+			      posts."quoting", posts."quotable", posts."reply_policy",
+			      (posts."id" || '|' || shares."user") COLLATE "C" AS "key"
+			    FROM shares
+			      JOIN posts ON posts."id" = shares."id"
+			    WHERE
+			      shares."date" < $2::timestamp + INTERVAL '1 millisecond'
+			      AND shares."vsb" <> 'direct' AND posts."vsb" <> 'direct'
+			      AND shares."user" IN (SELECT "user" FROM fo)
+			) AS items
+			WHERE (DATE_TRUNC('milliseconds', "act"), "key") < ($2, $3)
+			ORDER BY DATE_TRUNC('milliseconds', "act") DESC, "key" DESC
+			LIMIT $4;`
+	r, e := conn.Query(qs, user, before.UTC(), beforeID, limit)
+	if e != nil {
+		logger.Error("[Model.Timeline] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanPostsWithAct(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryListTimeline(id string, before time.Time, beforeID string, limit int) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	if before.IsZero() {
+		before = time.Now()
+		beforeID = ""
+	}
+	qs := ` WITH l AS (
+			  SELECT "id", "user", "replies"
+			  FROM lists
+			  WHERE "id" = $1
+			), m AS (
+			  SELECT "member" AS "user"
+			  FROM list_members
+			  WHERE "list" = $1
+			)
+			SELECT
+			  "id", "url", "user", "date",
+			  "vsb", "content", "media",
+			  "likes", "shares",
+			  "replyTo", "sharedBy", "act",
+			  "recipients", "edited", "spoiler", "sensitive",
+			  "quoting", "quotable", "reply_policy"
+			FROM (
+			    SELECT
+			      posts."id", posts."url", posts."user", posts."date",
+			      posts."vsb", posts."content", posts."media",
+			      CARDINALITY(posts."likes") as "likes",
+			      CARDINALITY(posts."shares") as "shares",
+			      p2."user" AS "replyTo", NULL AS "sharedBy",
+			      posts."date" AS "act", posts."recipients", posts."edited",
+			      posts."spoiler", posts."sensitive",
+			      posts."quoting", posts."quotable", posts."reply_policy",
+			      posts."id" COLLATE "C" AS "key"
+			    FROM posts
+			      CROSS JOIN l
+			      LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			    WHERE
+			      posts."date" < $2::timestamp + INTERVAL '1 millisecond'
+			      AND posts."vsb" <> 'direct'
+			      AND posts."user" IN (SELECT "user" FROM m)
+			      AND (
+			        posts."replying" IS NULL
+			        OR (l."replies" = 'followed' AND (
+			          p2."user" = l."user"
+			          OR EXISTS (
+			            SELECT 1 FROM follow WHERE "from" = l."user" AND "to" = p2."user"
+			          )
+			        ))
+			        OR (l."replies" = 'list' AND p2."user" IN (SELECT "user" FROM m))
+			      )
+			  UNION ALL
+			    SELECT
+			      posts."id", posts."url", posts."user", posts."date",
+			      posts."vsb", posts."content", posts."media",
+			      CARDINALITY(posts."likes") as "likes",
+			      CARDINALITY(posts."shares") as "shares",
+			      NULL AS "replyTo", shares."user" as "sharedBy",
+			      shares."date" AS "act", posts."recipients", posts."edited",
+			      posts."spoiler", posts."sensitive",
+			      posts."quoting", posts."quotable", posts."reply_policy",
+			      (posts."id" || '|' || shares."user") COLLATE "C" AS "key"
+			    FROM shares
+			      JOIN posts ON posts."id" = shares."id"
+			    WHERE
+			      shares."date" < $2::timestamp + INTERVAL '1 millisecond'
+			      AND shares."vsb" <> 'direct' AND posts."vsb" <> 'direct'
+			      AND shares."user" IN (SELECT "user" FROM m)
+			) AS items
+			WHERE (DATE_TRUNC('milliseconds', "act"), "key") < ($2, $3)
+			ORDER BY DATE_TRUNC('milliseconds', "act") DESC, "key" DESC
+			LIMIT $4;`
+	r, e := conn.Query(qs, id, before.UTC(), beforeID, limit)
+	if e != nil {
+		logger.Error("[Model.Timeline] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanPostsWithAct(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryPublicTimeline(viewer string, local bool, before time.Time, beforeID string, limit int) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	if before.IsZero() {
+		before = time.Now()
+		beforeID = ""
+	}
+	// local users have no domain part. the predicate of posts_public_local
+	// is written out only when local, so that the partial index is chosen
+	localClause := ""
+	if local {
+		localClause = `AND STRPOS("user", '@') = 0`
+	}
+	qs := ` SELECT
+			  "id", "url", "user", "date",
+			  "vsb", "content", "media",
+			  CARDINALITY("likes") as "likes",
+			  CARDINALITY("shares") as "shares",
+			  NULL AS "replyTo", NULL AS "sharedBy",
+			  "date" AS "act", "recipients", "edited", "spoiler", "sensitive",
+			  "quoting", "quotable", "reply_policy"
+			FROM posts
+			WHERE
+			  "vsb" = 'public' AND "replying" IS NULL %s
+			  AND ("date", "id") < ($2, $3)
+			  AND SPLIT_PART("user", '@', 2) NOT IN (
+			    SELECT "domain" FROM silenced_domains
+			  )
+			  AND "user" NOT IN (
+			    SELECT "target" FROM mutes WHERE "user" = $1
+			  )
+			ORDER BY "date" DESC, "id" DESC
+			LIMIT $4;`
+	r, e := conn.Query(fmt.Sprintf(qs, localClause), viewer, before.UTC(), beforeID, limit)
+	if e != nil {
+		logger.Error("[Model.Timeline] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanPostsWithAct(logger, r), nil
 }
 
 // ERRORS
@@ -385,18 +810,29 @@ func (db *PostDb) SetPost(p *Post, attachments []Img) error {
 	qs := ` INSERT INTO posts(
   			  "id", "url", "user", "date",
   			  "replying", "vsb", "content",
-			  "media"
+			  "media", "recipients", "conversation",
+			  "source", "spoiler", "sensitive",
+			  "quoting", "quotable", "reply_policy"
 			)
 			VALUES (
 			  $1, $2, $3, $4,
-			  $5, $6, $7,
-			  $8
-			);`
+			  NULLIF($5, ''), $6, $7,
+			  $8, $9, NULLIF($10, 0),
+			  NULLIF($11, ''), $12, $13,
+			  NULLIF($14, ''), $15, $16
+			)
+			ON CONFLICT ("id") DO NOTHING;`
 	p.Date = p.Date.UTC()
+	recipients := p.Recipients
+	if recipients == nil {
+		recipients = pq.StringArray{}
+	}
 	r, e := conn.Exec(qs,
 		p.ID, p.Url, p.User, p.Date,
 		p.Replying, p.Vsb.String(), p.Content,
-		NewArray(attachments, logger),
+		NewArray(attachments, logger), recipients, p.Conversation,
+		p.Source, p.Spoiler, p.Sensitive,
+		p.Quoting, p.Quotable, string(p.ReplyPolicy),
 	)
 	if e != nil {
 		logger.Error("[Model.Posts] Failed to execute", e)
@@ -421,20 +857,51 @@ func (db *PostDb) UpdatePost(p *Post, attachments []Img) error {
 	}
 	defer conn.Close()
 
-	qs := ` UPDATE posts
-			SET
-			  "date" = $2, "content" = $3,
-			  "media" = $4
+	tx, e := conn.BeginTx()
+	if e != nil {
+		logger.Error("[Model.Posts] Cannot start transaction", e)
+		return ErrDbInternal
+	}
+	defer tx.Rollback()
+
+	// keep the current version
+	qs := ` INSERT INTO post_revisions(
+			  "post", "content", "source", "media", "date",
+			  "spoiler", "sensitive"
+			)
+			SELECT
+			  "id", "content", "source", "media", COALESCE("edited", "date"),
+			  "spoiler", "sensitive"
+			FROM posts
 			WHERE "id" = $1;`
-	p.Date = p.Date.UTC()
-	r, e := conn.Exec(qs, p.ID, p.Date, p.Content, NewArray(attachments, logger))
+	r, e := tx.Exec(qs, p.ID)
 	if e != nil {
-		logger.Error("[Model.Reply] Failed to execute", e)
+		logger.Error("[Model.Posts] Failed to execute", e)
 		return ErrDbInternal
 	}
 	if r == 0 {
 		return ErrNotFound
 	}
+	qs = `  UPDATE posts
+			SET
+			  "edited" = $2, "content" = $3,
+			  "media" = $4, "source" = NULLIF($5, ''),
+			  "spoiler" = $6, "sensitive" = $7,
+			  "quotable" = $8, "reply_policy" = $9
+			WHERE "id" = $1;`
+	p.Edited = p.Edited.UTC()
+	if _, e := tx.Exec(qs,
+		p.ID, p.Edited, p.Content,
+		NewArray(attachments, logger), p.Source,
+		p.Spoiler, p.Sensitive, p.Quotable, string(p.ReplyPolicy),
+	); e != nil {
+		logger.Error("[Model.Posts] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if e := tx.Commit(); e != nil {
+		logger.Error("[Model.Posts] Cannot commit", e)
+		return ErrDbInternal
+	}
 	return nil
 }
 
@@ -471,33 +938,13 @@ func (db *PostDb) RemovePost(id string) error {
 //
 //   - DbInternal
 //   - NotFound "post"
-func (db *PostDb) QueryLikes(id string) (list []string, owner string, vsb utils.Vsb, err error) {
-	logger := db.lg
-	conn, err := db.pool.Open()
+func (db *PostDb) QueryLikes(id string, page utils.Page) (list []*UserAct, owner string, vsb utils.Vsb, err error) {
+	owner, vsb, err = db.queryOwner(id)
 	if err != nil {
-		logger.Error("[Model] Failed to open a connection", err)
-		return nil, "", vsb, ErrDbInternal
-	}
-	defer conn.Close()
-
-	qs := ` SELECT "user", "vsb", "likes"
-			FROM posts
-			WHERE "id" = $1;`
-	r := conn.QueryOne(qs, id)
-
-	u, v := "", ""
-	e := r.Scan(&u, &v, pq.Array(&list))
-	vsb, _ = utils.GetVsb(v)
-	if e != nil {
-		switch e {
-		case sql.ErrNoRows:
-			return nil, u, vsb, ErrNotFound
-		default:
-			logger.Error("[Model.Like] Cannot scan row", e)
-			return nil, "", vsb, ErrDbInternal
-		}
+		return nil, owner, vsb, err
 	}
-	return list, u, vsb, nil
+	list, err = db.queryUserActs("likes", id, page)
+	return list, owner, vsb, err
 }
 
 // ERRORS
@@ -505,7 +952,7 @@ func (db *PostDb) QueryLikes(id string) (list []string, owner string, vsb utils.
 //   - DbInternal
 //   - NotFound "post"
 //   - Dunplicate "like"
-func (db *PostDb) SetLike(user, id string) error {
+func (db *PostDb) SetLike(user, id string, date time.Time) error {
 	logger := db.lg
 	conn, err := db.pool.Open()
 	if err != nil {
@@ -517,19 +964,40 @@ func (db *PostDb) SetLike(user, id string) error {
 		return ErrNotFound
 	}
 
+	tx, e := conn.BeginTx()
+	if e != nil {
+		logger.Error("[Model.Like] Cannot start transaction", e)
+		return ErrDbInternal
+	}
+	defer tx.Rollback()
+
+	// update posts.likes
 	qs := ` UPDATE posts
 			SET "likes" = ARRAY_APPEND("likes", $1)
 			WHERE
 			  "id" = $2
-  			  AND ARRAY_POSITION("likes", $1) IS NULL;`
-	r, e := conn.Exec(qs, user, id)
+			  AND ARRAY_POSITION("likes", $1) IS NULL;`
+	r, e := tx.Exec(qs, user, id)
 	if e != nil {
-		logger.Error("[Model.Reply] Failed to execute", e)
+		logger.Error("[Model.Like] Failed to execute", e)
 		return ErrDbInternal
 	}
 	if r == 0 {
 		return ErrDunplicate
 	}
+
+	// insert into likes
+	qs = `  INSERT INTO likes("user", "id", "date")
+			VALUES ($1, $2, $3);`
+	if _, e := tx.Exec(qs, user, id, date.UTC()); e != nil {
+		logger.Error("[Model.Like] Failed to execute", e)
+		return ErrDbInternal
+	}
+
+	if e := tx.Commit(); e != nil {
+		logger.Error("[Model.Like] Cannot commit", e)
+		return ErrDbInternal
+	}
 	return nil
 }
 
@@ -546,51 +1014,109 @@ func (db *PostDb) RemoveLike(user, id string) error {
 	}
 	defer conn.Close()
 
+	tx, e := conn.BeginTx()
+	if e != nil {
+		logger.Error("[Model.Like] Cannot start transaction", e)
+		return ErrDbInternal
+	}
+	defer tx.Rollback()
+
 	qs := ` UPDATE posts
 			SET "likes" = ARRAY_REMOVE("likes", $1)
 			WHERE "id" = $2;`
-	r, e := conn.Exec(qs, user, id)
+	r, e := tx.Exec(qs, user, id)
 	if e != nil {
-		logger.Error("[Model.Reply] Failed to execute", e)
+		logger.Error("[Model.Like] Failed to execute", e)
 		return ErrDbInternal
 	}
 	if r == 0 {
 		return ErrNotFound
 	}
+
+	qs = `  DELETE FROM likes
+			WHERE "user" = $1 AND "id" = $2;`
+	if _, e := tx.Exec(qs, user, id); e != nil {
+		logger.Error("[Model.Like] Failed to execute", e)
+		return ErrDbInternal
+	}
+
+	if e := tx.Commit(); e != nil {
+		logger.Error("[Model.Like] Cannot commit", e)
+		return ErrDbInternal
+	}
 	return nil
 }
 
+// owner and visibility of the post
+//
 // ERRORS
 //
 //   - DbInternal
 //   - NotFound "post"
-func (db *PostDb) QueryShares(id string) (list []string, owner string, vsb utils.Vsb, err error) {
+func (db *PostDb) queryOwner(id string) (owner string, vsb utils.Vsb, err error) {
 	logger := db.lg
 	conn, err := db.pool.Open()
 	if err != nil {
-		logger.Error("[Model.Share] Failed to open a connection", err)
-		return nil, "", vsb, ErrDbInternal
+		logger.Error("[Model.Posts] Failed to open a connection", err)
+		return "", vsb, ErrDbInternal
 	}
 	defer conn.Close()
 
-	qs := ` SELECT "user", "vsb", "shares"
+	qs := ` SELECT "user", "vsb"
 			FROM posts
 			WHERE "id" = $1;`
-	r := conn.QueryOne(qs, id)
-
-	u, v := "", ""
-	e := r.Scan(&u, &v, pq.Array(&list))
-	vsb, _ = utils.GetVsb(v)
-	if e != nil {
+	v := ""
+	if e := conn.QueryOne(qs, id).Scan(&owner, &v); e != nil {
 		switch e {
 		case sql.ErrNoRows:
-			return nil, u, vsb, ErrNotFound
+			return "", vsb, ErrNotFound
 		default:
-			logger.Error("[Model.Like] Cannot scan row", e)
-			return nil, "", vsb, ErrDbInternal
+			logger.Error("[Model.Posts] Cannot scan row", e)
+			return "", vsb, ErrDbInternal
 		}
 	}
-	return list, u, vsb, nil
+	vsb, _ = utils.GetVsb(v)
+	return owner, vsb, nil
+}
+
+// users liking or sharing the post
+//
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) queryUserActs(table, id string, page utils.Page) (list []*UserAct, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Posts] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "user", "date"
+			FROM %s
+			WHERE "id" = $1 AND %s;`
+	clause, args := pageClause(page, `"date"`, `"user"`, []interface{}{id})
+	r, e := conn.Query(fmt.Sprintf(qs, table, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Posts] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return reversePage(page, scanUserActs(logger, r)), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "post"
+func (db *PostDb) QueryShares(id string, page utils.Page) (list []*UserAct, owner string, vsb utils.Vsb, err error) {
+	owner, vsb, err = db.queryOwner(id)
+	if err != nil {
+		return nil, owner, vsb, err
+	}
+	list, err = db.queryUserActs("shares", id, page)
+	return list, owner, vsb, err
 }
 
 // ERRORS
@@ -615,12 +1141,13 @@ func (db *PostDb) SetShare(user, id string, date time.Time, vsb utils.Vsb) error
 		logger.Error("[Model.Share] Cannot start transaction", e)
 		return ErrDbInternal
 	}
+	defer tx.Rollback()
 
 	// update posts.shares
 	qs := ` UPDATE posts
 			SET "shares" = ARRAY_APPEND("shares", $1)
 			WHERE
-			  "id" = $2 AND
+			  "id" = $2
 			  AND ARRAY_POSITION("shares", $1) IS NULL;
 	`
 	r, e := tx.Exec(qs, user, id)
@@ -672,6 +1199,7 @@ func (db *PostDb) RemoveShare(user, id string) error {
 		logger.Error("[Model.Share] Cannot start transaction", e)
 		return ErrDbInternal
 	}
+	defer tx.Rollback()
 
 	// use 2 annoymous func to ensure completely deletion
 
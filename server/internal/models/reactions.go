@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// models
+
+// reactions of a post with an emoji
+type Reaction struct {
+	Emoji string `json:"emoji"` // unicode, or ":shortcode:" of custom emoji
+	Count int64  `json:"count"`
+	Me    bool   `json:"me"` // reacted by the viewer
+}
+
+// db
+
+type IPostReaction interface {
+	// reacting twice with the same emoji is ok. a user may react with several emoji
+	SetReaction(user, id, emoji string, date time.Time) error
+	RemoveReaction(user, id, emoji string) error
+	// reactions of each of the posts, descending by count. posts without reactions are absent
+	QueryReactions(ids []string, user string) (m map[string][]*Reaction, err error)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "post"
+func (db *PostDb) SetReaction(user, id, emoji string, date time.Time) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Reactions] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+	if !db.IsPostExist(id) {
+		return ErrNotFound
+	}
+
+	qs := ` INSERT INTO reactions("post", "user", "emoji", "date")
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING;`
+	if _, e := conn.Exec(qs, id, user, emoji, date.UTC()); e != nil {
+		logger.Error("[Model.Reactions] Failed to execute", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "reaction"
+func (db *PostDb) RemoveReaction(user, id, emoji string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Reactions] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM reactions
+			WHERE "post" = $1 AND "user" = $2 AND "emoji" = $3;`
+	r, e := conn.Exec(qs, id, user, emoji)
+	if e != nil {
+		logger.Error("[Model.Reactions] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryReactions(ids []string, user string) (m map[string][]*Reaction, err error) {
+	logger := db.lg
+	m = make(map[string][]*Reaction)
+	if len(ids) == 0 {
+		return m, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Reactions] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	// emoji of the same count are in order of the first reacting
+	qs := ` SELECT
+			  "post", "emoji", COUNT(*) AS "count",
+			  BOOL_OR("user" = $2) AS "me"
+			FROM reactions
+			WHERE "post" = ANY($1)
+			GROUP BY "post", "emoji"
+			ORDER BY "post", "count" DESC, MIN("date") ASC;`
+	r, e := conn.Query(qs, pq.Array(ids), user)
+	if e != nil {
+		logger.Error("[Model.Reactions] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		var id string
+		rc := Reaction{}
+		if e := r.Scan(&id, &rc.Emoji, &rc.Count, &rc.Me); e != nil {
+			logger.Error("[Model.Reactions] Cannot scan row", e)
+			continue
+		}
+		m[id] = append(m[id], &rc)
+	}
+	return m, nil
+}
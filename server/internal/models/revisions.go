@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+// models
+
+// a previous version of a post
+type Revision struct {
+	ID        int64            `json:"id"`
+	Post      string           `json:"post"`
+	Content   string           `json:"content"`
+	Source    string           `json:"source"`
+	Media     Array[Img, *Img] `json:"media"`
+	Spoiler   string           `json:"spoiler"`
+	Sensitive bool             `json:"sensitive"`
+	Date      time.Time        `json:"date"` // when this version was published or edited
+}
+
+// db
+
+type IPostRevision interface {
+	// previous versions of the post, newest first
+	QueryRevisions(id string) (list []*Revision, err error)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryRevisions(id string) (list []*Revision, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Revisions] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  "id", "post", "content", COALESCE("source", ''),
+			  "media", "spoiler", "sensitive", "date"
+			FROM post_revisions
+			WHERE "post" = $1
+			ORDER BY "date" DESC, "id" DESC;`
+	r, e := conn.Query(qs, id)
+	if e != nil {
+		logger.Error("[Model.Revisions] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+
+	list = make([]*Revision, 0)
+	for r.Next() {
+		v := Revision{}
+		if e := r.Scan(
+			&v.ID, &v.Post, &v.Content, &v.Source,
+			v.Media.ToPqArray(), &v.Spoiler, &v.Sensitive, &v.Date,
+		); e != nil {
+			logger.Error("[Model.Revisions] Cannot scan row", e)
+			continue
+		}
+		list = append(list, &v)
+	}
+	return list, nil
+}
@@ -0,0 +1,253 @@
+package models
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/logging"
+)
+
+// models
+
+type ScheduledPoll struct {
+	Options    []string `json:"options"`
+	Multiple   bool     `json:"multiple"`
+	ExpiresIn  int64    `json:"expiresIn"` // seconds, from publishing
+	HideTotals bool     `json:"hideTotals"`
+}
+
+// what a scheduled post is published with
+type ScheduledParams struct {
+	Vsb         string         `json:"vsb"` // empty means by preference on publishing
+	Content     string         `json:"content"`
+	Media       []Img          `json:"media"`
+	Recipients  []string       `json:"recipients"`
+	Spoiler     string         `json:"spoiler"`
+	Sensitive   *bool          `json:"sensitive"`
+	Poll        *ScheduledPoll `json:"poll"`
+	Quoting     string         `json:"quoting"`
+	Quotable    *bool          `json:"quotable"`
+	ReplyPolicy string         `json:"replyPolicy"`
+}
+
+func (p ScheduledParams) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *ScheduledParams) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed.")
+	}
+	return json.Unmarshal(b, p)
+}
+
+type ScheduledPost struct {
+	ID     string          `json:"id"` // id of the post when published
+	User   string          `json:"user"`
+	Date   time.Time       `json:"date"` // when to publish
+	Params ScheduledParams `json:"params"`
+}
+
+// db
+
+type IScheduledPost interface {
+	SetScheduledPost(s *ScheduledPost) error
+	QueryScheduledPost(id string) (s ScheduledPost, err error)
+	// scheduled posts of the user, ascending by date
+	QueryScheduledPosts(user string) (list []*ScheduledPost, err error)
+	// claim scheduled posts due by now, not claimed in the lease before, for the caller
+	// to publish. each is claimed by one caller until the lease expires
+	ClaimDueScheduledPosts(now time.Time, lease time.Duration, limit int) (list []*ScheduledPost, err error)
+	// uses: ScheduledPost.ID, ScheduledPost.User, ScheduledPost.Date, ScheduledPost.Params
+	UpdateScheduledPost(s *ScheduledPost) error
+	RemoveScheduledPost(id string) error
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+//   - Dunplicate
+func (db *PostDb) SetScheduledPost(s *ScheduledPost) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Scheduled] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO scheduled_posts("id", "user", "date", "params")
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING;`
+	r, e := conn.Exec(qs, s.ID, s.User, s.Date.UTC(), s.Params)
+	if e != nil {
+		logger.Error("[Model.Scheduled] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrDunplicate
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound
+func (db *PostDb) QueryScheduledPost(id string) (s ScheduledPost, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Scheduled] Failed to open a connection", err)
+		return s, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "id", "user", "date", "params"
+			FROM scheduled_posts
+			WHERE "id" = $1;`
+	r := conn.QueryOne(qs, id)
+	if e := r.Scan(&s.ID, &s.User, &s.Date, &s.Params); e != nil {
+		switch e {
+		case sql.ErrNoRows:
+			return s, ErrNotFound
+		default:
+			logger.Error("[Model.Scheduled] Cannot scan row", e)
+			return s, ErrDbInternal
+		}
+	}
+	return s, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryScheduledPosts(user string) (list []*ScheduledPost, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Scheduled] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "id", "user", "date", "params"
+			FROM scheduled_posts
+			WHERE "user" = $1
+			ORDER BY "date" ASC, "id" ASC;`
+	r, e := conn.Query(qs, user)
+	if e != nil {
+		logger.Error("[Model.Scheduled] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanScheduledPosts(logger, r), nil
+}
+
+// rows locked by another caller claiming are skipped, so callers at the same
+// time claim different ones
+//
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) ClaimDueScheduledPosts(now time.Time, lease time.Duration, limit int) (list []*ScheduledPost, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Scheduled] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` UPDATE scheduled_posts
+			SET "claimed" = $1
+			WHERE "id" IN (
+			  SELECT "id"
+			  FROM scheduled_posts
+			  WHERE "date" <= $1 AND ("claimed" IS NULL OR "claimed" <= $2)
+			  ORDER BY "date" ASC, "id" ASC
+			  LIMIT $3
+			  FOR UPDATE SKIP LOCKED
+			)
+			RETURNING "id", "user", "date", "params";`
+	now = now.UTC()
+	r, e := conn.Query(qs, now, now.Add(-lease), limit)
+	if e != nil {
+		logger.Error("[Model.Scheduled] Cannot claim", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanScheduledPosts(logger, r), nil
+}
+
+func scanScheduledPosts(logger logging.Logger, r *sql.Rows) (list []*ScheduledPost) {
+	list = make([]*ScheduledPost, 0)
+	for r.Next() {
+		s := ScheduledPost{}
+		if e := r.Scan(&s.ID, &s.User, &s.Date, &s.Params); e != nil {
+			logger.Error("[Model.Scheduled] Cannot scan row", e)
+			continue
+		}
+		list = append(list, &s)
+	}
+	return list
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "scheduled post of the user"
+func (db *PostDb) UpdateScheduledPost(s *ScheduledPost) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Scheduled] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` UPDATE scheduled_posts
+			SET "date" = $3, "params" = $4
+			WHERE "id" = $1 AND "user" = $2;`
+	r, e := conn.Exec(qs, s.ID, s.User, s.Date.UTC(), s.Params)
+	if e != nil {
+		logger.Error("[Model.Scheduled] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound
+func (db *PostDb) RemoveScheduledPost(id string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Scheduled] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM scheduled_posts
+			WHERE "id" = $1;`
+	r, e := conn.Exec(qs, id)
+	if e != nil {
+		logger.Error("[Model.Scheduled] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// models
+
+// a hashtag with its latest use in public posts
+type TagUse struct {
+	Tag  string    `json:"tag"`
+	Date time.Time `json:"date"` // of the latest post with it
+	Uses int64     `json:"uses"` // count of public posts with it
+}
+
+// db
+
+type IPostSearch interface {
+	// posts matching the full-text query that may be visible to the viewer, descending by date.
+	// excludes posts of users opting out of search except the viewer's own,
+	// silenced domains and users muted by viewer
+	SearchPosts(query, viewer string, page utils.Page) (list []*Post, err error)
+	// hashtags of public posts matching the full-text query, descending by date of the latest use
+	SearchTags(query string, page utils.Page) (list []*TagUse, err error)
+}
+
+type IUserSearch interface {
+	// users of this site matching the full-text query, descending by date of registering
+	SearchUsers(query string, page utils.Page) (list []*UserAct, err error)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) SearchPosts(query, viewer string, page utils.Page) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Search] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	// visibility is prefiltered as checkPermission does, to keep pages full
+	qs := ` SELECT
+			  posts."id", posts."url", posts."user", posts."date",
+			  posts."vsb", posts."content", posts."media",
+			  CARDINALITY(posts."likes") as "likes",
+			  CARDINALITY(posts."shares") as "shares",
+			  p2."user" AS "replyTo", NULL AS "sharedBy",
+			  posts."date" AS "act", posts."recipients", posts."edited",
+			  posts."spoiler", posts."sensitive",
+			  posts."quoting", posts."quotable", posts."reply_policy"
+			FROM posts
+			  LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			  LEFT JOIN users AS u ON u."username" = posts."user"
+			WHERE
+			  posts."search" @@ websearch_to_tsquery('simple', $1)
+			  AND (
+			    posts."vsb" = 'public' OR posts."user" = $2
+			    OR (posts."vsb" = 'follower' AND (
+			      EXISTS (
+			        SELECT 1 FROM follow WHERE "from" = $2 AND "to" = posts."user"
+			      )
+			      OR EXISTS (
+			        SELECT 1 FROM mentions WHERE "id" = posts."id" AND "user" = $2
+			      )
+			    ))
+			    OR (posts."vsb" = 'direct' AND $2 = ANY(posts."recipients"))
+			  )
+			  AND (
+			    posts."user" = $2
+			    OR NOT COALESCE((u."preferences"->>'noSearch')::boolean, FALSE)
+			  )
+			  AND SPLIT_PART(posts."user", '@', 2) NOT IN (
+			    SELECT "domain" FROM silenced_domains
+			  )
+			  AND posts."user" NOT IN (
+			    SELECT "target" FROM mutes WHERE "user" = $2
+			  )
+			  AND %s;`
+	clause, args := pageClause(page, `posts."date"`, `posts."id"`, []interface{}{query, viewer})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Search] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return reversePage(page, scanPostsWithAct(logger, r)), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) SearchTags(query string, page utils.Page) (list []*TagUse, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Search] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "tag", "date", "uses"
+			FROM (
+			  SELECT t."tag", MAX(t."date") AS "date", COUNT(*) AS "uses"
+			  FROM post_tags AS t
+			    JOIN posts ON posts."id" = t."id"
+			  WHERE
+			    t."search" @@ websearch_to_tsquery('simple', $1)
+			    AND posts."vsb" = 'public'
+			  GROUP BY t."tag"
+			) AS tu
+			WHERE %s;`
+	clause, args := pageClause(page, `tu."date"`, `tu."tag"`, []interface{}{query})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Search] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+
+	list = make([]*TagUse, 0)
+	for r.Next() {
+		t := TagUse{}
+		if e := r.Scan(&t.Tag, &t.Date, &t.Uses); e != nil {
+			logger.Error("[Model.Search] Cannot scan row", e)
+			continue
+		}
+		list = append(list, &t)
+	}
+	return reversePage(page, list), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *UserDb) SearchUsers(query string, page utils.Page) (list []*UserAct, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Search] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "username" AS "user", "createdAt" AS "date"
+			FROM users
+			WHERE "search" @@ websearch_to_tsquery('simple', $1) AND %s;`
+	clause, args := pageClause(page, `"createdAt"`, `"username"`, []interface{}{query})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Search] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return reversePage(page, scanUserActs(logger, r)), nil
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+)
+
+const stream_prefix = "stream:"
+
+// names of streams
+
+const (
+	Stream_PUBLIC       = "public"
+	Stream_PUBLIC_LOCAL = "public:local"
+)
+
+func StreamOfUser(user string) string {
+	return "user:" + user
+}
+
+func StreamOfHashtag(tag string) string {
+	return "hashtag:" + tag
+}
+
+func StreamOfList(id string) string {
+	return "list:" + id
+}
+
+// models
+
+type StreamEventType string
+
+const (
+	Event_UPDATE       StreamEventType = "update"       // new post or share in timeline
+	Event_NOTIFICATION StreamEventType = "notification" // new notification
+	Event_DELETE       StreamEventType = "delete"       // post removed
+	Event_EDIT         StreamEventType = "edit"         // post edited
+)
+
+type StreamEvent struct {
+	Event        StreamEventType `json:"event"`
+	Item         *TimelineItem   `json:"item,omitempty"`         // when update
+	Notification *Notification   `json:"notification,omitempty"` // when notification
+	PostID       string          `json:"postID,omitempty"`       // when delete or edit
+}
+
+// db
+
+type IStream interface {
+	Publish(streams []string, e StreamEvent) error
+	// handle events of all streams. blocks until the subscription fails
+	Listen(handler func(stream string, e StreamEvent)) error
+}
+
+// should implemented with Redis pub/sub
+type StreamDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.RdConn]
+}
+
+var streamIns *StreamDb = nil
+
+func StreamInstance(lg logging.Logger) *StreamDb {
+	if streamIns == nil {
+		streamIns = &StreamDb{
+			lg:   lg,
+			pool: _db.StreamPool(nil, nil),
+		}
+	}
+	return streamIns
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *StreamDb) Publish(streams []string, e StreamEvent) error {
+	logger := db.lg
+	if len(streams) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		logger.Error("[Model.Stream] Cannot marshal event", err)
+		return ErrSyntax
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Stream] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	channels := make([]string, 0, len(streams))
+	for _, v := range streams {
+		channels = append(channels, stream_prefix+v)
+	}
+	if e := conn.Publish(channels, string(b)); e != nil {
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *StreamDb) Listen(handler func(stream string, e StreamEvent)) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Stream] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	return conn.Listen(stream_prefix+"*", func(channel, payload string) {
+		e := StreamEvent{}
+		if err := json.Unmarshal([]byte(payload), &e); err != nil {
+			logger.Error("[Model.Stream] Cannot unmarshal event", err)
+			return
+		}
+		handler(strings.TrimPrefix(channel, stream_prefix), e)
+	})
+}
@@ -0,0 +1,263 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/utils"
+	"github.com/lib/pq"
+)
+
+// db
+
+type IPostTag interface {
+	// replace hashtags of the post. tags should be normalized
+	SetTags(id string, tags []string) error
+	// hashtags of each post, in order of appearing. posts without are absent
+	QueryTags(ids []string) (tags map[string][]string, err error)
+	// public posts with the hashtag, descending by date.
+	// excludes silenced domains and users muted by viewer
+	QueryTagTimeline(tag, viewer string, page utils.Page) (list []*Post, err error)
+}
+
+type ITagFollow interface {
+	IsFollowingTag(user, tag string) bool
+	SetTagFollow(user, tag string, date time.Time) error
+	RemoveTagFollow(user, tag string) error
+	// users following any of the hashtags
+	QueryTagFollowers(tags []string) (list []string, err error)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) SetTags(id string, tags []string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Tags] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	tx, e := conn.BeginTx()
+	if e != nil {
+		logger.Error("[Model.Tags] Cannot start transaction", e)
+		return ErrDbInternal
+	}
+	defer tx.Rollback()
+	qs := ` DELETE FROM post_tags
+			WHERE "id" = $1;`
+	if _, e := tx.Exec(qs, id); e != nil {
+		logger.Error("[Model.Tags] Failed to execute", e)
+		return ErrDbInternal
+	}
+	qs = `  INSERT INTO post_tags("id", "tag", "index", "date")
+			SELECT $1, t, i, posts."date"
+			FROM UNNEST($2::text[]) WITH ORDINALITY AS m(t, i)
+			  JOIN posts ON posts."id" = $1
+			ON CONFLICT DO NOTHING;`
+	if _, e := tx.Exec(qs, id, pq.Array(tags)); e != nil {
+		logger.Error("[Model.Tags] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if e := tx.Commit(); e != nil {
+		logger.Error("[Model.Tags] Cannot commit", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryTags(ids []string) (tags map[string][]string, err error) {
+	logger := db.lg
+	tags = make(map[string][]string)
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Tags] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "id", "tag"
+			FROM post_tags
+			WHERE "id" = ANY($1)
+			ORDER BY "id", "index" ASC;`
+	r, e := conn.Query(qs, pq.Array(ids))
+	if e != nil {
+		logger.Error("[Model.Tags] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		var id, tag string
+		if e := r.Scan(&id, &tag); e != nil {
+			logger.Error("[Model.Tags] Cannot scan row", e)
+			continue
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	return tags, nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryTagTimeline(tag, viewer string, page utils.Page) (list []*Post, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Tags] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  posts."id", posts."url", posts."user", posts."date",
+			  posts."vsb", posts."content", posts."media",
+			  CARDINALITY(posts."likes") as "likes",
+			  CARDINALITY(posts."shares") as "shares",
+			  p2."user" AS "replyTo", NULL AS "sharedBy",
+			  posts."date" AS "act", posts."recipients", posts."edited",
+			  posts."spoiler", posts."sensitive",
+			  posts."quoting", posts."quotable", posts."reply_policy"
+			FROM post_tags AS t
+			  JOIN posts ON posts."id" = t."id"
+			  LEFT JOIN posts AS p2 ON p2."id" = posts."replying"
+			WHERE
+			  t."tag" = $1 AND posts."vsb" = 'public'
+			  AND SPLIT_PART(posts."user", '@', 2) NOT IN (
+			    SELECT "domain" FROM silenced_domains
+			  )
+			  AND posts."user" NOT IN (
+			    SELECT "target" FROM mutes WHERE "user" = $2
+			  )
+			  AND %s;`
+	clause, args := pageClause(page, `t."date"`, `t."id"`, []interface{}{tag, viewer})
+	r, e := conn.Query(fmt.Sprintf(qs, clause), args...)
+	if e != nil {
+		logger.Error("[Model.Tags] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return reversePage(page, scanPostsWithAct(logger, r)), nil
+}
+
+func (db *PostDb) IsFollowingTag(user, tag string) bool {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Tags] Failed to open a connection", err)
+		return false
+	}
+	defer conn.Close()
+
+	qs := `SELECT 1
+		   FROM tag_follows
+		   WHERE "user" = $1 AND "tag" = $2;`
+	var n int
+	if e := conn.QueryOne(qs, user, tag).Scan(&n); e != nil {
+		if e != sql.ErrNoRows {
+			logger.Error("[Model.Tags] Cannot query", e)
+		}
+		return false
+	}
+	return true
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "user"
+func (db *PostDb) SetTagFollow(user, tag string, date time.Time) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Tags] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO tag_follows("user", "tag", "date")
+			SELECT $1, $2, $3
+			WHERE EXISTS (SELECT 1 FROM users WHERE "username" = $1)
+			ON CONFLICT DO NOTHING;`
+	if _, e := conn.Exec(qs, user, tag, date.UTC()); e != nil {
+		logger.Error("[Model.Tags] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if !db.IsFollowingTag(user, tag) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "follow"
+func (db *PostDb) RemoveTagFollow(user, tag string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Tags] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM tag_follows
+			WHERE "user" = $1 AND "tag" = $2;`
+	r, e := conn.Exec(qs, user, tag)
+	if e != nil {
+		logger.Error("[Model.Tags] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryTagFollowers(tags []string) (list []string, err error) {
+	logger := db.lg
+	list = make([]string, 0)
+	if len(tags) == 0 {
+		return list, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Tags] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT DISTINCT "user"
+			FROM tag_follows
+			WHERE "tag" = ANY($1);`
+	r, e := conn.Query(qs, pq.Array(tags))
+	if e != nil {
+		logger.Error("[Model.Tags] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		var u string
+		if e := r.Scan(&u); e != nil {
+			logger.Error("[Model.Tags] Cannot scan row", e)
+			continue
+		}
+		list = append(list, u)
+	}
+	return list, nil
+}
@@ -0,0 +1,357 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+)
+
+// a cached timeline will be evicted if nobody reads it in this duration
+const timeline_expire = 7 * 24 * time.Hour
+
+// models
+
+// an entry of timeline. SharedBy is empty if it's not a share
+type TimelineItem struct {
+	PostID   string    `json:"postID"`
+	SharedBy string    `json:"sharedBy"`
+	Date     time.Time `json:"date"`
+}
+
+// member of the item in cached timelines, breaking ties of date.
+// "<postID>" for a post, "<postID>|<sharer>" for a share
+func (item TimelineItem) ID() string {
+	if item.SharedBy == "" {
+		return item.PostID
+	}
+	return item.PostID + "|" + item.SharedBy
+}
+
+func (item TimelineItem) score() float64 {
+	return float64(item.Date.UnixMilli())
+}
+
+func parseTimelineItem(z _db.Z) (item TimelineItem, ok bool) {
+	m, ok := z.Member.(string)
+	if !ok || m == "" {
+		return item, false
+	}
+	fields := strings.SplitN(m, "|", 2)
+	item.PostID = fields[0]
+	if len(fields) > 1 {
+		item.SharedBy = fields[1]
+	}
+	item.Date = time.UnixMilli(int64(z.Score)).UTC()
+	return item, true
+}
+
+// db
+
+type ITimeline interface {
+	IsTimelineExist(user string) bool
+	// push item into timelines existing. max <= 0 means no trimming
+	PushTimeline(users []string, item TimelineItem, max int) error
+	RemoveFromTimeline(users []string, item TimelineItem) error
+	// items before (date, id), descending. see TimelineItem.ID
+	QueryTimeline(user string, before time.Time, beforeID string, count int) (list []TimelineItem, err error)
+	// replace the timeline with the items
+	SetTimeline(user string, items []TimelineItem) error
+	// evict the timeline, to be rebuilt when read
+	RemoveTimeline(user string) error
+}
+
+// cached list timelines, working as home timelines do
+type IListTimeline interface {
+	// push item into list timelines existing. max <= 0 means no trimming
+	PushListTimeline(lists []string, item TimelineItem, max int) error
+	RemoveFromListTimeline(lists []string, item TimelineItem) error
+	// items before (date, id), descending. see TimelineItem.ID
+	QueryCachedListTimeline(list string, before time.Time, beforeID string, count int) (items []TimelineItem, err error)
+	// replace the list timeline with the items
+	SetListTimeline(list string, items []TimelineItem) error
+	// evict the list timeline, to be rebuilt when read
+	RemoveListTimeline(list string) error
+}
+
+// should implemented with Redis
+type TimelineDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.RdConn]
+}
+
+var timelineIns *TimelineDb = nil
+
+func TimelineInstance(lg logging.Logger) *TimelineDb {
+	if timelineIns == nil {
+		timelineIns = &TimelineDb{
+			lg:   lg,
+			pool: _db.TimelinePool(nil, nil),
+		}
+	}
+	return timelineIns
+}
+
+func timelineKey(user string) string {
+	return "home:" + user
+}
+
+func listTimelineKey(list string) string {
+	return "list:" + list
+}
+
+func keysOf(ids []string, key func(string) string) []string {
+	keys := make([]string, 0, len(ids))
+	for _, v := range ids {
+		keys = append(keys, key(v))
+	}
+	return keys
+}
+
+// functions
+
+func (db *TimelineDb) IsTimelineExist(user string) bool {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return false
+	}
+	defer conn.Close()
+
+	ok, e := conn.Exists(timelineKey(user))
+	if e != nil {
+		logger.Error("[Model.Timeline] Cannot check timeline", e)
+		return false
+	}
+	return ok
+}
+
+// timelines not cached are skipped. they will be rebuilt when read
+//
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) PushTimeline(users []string, item TimelineItem, max int) error {
+	return db.push(keysOf(users, timelineKey), item, max)
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) RemoveFromTimeline(users []string, item TimelineItem) error {
+	return db.remove(keysOf(users, timelineKey), item)
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "timeline", not cached or evicted
+func (db *TimelineDb) QueryTimeline(user string, before time.Time, beforeID string, count int) (list []TimelineItem, err error) {
+	return db.query(timelineKey(user), before, beforeID, count)
+}
+
+// an empty timeline is also cached, to mark it's been built
+//
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) SetTimeline(user string, items []TimelineItem) error {
+	return db.set(timelineKey(user), items)
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) RemoveTimeline(user string) error {
+	return db.evict(timelineKey(user))
+}
+
+// list timelines not cached are skipped. they will be rebuilt when read
+//
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) PushListTimeline(lists []string, item TimelineItem, max int) error {
+	return db.push(keysOf(lists, listTimelineKey), item, max)
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) RemoveFromListTimeline(lists []string, item TimelineItem) error {
+	return db.remove(keysOf(lists, listTimelineKey), item)
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "timeline", not cached or evicted
+func (db *TimelineDb) QueryCachedListTimeline(list string, before time.Time, beforeID string, count int) (items []TimelineItem, err error) {
+	return db.query(listTimelineKey(list), before, beforeID, count)
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) SetListTimeline(list string, items []TimelineItem) error {
+	return db.set(listTimelineKey(list), items)
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TimelineDb) RemoveListTimeline(list string) error {
+	return db.evict(listTimelineKey(list))
+}
+
+func (db *TimelineDb) evict(key string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	if e := conn.Del(key); e != nil {
+		return ErrDbInternal
+	}
+	return nil
+}
+
+func (db *TimelineDb) push(keys []string, item TimelineItem, max int) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	z := _db.Z{Score: item.score(), Member: item.ID()}
+	failed := 0
+	for _, key := range keys {
+		ok, e := conn.Exists(key)
+		if e != nil {
+			failed += 1
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if e := conn.ZAddTrim(key, int64(max), z); e != nil {
+			msg := fmt.Sprintf("[Model.Timeline] Cannot push to %s", key)
+			logger.Error(msg, e)
+			failed += 1
+		}
+	}
+	if failed != 0 {
+		return ErrDbInternal
+	}
+	return nil
+}
+
+func (db *TimelineDb) remove(keys []string, item TimelineItem) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	failed := 0
+	for _, key := range keys {
+		if e := conn.ZRem(key, item.ID()); e != nil {
+			failed += 1
+		}
+	}
+	if failed != 0 {
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// scores are in milliseconds, so items of the same millisecond as the cursor
+// are read first, those after beforeID in the order of Redis
+func (db *TimelineDb) query(key string, before time.Time, beforeID string, count int) (list []TimelineItem, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	ok, e := conn.Exists(key)
+	if e != nil {
+		return nil, ErrDbInternal
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	list = make([]TimelineItem, 0, count)
+	max := math.Inf(1)
+	if !before.IsZero() {
+		max = float64(before.UnixMilli())
+	}
+	if !before.IsZero() && beforeID != "" {
+		ties, e := conn.ZRevRangeAt(key, max)
+		if e != nil {
+			logger.Error("[Model.Timeline] Cannot query timeline", e)
+			return nil, ErrDbInternal
+		}
+		for _, z := range ties {
+			item, ok := parseTimelineItem(z)
+			if ok && item.ID() < beforeID && len(list) < count {
+				list = append(list, item)
+			}
+		}
+	}
+	if len(list) < count {
+		zs, e := conn.ZRevRangeBefore(key, max, int64(count-len(list)))
+		if e != nil {
+			logger.Error("[Model.Timeline] Cannot query timeline", e)
+			return nil, ErrDbInternal
+		}
+		for _, z := range zs {
+			if item, ok := parseTimelineItem(z); ok {
+				list = append(list, item)
+			}
+		}
+	}
+	conn.Expire(key, timeline_expire)
+	return list, nil
+}
+
+func (db *TimelineDb) set(key string, items []TimelineItem) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Timeline] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	if e := conn.Del(key); e != nil {
+		return ErrDbInternal
+	}
+	zs := make([]_db.Z, 0, len(items)+1)
+	// placeholder with the lowest score, keeping the key alive when empty
+	zs = append(zs, _db.Z{Score: 0, Member: ""})
+	for _, v := range items {
+		zs = append(zs, _db.Z{Score: v.score(), Member: v.ID()})
+	}
+	if e := conn.ZAddTrim(key, 0, zs...); e != nil {
+		msg := fmt.Sprintf("[Model.Timeline] Cannot set %s", key)
+		logger.Error(msg, e)
+		return ErrDbInternal
+	}
+	conn.Expire(key, timeline_expire)
+	return nil
+}
@@ -0,0 +1,365 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	_db "github.com/kidommoc/gustrody/internal/db"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/lib/pq"
+)
+
+// models
+
+type TrendKind string
+
+const (
+	Trd_TAG  TrendKind = "tag"
+	Trd_POST TrendKind = "post"
+	Trd_LINK TrendKind = "link"
+)
+
+// usage of a hashtag or a link, or engagement (likes and shares) of a post, in time windows
+type Trend struct {
+	Key      string    `json:"key"`      // hashtag, post id, or url
+	Short    int64     `json:"short"`    // count in the short window
+	Long     int64     `json:"long"`     // count in the long window, including the short one
+	Accounts int64     `json:"accounts"` // distinct users in the long window
+	Score    float64   `json:"score"`
+	Approved bool      `json:"approved"` // by admins. not approved ones are pending
+	Date     time.Time `json:"date"`     // of computing
+}
+
+// db
+
+type ITrend interface {
+	// hashtags used in public posts in the long window before now, with the uses in the short one.
+	// descending by velocity: uses per hour averaged over the windows, times the share of users making them
+	QueryTagTrends(now time.Time, short, long time.Duration, limit int) (list []*Trend, err error)
+	// public posts liked or shared in the long window before now, with the engagement in the short one.
+	// descending by velocity as hashtags. excludes silenced domains
+	QueryPostTrends(now time.Time, short, long time.Duration, limit int) (list []*Trend, err error)
+	// urls linked in public posts in the long window before now, counted by posts, with the ones
+	// in the short window. descending by velocity as hashtags. excludes silenced domains
+	QueryLinkTrends(now time.Time, short, long time.Duration, limit int) (list []*Trend, err error)
+	// approve or hide a trend, replacing the review before
+	SetTrendReview(kind TrendKind, key string, approved bool, admin string, date time.Time) error
+	// reviews of the trends, true if approved. ones not reviewed are absent
+	QueryTrendReviews(kind TrendKind, keys []string) (reviews map[string]bool, err error)
+}
+
+type ITrendCache interface {
+	// replace the trends of the kind, kept for the duration
+	SetTrends(kind TrendKind, list []*Trend, exp time.Duration) error
+	// descending by score
+	QueryTrends(kind TrendKind) (list []*Trend, err error)
+}
+
+// should implemented with Postgre
+type TrendDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.PqConn]
+}
+
+var trendIns *TrendDb = nil
+
+func TrendInstance(lg logging.Logger) *TrendDb {
+	if trendIns == nil {
+		trendIns = &TrendDb{
+			lg:   lg,
+			pool: _db.MainPool(nil, nil),
+		}
+	}
+	return trendIns
+}
+
+// should implemented with Redis
+type TrendCacheDb struct {
+	lg   logging.Logger
+	pool *_db.ConnPool[*_db.RdConn]
+}
+
+var trendCacheIns *TrendCacheDb = nil
+
+func TrendCacheInstance(lg logging.Logger) *TrendCacheDb {
+	if trendCacheIns == nil {
+		trendCacheIns = &TrendCacheDb{
+			lg:   lg,
+			pool: _db.TimelinePool(nil, nil),
+		}
+	}
+	return trendCacheIns
+}
+
+func trendsKey(kind TrendKind) string {
+	return "trends:" + string(kind)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TrendDb) QueryTagTrends(now time.Time, short, long time.Duration, limit int) (list []*Trend, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Trend] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "tag", "short", "long", "accounts"
+			FROM (
+			  SELECT
+			    t."tag",
+			    COUNT(*) FILTER (WHERE t."date" >= $1) AS "short",
+			    COUNT(*) AS "long",
+			    COUNT(DISTINCT posts."user") AS "accounts"
+			  FROM post_tags AS t
+			    JOIN posts ON posts."id" = t."id"
+			  WHERE t."date" >= $2 AND posts."vsb" = 'public'
+			  GROUP BY t."tag"
+			) AS c
+			ORDER BY
+			  ("short" / $3::float8 + "long" / $4::float8) * "accounts" / "long" DESC,
+			  "accounts" DESC
+			LIMIT $5;`
+	r, e := conn.Query(qs,
+		now.Add(-short).UTC(), now.Add(-long).UTC(), short.Hours(), long.Hours(), limit,
+	)
+	if e != nil {
+		logger.Error("[Model.Trend] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanTrends(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TrendDb) QueryPostTrends(now time.Time, short, long time.Duration, limit int) (list []*Trend, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Trend] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` WITH acts AS (
+			    SELECT "id", "user", "date" FROM likes
+			    WHERE "date" >= $2
+			  UNION ALL
+			    SELECT "id", "user", "date" FROM shares
+			    WHERE "date" >= $2
+			)
+			SELECT "id", "short", "long", "accounts"
+			FROM (
+			  SELECT
+			    acts."id",
+			    COUNT(*) FILTER (WHERE acts."date" >= $1) AS "short",
+			    COUNT(*) AS "long",
+			    COUNT(DISTINCT acts."user") AS "accounts"
+			  FROM acts
+			    JOIN posts ON posts."id" = acts."id"
+			  WHERE
+			    posts."vsb" = 'public'
+			    AND SPLIT_PART(posts."user", '@', 2) NOT IN (
+			      SELECT "domain" FROM silenced_domains
+			    )
+			  GROUP BY acts."id"
+			) AS c
+			ORDER BY
+			  ("short" / $3::float8 + "long" / $4::float8) * "accounts" / "long" DESC,
+			  "accounts" DESC
+			LIMIT $5;`
+	r, e := conn.Query(qs,
+		now.Add(-short).UTC(), now.Add(-long).UTC(), short.Hours(), long.Hours(), limit,
+	)
+	if e != nil {
+		logger.Error("[Model.Trend] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanTrends(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TrendDb) QueryLinkTrends(now time.Time, short, long time.Duration, limit int) (list []*Trend, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Trend] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "url", "short", "long", "accounts"
+			FROM (
+			  SELECT
+			    l."url",
+			    COUNT(*) FILTER (WHERE l."date" >= $1) AS "short",
+			    COUNT(*) AS "long",
+			    COUNT(DISTINCT posts."user") AS "accounts"
+			  FROM post_links AS l
+			    JOIN posts ON posts."id" = l."id"
+			  WHERE
+			    l."date" >= $2 AND posts."vsb" = 'public'
+			    AND SPLIT_PART(posts."user", '@', 2) NOT IN (
+			      SELECT "domain" FROM silenced_domains
+			    )
+			  GROUP BY l."url"
+			) AS c
+			ORDER BY
+			  ("short" / $3::float8 + "long" / $4::float8) * "accounts" / "long" DESC,
+			  "accounts" DESC
+			LIMIT $5;`
+	r, e := conn.Query(qs,
+		now.Add(-short).UTC(), now.Add(-long).UTC(), short.Hours(), long.Hours(), limit,
+	)
+	if e != nil {
+		logger.Error("[Model.Trend] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	return scanTrends(logger, r), nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TrendDb) SetTrendReview(kind TrendKind, key string, approved bool, admin string, date time.Time) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Trend] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO trend_reviews("kind", "key", "approved", "admin", "date")
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT ("kind", "key") DO UPDATE
+			SET
+			  "approved" = EXCLUDED."approved",
+			  "admin" = EXCLUDED."admin", "date" = EXCLUDED."date";`
+	if _, e := conn.Exec(qs, string(kind), key, approved, admin, date.UTC()); e != nil {
+		logger.Error("[Model.Trend] Failed to execute", e)
+		return ErrDbInternal
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TrendDb) QueryTrendReviews(kind TrendKind, keys []string) (reviews map[string]bool, err error) {
+	logger := db.lg
+	reviews = make(map[string]bool)
+	if len(keys) == 0 {
+		return reviews, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Trend] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT "key", "approved"
+			FROM trend_reviews
+			WHERE "kind" = $1 AND "key" = ANY($2);`
+	r, e := conn.Query(qs, string(kind), pq.Array(keys))
+	if e != nil {
+		logger.Error("[Model.Trend] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		var key string
+		var approved bool
+		if e := r.Scan(&key, &approved); e != nil {
+			logger.Error("[Model.Trend] Cannot scan row", e)
+			continue
+		}
+		reviews[key] = approved
+	}
+	return reviews, nil
+}
+
+// scan rows of key, "short", "long" and "accounts"
+func scanTrends(logger logging.Logger, r *sql.Rows) (list []*Trend) {
+	list = make([]*Trend, 0)
+	for r.Next() {
+		t := Trend{}
+		if e := r.Scan(&t.Key, &t.Short, &t.Long, &t.Accounts); e != nil {
+			logger.Error("[Model.Trend] Cannot scan row", e)
+			continue
+		}
+		list = append(list, &t)
+	}
+	return list
+}
+
+// ERRORS
+//
+//   - DbInternal
+func (db *TrendCacheDb) SetTrends(kind TrendKind, list []*Trend, exp time.Duration) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.TrendCache] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	b, e := json.Marshal(list)
+	if e != nil {
+		logger.Error("[Model.TrendCache] Cannot marshal trends", e)
+		return ErrDbInternal
+	}
+	key := trendsKey(kind)
+	if e := conn.SetString(key, string(b)); e != nil {
+		return ErrDbInternal
+	}
+	conn.Expire(key, exp)
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "trends", not computed yet or expired
+func (db *TrendCacheDb) QueryTrends(kind TrendKind) (list []*Trend, err error) {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.TrendCache] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	key := trendsKey(kind)
+	ok, e := conn.Exists(key)
+	if e != nil {
+		return nil, ErrDbInternal
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s, e := conn.Get(key)
+	if e != nil {
+		return nil, ErrDbInternal
+	}
+	list = make([]*Trend, 0)
+	if e := json.Unmarshal([]byte(s), &list); e != nil {
+		logger.Error("[Model.TrendCache] Cannot unmarshal trends", e)
+		return nil, ErrDbInternal
+	}
+	return list, nil
+}
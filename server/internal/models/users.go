@@ -43,8 +43,10 @@ func (kp *KeyPair) Scan(src interface{}) error {
 }
 
 type Preferences struct {
-	PostVsb  string `json:"postVsb"`
-	ShareVsb string `json:"shareVsb"`
+	PostVsb   string `json:"postVsb"`
+	ShareVsb  string `json:"shareVsb"`
+	Sensitive bool   `json:"sensitive"`
+	NoSearch  bool   `json:"noSearch"`
 }
 
 func (p Preferences) Value() (driver.Value, error) {
@@ -93,12 +95,20 @@ type IUserInfo interface {
 type IUserFollow interface {
 	IsFollowing(username, target string) bool
 	QueryUserFollowInfo(username string) (follows int64, followed int64, err error)
-	QueryUserFollowings(username string) (list []*User, err error)
-	QueryUserFollowers(username string) (list []*User, err error)
+	// descending by date of following
+	QueryUserFollowings(username string, page utils.Page) (list []*UserAct, err error)
+	QueryUserFollowers(username string, page utils.Page) (list []*UserAct, err error)
+	QueryLocalFollowers(username string) (list []string, err error)
 	SetFollow(from, to string) error
 	RemoveFollow(from, to string) error
 }
 
+type IUserMute interface {
+	IsMuting(username, target string) bool
+	SetMute(user, target string) error
+	RemoveMute(user, target string) error
+}
+
 // should implemented with Postgre
 type UserDb struct {
 	lg   logging.Logger
@@ -379,7 +389,20 @@ func (db *UserDb) QueryUserFollowInfo(username string) (follows int64, followed
 //
 //   - DbInternal
 //   - NotFound "user"
-func (db *UserDb) QueryUserFollowings(username string) (list []*User, err error) {
+func (db *UserDb) QueryUserFollowings(username string, page utils.Page) (list []*UserAct, err error) {
+	return db.queryFollows(username, `"from"`, `"to"`, page)
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "user"
+func (db *UserDb) QueryUserFollowers(username string, page utils.Page) (list []*UserAct, err error) {
+	return db.queryFollows(username, `"to"`, `"from"`, page)
+}
+
+// users on the other side of the follows of username, descending by date
+func (db *UserDb) queryFollows(username, side, other string, page utils.Page) (list []*UserAct, err error) {
 	logger := db.lg
 	conn, err := db.pool.Open()
 	if err != nil {
@@ -388,41 +411,28 @@ func (db *UserDb) QueryUserFollowings(username string) (list []*User, err error)
 	}
 	defer conn.Close()
 	if !db.IsUserExist(username) {
-		return list, ErrNotFound
+		return nil, ErrNotFound
 	}
 
-	qs := ` SELECT "to" AS "following"
+	qs := ` SELECT %[2]s AS "user", "date"
 			FROM follow
-			WHERE "from" = $1;
-	`
-	r, e := conn.Query(qs, username)
+			WHERE %[1]s = $1 AND %[3]s;`
+	clause, args := pageClause(page, `"date"`, other, []interface{}{username})
+	r, e := conn.Query(fmt.Sprintf(qs, side, other, clause), args...)
 	if e != nil {
 		logger.Error("[Model.UserFollow] Failed to query", e)
 		return nil, ErrDbInternal
 	}
-
-	list = make([]*User, 0)
-	for r.Next() {
-		var f string
-		if e := r.Scan(&f); e != nil {
-			logger.Error("[Model.UserFollow] Cannot scan row", e)
-			continue
-		}
-		u, e := db.QueryUser(f)
-		if e != nil {
-			logger.Error("[Model.UserFollow] Cannot find user", e)
-			continue
-		}
-		list = append(list, &u)
-	}
-	return list, nil
+	defer r.Close()
+	return reversePage(page, scanUserActs(logger, r)), nil
 }
 
+// followers of this site, for fanning out
+//
 // ERRORS
 //
 //   - DbInternal
-//   - NotFound "user"
-func (db *UserDb) QueryUserFollowers(username string) (list []*User, err error) {
+func (db *UserDb) QueryLocalFollowers(username string) (list []string, err error) {
 	logger := db.lg
 	conn, err := db.pool.Open()
 	if err != nil {
@@ -430,32 +440,26 @@ func (db *UserDb) QueryUserFollowers(username string) (list []*User, err error)
 		return nil, ErrDbInternal
 	}
 	defer conn.Close()
-	if !db.IsUserExist(username) {
-		return nil, ErrNotFound
-	}
 
-	qs := ` SELECT "from" AS "follower"
+	// local users have no domain part
+	qs := ` SELECT "from"
 			FROM follow
-			WHERE "to" = $1;`
+			WHERE "to" = $1 AND STRPOS("from", '@') = 0;`
 	r, e := conn.Query(qs, username)
 	if e != nil {
 		logger.Error("[Model.UserFollow] Failed to query", e)
 		return nil, ErrDbInternal
 	}
+	defer r.Close()
 
-	list = make([]*User, 0)
+	list = make([]string, 0)
 	for r.Next() {
 		var f string
 		if e := r.Scan(&f); e != nil {
 			logger.Error("[Model.UserFollow] Cannot scan row", e)
 			continue
 		}
-		u, e := db.QueryUser(f)
-		if e != nil {
-			logger.Error("[Model.UserFollow] Cannot find user", e)
-			continue
-		}
-		list = append(list, &u)
+		list = append(list, f)
 	}
 	return list, nil
 }
@@ -474,9 +478,9 @@ func (db *UserDb) SetFollow(from string, to string) error {
 	}
 	defer conn.Close()
 
-	qs := ` INSERT INTO follow
-			VALUES ($1, $2);`
-	r, e := conn.Exec(qs, from, to)
+	qs := ` INSERT INTO follow("from", "to", "date")
+			VALUES ($1, $2, $3);`
+	r, e := conn.Exec(qs, from, to, time.Now().UTC())
 	if e != nil {
 		logger.Error("[Model.UserFollow] Failed to execute", e)
 		return ErrDbInternal
@@ -512,3 +516,84 @@ func (db *UserDb) RemoveFollow(from string, to string) error {
 	}
 	return nil
 }
+
+// mute
+
+func (db *UserDb) IsMuting(username string, target string) bool {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model] Failed to open a connection", err)
+		return false
+	}
+	defer conn.Close()
+
+	qs := ` SELECT 1
+			FROM mutes
+			WHERE "user" = $1 AND "target" = $2;`
+	r := conn.QueryOne(qs, username, target)
+	var n int
+	if e := r.Scan(&n); e != nil {
+		switch e {
+		case sql.ErrNoRows:
+			return false
+		default:
+			logger.Error("[Model.UserMute] Cannot query", e)
+			return false
+		}
+	}
+	return true
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - Dunplicate "mute"
+func (db *UserDb) SetMute(user string, target string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` INSERT INTO mutes("user", "target")
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING;`
+	r, e := conn.Exec(qs, user, target)
+	if e != nil {
+		logger.Error("[Model.UserMute] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrDunplicate
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - DbInternal
+//   - NotFound "mute"
+func (db *UserDb) RemoveMute(user string, target string) error {
+	logger := db.lg
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model] Failed to open a connection", err)
+		return ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` DELETE FROM mutes
+			WHERE "user" = $1 AND "target" = $2;`
+	r, e := conn.Exec(qs, user, target)
+	if e != nil {
+		logger.Error("[Model.UserMute] Failed to execute", e)
+		return ErrDbInternal
+	}
+	if r == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"github.com/lib/pq"
+)
+
+// models
+
+// how a user has acted on a post
+type ViewerState struct {
+	Liked      bool `json:"liked"`
+	Shared     bool `json:"shared"`
+	Bookmarked bool `json:"bookmarked"`
+}
+
+// db
+
+type IPostViewer interface {
+	// how the user has acted on each of the posts, in one query. posts not found are absent
+	QueryViewerStates(ids []string, user string) (states map[string]*ViewerState, err error)
+}
+
+// functions
+
+// ERRORS
+//
+//   - DbInternal
+func (db *PostDb) QueryViewerStates(ids []string, user string) (states map[string]*ViewerState, err error) {
+	logger := db.lg
+	states = make(map[string]*ViewerState)
+	if len(ids) == 0 || user == "" {
+		return states, nil
+	}
+	conn, err := db.pool.Open()
+	if err != nil {
+		logger.Error("[Model.Viewer] Failed to open a connection", err)
+		return nil, ErrDbInternal
+	}
+	defer conn.Close()
+
+	qs := ` SELECT
+			  p."id",
+			  $2 = ANY(p."likes"),
+			  $2 = ANY(p."shares"),
+			  EXISTS (
+			    SELECT 1 FROM bookmarks AS b
+			    WHERE b."user" = $2 AND b."post" = p."id"
+			  )
+			FROM posts AS p
+			WHERE p."id" = ANY($1);`
+	r, e := conn.Query(qs, pq.Array(ids), user)
+	if e != nil {
+		logger.Error("[Model.Viewer] Cannot query", e)
+		return nil, ErrDbInternal
+	}
+	defer r.Close()
+	for r.Next() {
+		var id string
+		s := ViewerState{}
+		if e := r.Scan(&id, &s.Liked, &s.Shared, &s.Bookmarked); e != nil {
+			logger.Error("[Model.Viewer] Cannot scan row", e)
+			continue
+		}
+		states[id] = &s
+	}
+	return states, nil
+}
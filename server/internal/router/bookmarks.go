@@ -0,0 +1,120 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+func routeBookmarks(router fiber.Router) {
+	router.Get("/", mAuth, getBookmarks)
+}
+
+func getBookmarks(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := postService.GetBookmarks(username, page)
+	if err != nil {
+		switch err {
+		case posts.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("User not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[BOOKMARKS]GET: bookmarks of %s", username)
+	logger.Info(msg)
+	setLinks(c, links)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func bookmarkPost(c *fiber.Ctx) error {
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := postService.Bookmark(username, postID); err != nil {
+		switch err {
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[BOOKMARKS]PUT: %s bookmarks %s", username, postID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func unbookmarkPost(c *fiber.Ctx) error {
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := postService.Unbookmark(username, postID); err != nil {
+		switch err {
+		case posts.ErrBookmarkNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Bookmark not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[BOOKMARKS]DELETE: %s removes bookmark of %s", username, postID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,121 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/conversations"
+)
+
+func routeConversations(router fiber.Router) {
+	router.Get("/", mAuth, getConversations)
+	router.Get("/:id", mAuth, getConversation)
+	router.Post("/:id/read", mAuth, readConversation)
+}
+
+func getConversations(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var conversationService *conversations.ConversationService
+	err := services.Get(reflect.ValueOf(&conversationService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := conversationService.GetList(username, page)
+	if err != nil {
+		switch err {
+		case conversations.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("User not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[CONVERSATIONS]GET: conversations of %s", username)
+	logger.Info(msg)
+	setLinks(c, links)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func getConversation(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	id := c.Params("id")
+
+	var conversationService *conversations.ConversationService
+	err := services.Get(reflect.ValueOf(&conversationService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := conversationService.Get(username, id, page)
+	if err != nil {
+		switch err {
+		case conversations.ErrConversationNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Conversation not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[CONVERSATIONS]GET: conversation %s of %s", id, username)
+	logger.Info(msg)
+	setLinks(c, links)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func readConversation(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	id := c.Params("id")
+
+	var conversationService *conversations.ConversationService
+	err := services.Get(reflect.ValueOf(&conversationService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := conversationService.MarkRead(username, id); err != nil {
+		switch err {
+		case conversations.ErrConversationNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Conversation not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[CONVERSATIONS]READ: %s reads conversation %s", username, id)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/emojis"
+)
+
+func routeEmojis(router fiber.Router) {
+	router.Get("/", getCustomEmojis)
+	router.Put("/", mAuth, uploadCustomEmoji)
+	router.Delete("/:shortcode", mAuth, removeCustomEmoji)
+}
+
+func getCustomEmojis(c *fiber.Ctx) error {
+	var emojiService *emojis.EmojiService
+	err := services.Get(reflect.ValueOf(&emojiService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := emojiService.List()
+	if err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	logger := logging.Get()
+	logger.Info("[EMOJIS]GET: request for custom emoji")
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func uploadCustomEmoji(c *fiber.Ctx) error {
+	c.Accepts("multipart/form-data")
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	shortcode := c.FormValue("shortcode")
+	category := c.FormValue("category")
+	fh, e := c.FormFile("image")
+	if e != nil {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire image.")
+	}
+	f, e := fh.Open()
+	if e != nil {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Cannot read image.")
+	}
+	defer f.Close()
+	b, e := io.ReadAll(f)
+	if e != nil {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Cannot read image.")
+	}
+
+	var emojiService *emojis.EmojiService
+	err := services.Get(reflect.ValueOf(&emojiService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	emoji, err := emojiService.Upload(username, shortcode, category, b)
+	if err != nil {
+		switch err {
+		case emojis.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case emojis.ErrShortcode:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid shortcode: 2 to 30 letters, digits or underscores.")
+		case emojis.ErrImage:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid image: jpeg or png, max 256KB.")
+		case emojis.ErrEmojiExist:
+			c.Status(fiber.StatusConflict)
+			return c.SendString("Shortcode already used.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[EMOJIS]UPLOAD: %s uploads :%s:", username, emoji.Shortcode)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(emoji)
+}
+
+func removeCustomEmoji(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	shortcode := c.Params("shortcode")
+
+	var emojiService *emojis.EmojiService
+	err := services.Get(reflect.ValueOf(&emojiService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := emojiService.Remove(username, shortcode); err != nil {
+		switch err {
+		case emojis.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case emojis.ErrEmojiNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Custom emoji not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[EMOJIS]REMOVE: %s removes :%s:", username, shortcode)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
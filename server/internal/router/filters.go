@@ -0,0 +1,173 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/filters"
+)
+
+func routeFilters(router fiber.Router) {
+	router.Get("/", mAuth, getFilters)
+	router.Post("/", mAuth, createFilter)
+	router.Get("/:filterID", mAuth, getFilter)
+	router.Put("/:filterID", mAuth, updateFilter)
+	router.Delete("/:filterID", mAuth, removeFilter)
+}
+
+func filterErr(c *fiber.Ctx, err error) error {
+	switch err {
+	case filters.ErrUserNotFound:
+		c.Status(fiber.StatusNotFound)
+		return c.SendString("User not found.")
+	case filters.ErrFilterNotFound:
+		c.Status(fiber.StatusNotFound)
+		return c.SendString("Filter not found.")
+	case filters.ErrPhrase:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid phrase: 1 to 100 characters.")
+	case filters.ErrContext:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid context: home, notifications, public, thread or profile.")
+	case filters.ErrAction:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid action: hide or warn.")
+	case filters.ErrExpiry:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid expiry: expiresIn.")
+	default:
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
+
+func getFilters(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var filterService *filters.FilterService
+	err := services.Get(reflect.ValueOf(&filterService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := filterService.GetFilters(username)
+	if err != nil {
+		return filterErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[FILTERS]GET: filters of %s", username)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func createFilter(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	body := new(filters.FilterBody)
+	if err := c.BodyParser(body); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var filterService *filters.FilterService
+	err := services.Get(reflect.ValueOf(&filterService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	filter, err := filterService.Create(username, body)
+	if err != nil {
+		return filterErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[FILTERS]CREATE: %s creates filter %s", username, filter.ID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(filter)
+}
+
+func getFilter(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	filterID := c.Params("filterID")
+
+	var filterService *filters.FilterService
+	err := services.Get(reflect.ValueOf(&filterService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	filter, err := filterService.Get(username, filterID)
+	if err != nil {
+		return filterErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[FILTERS]GET: filter %s", filterID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(filter)
+}
+
+func updateFilter(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	filterID := c.Params("filterID")
+	body := new(filters.FilterBody)
+	if err := c.BodyParser(body); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var filterService *filters.FilterService
+	err := services.Get(reflect.ValueOf(&filterService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	filter, err := filterService.Update(username, filterID, body)
+	if err != nil {
+		return filterErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[FILTERS]UPDATE: %s updates filter %s", username, filterID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(filter)
+}
+
+func removeFilter(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	filterID := c.Params("filterID")
+
+	var filterService *filters.FilterService
+	err := services.Get(reflect.ValueOf(&filterService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := filterService.Remove(username, filterID); err != nil {
+		return filterErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[FILTERS]REMOVE: %s removes filter %s", username, filterID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
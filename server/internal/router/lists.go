@@ -0,0 +1,299 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/lists"
+)
+
+func routeLists(router fiber.Router) {
+	router.Get("/", mAuth, getLists)
+	router.Post("/", mAuth, createList)
+	router.Get("/:listID", mAuth, getList)
+	router.Put("/:listID", mAuth, updateList)
+	router.Delete("/:listID", mAuth, removeList)
+	router.Get("/:listID/members", mAuth, getListMembers)
+	router.Put("/:listID/members", mAuth, addListMembers)
+	router.Delete("/:listID/members", mAuth, removeListMembers)
+	router.Get("/:listID/timeline", mAuth, getListTimeline)
+}
+
+type listBody struct {
+	Title         string `json:"title"`
+	RepliesPolicy string `json:"repliesPolicy"`
+}
+
+type membersBody struct {
+	Users []string `json:"users"`
+}
+
+func listErr(c *fiber.Ctx, err error) error {
+	switch err {
+	case lists.ErrUserNotFound:
+		c.Status(fiber.StatusNotFound)
+		return c.SendString("User not found.")
+	case lists.ErrListNotFound:
+		c.Status(fiber.StatusNotFound)
+		return c.SendString("List not found.")
+	case lists.ErrTitle:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid title: 1 to 100 characters.")
+	case lists.ErrRepliesPolicy:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid replies policy: followed, list or none.")
+	case lists.ErrNotFollowing:
+		c.Status(fiber.StatusUnprocessableEntity)
+		return c.SendString("Only users followed can be added.")
+	case lists.ErrCursor:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid cursor: from.")
+	default:
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
+
+func getLists(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := listService.GetLists(username)
+	if err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]GET: lists of %s", username)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func createList(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	body := new(listBody)
+	if err := c.BodyParser(body); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := listService.Create(username, body.Title, body.RepliesPolicy)
+	if err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]CREATE: %s creates list %s", username, list.ID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func getList(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	listID := c.Params("listID")
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := listService.Get(username, listID)
+	if err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]GET: list %s", listID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func updateList(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	listID := c.Params("listID")
+	body := new(listBody)
+	if err := c.BodyParser(body); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := listService.Update(username, listID, body.Title, body.RepliesPolicy)
+	if err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]UPDATE: %s updates list %s", username, listID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func removeList(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	listID := c.Params("listID")
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := listService.Remove(username, listID); err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]REMOVE: %s removes list %s", username, listID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func getListMembers(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	listID := c.Params("listID")
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, links, err := listService.GetMembers(username, listID, page)
+	if err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]GET: members of list %s", listID)
+	logger.Info(msg)
+	setLinks(c, links)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func addListMembers(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	listID := c.Params("listID")
+	body := new(membersBody)
+	if err := c.BodyParser(body); err != nil || len(body.Users) == 0 {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire users.")
+	}
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := listService.AddMembers(username, listID, body.Users); err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]ADD: %s adds %d users to list %s", username, len(body.Users), listID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func removeListMembers(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	listID := c.Params("listID")
+	body := new(membersBody)
+	if err := c.BodyParser(body); err != nil || len(body.Users) == 0 {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire users.")
+	}
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := listService.RemoveMembers(username, listID, body.Users); err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]REMOVE: %s removes %d users from list %s", username, len(body.Users), listID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func getListTimeline(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	listID := c.Params("listID")
+	from := c.Query("from")
+
+	var listService *lists.ListService
+	err := services.Get(reflect.ValueOf(&listService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	tl, err := listService.GetTimeline(username, listID, from)
+	if err != nil {
+		return listErr(c, err)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[LISTS]GET: timeline of list %s", listID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(tl)
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/notifications"
+)
+
+func routeNotifications(router fiber.Router) {
+	router.Get("/", mAuth, getNotifications)
+	router.Post("/read", mAuth, readNotifications)
+	router.Delete("/", mAuth, clearNotifications)
+}
+
+func getNotifications(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	from := c.Query("from")
+	types := c.Query("types")
+
+	var notificationService *notifications.NotificationService
+	err := services.Get(reflect.ValueOf(&notificationService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	page, err := notificationService.Get(username, from, types)
+	if err != nil {
+		switch err {
+		case notifications.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("User not found.")
+		case notifications.ErrType:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid notification type.")
+		case notifications.ErrCursor:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid cursor: from.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[NOTIFICATIONS]GET: notifications of %s", username)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(page)
+}
+
+type readBody struct {
+	ID string `json:"id"`
+}
+
+func readNotifications(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	body := new(readBody)
+	if err := c.BodyParser(body); err != nil || body.ID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire notification id.")
+	}
+
+	var notificationService *notifications.NotificationService
+	err := services.Get(reflect.ValueOf(&notificationService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := notificationService.MarkRead(username, body.ID); err != nil {
+		switch err {
+		case notifications.ErrCursor:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid notification id.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[NOTIFICATIONS]READ: %s reads up to %s", username, body.ID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func clearNotifications(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var notificationService *notifications.NotificationService
+	err := services.Get(reflect.ValueOf(&notificationService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := notificationService.Clear(username); err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[NOTIFICATIONS]CLEAR: notifications of %s", username)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
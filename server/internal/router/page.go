@@ -0,0 +1,43 @@
+package router
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// page of a list from queries: max_id, since_id, min_id and limit
+func queryPage(c *fiber.Ctx) (page utils.Page, ok bool) {
+	return utils.ParsePage(
+		c.Query("max_id"), c.Query("since_id"), c.Query("min_id"),
+		c.QueryInt("limit", 0),
+	)
+}
+
+// set Link header to the next and previous pages, keeping other queries
+func setLinks(c *fiber.Ctx, links utils.PageLinks) {
+	link := func(key string, cursor utils.Cursor, rel string) string {
+		q := url.Values{}
+		for k, v := range c.Queries() {
+			switch k {
+			case "max_id", "since_id", "min_id":
+				continue
+			}
+			q.Set(k, v)
+		}
+		q.Set(key, cursor.String())
+		return "<" + c.BaseURL() + c.Path() + "?" + q.Encode() + `>; rel="` + rel + `"`
+	}
+	ls := make([]string, 0, 2)
+	if !links.Next.IsZero() {
+		ls = append(ls, link("max_id", links.Next, "next"))
+	}
+	if !links.Prev.IsZero() {
+		ls = append(ls, link("min_id", links.Prev, "prev"))
+	}
+	if len(ls) != 0 {
+		c.Set(fiber.HeaderLink, strings.Join(ls, ", "))
+	}
+}
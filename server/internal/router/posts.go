@@ -8,6 +8,7 @@ import (
 	"github.com/kidommoc/gustrody/internal/logging"
 	"github.com/kidommoc/gustrody/internal/services"
 	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/utils"
 )
 
 func routePosts(router fiber.Router) {
@@ -15,6 +16,18 @@ func routePosts(router fiber.Router) {
 		c.Locals("forced", false)
 		return c.Next()
 	}, mAuth, getPost)
+	router.Get("/:postID/context", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getPostContext)
+	router.Get("/:postID/history", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getPostHistory)
+	router.Get("/:postID/source", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getPostSource)
 	router.Get("/:postID/likes", func(c *fiber.Ctx) error {
 		c.Locals("forced", false)
 		return c.Next()
@@ -32,6 +45,11 @@ func routePosts(router fiber.Router) {
 	router.Put("/:postID/share", mAuth, sharePost)
 	router.Delete("/:postID/share", mAuth, unsharePost)
 	router.Put("/:postID/reply", mAuth, replyPost)
+	router.Put("/:postID/vote", mAuth, votePost)
+	router.Put("/:postID/bookmark", mAuth, bookmarkPost)
+	router.Delete("/:postID/bookmark", mAuth, unbookmarkPost)
+	router.Put("/:postID/reactions/:emoji", mAuth, reactPost)
+	router.Delete("/:postID/reactions/:emoji", mAuth, unreactPost)
 }
 
 func getPost(c *fiber.Ctx) error {
@@ -74,6 +92,128 @@ func getPost(c *fiber.Ctx) error {
 	return c.JSON(post)
 }
 
+// descendants are paged with maxDescendants as the limit
+func getPostContext(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	page, ok := utils.ParsePage(
+		c.Query("max_id"), c.Query("since_id"), c.Query("min_id"),
+		c.QueryInt("maxDescendants", 0),
+	)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	ctx, links, err := postService.GetContext(username, postID, c.QueryInt("maxDepth", 0), page)
+	if err != nil {
+		switch err {
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[POSTS]GET: request for context of %s", postID)
+	logger.Info(msg)
+	setLinks(c, links)
+	c.Status(fiber.StatusOK)
+	return c.JSON(ctx)
+}
+
+func getPostHistory(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := postService.GetHistory(username, postID)
+	if err != nil {
+		switch err {
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[POSTS]GET: request for history of %s", postID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func getPostSource(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	source, err := postService.GetSource(username, postID)
+	if err != nil {
+		switch err {
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[POSTS]GET: request for source of %s", postID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(source)
+}
+
 func getPostLikes(c *fiber.Ctx) error {
 	username, ok := c.Locals("username").(string)
 	if !ok {
@@ -91,7 +231,13 @@ func getPostLikes(c *fiber.Ctx) error {
 		// ?
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
-	list, err := postService.GetLikes(username, postID)
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := postService.GetLikes(username, postID, page)
 	if err != nil {
 		switch err {
 		case posts.ErrNotPermitted:
@@ -107,6 +253,7 @@ func getPostLikes(c *fiber.Ctx) error {
 	logger := logging.Get()
 	msg := fmt.Sprintf("[POSTS]GET: request for likes of %s", postID)
 	logger.Info(msg)
+	setLinks(c, links)
 	c.Status(fiber.StatusOK)
 	return c.JSON(list)
 }
@@ -128,7 +275,13 @@ func getPostShares(c *fiber.Ctx) error {
 		// ?
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
-	list, err := postService.GetShares(username, postID)
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := postService.GetShares(username, postID, page)
 	if err != nil {
 		switch err {
 		case posts.ErrNotPermitted:
@@ -144,6 +297,7 @@ func getPostShares(c *fiber.Ctx) error {
 	logger := logging.Get()
 	msg := fmt.Sprintf("[POSTS]GET: request for shares of %s", postID)
 	logger.Info(msg)
+	setLinks(c, links)
 	c.Status(fiber.StatusOK)
 	return c.JSON(list)
 }
@@ -152,6 +306,22 @@ type contentBody struct {
 	Content     string            `json:"content"`
 	Vsb         string            `json:"vsb"`
 	Attachments []posts.AttachImg `json:"attachments,omitempty"`
+	Recipients  []string          `json:"recipients,omitempty"` // when direct
+	Spoiler     *string           `json:"spoiler,omitempty"`
+	Sensitive   *bool             `json:"sensitive,omitempty"`
+	Poll        *posts.NewPoll    `json:"poll,omitempty"`        // only when posting
+	ScheduledAt string            `json:"scheduledAt,omitempty"` // rfc3339, only when posting
+	Quoting     string            `json:"quoting,omitempty"`     // post id, only when posting
+	Quotable    *bool             `json:"quotable,omitempty"`
+	ReplyPolicy string            `json:"replyPolicy,omitempty"` // everyone, followers, mentioned or nobody
+}
+
+func (body *contentBody) options() posts.Options {
+	return posts.Options{
+		Spoiler: body.Spoiler, Sensitive: body.Sensitive, Poll: body.Poll,
+		Quoting: body.Quoting, Quotable: body.Quotable,
+		ReplyPolicy: body.ReplyPolicy,
+	}
 }
 
 func newPost(c *fiber.Ctx) error {
@@ -171,8 +341,11 @@ func newPost(c *fiber.Ctx) error {
 		// ?
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
+	if body.ScheduledAt != "" {
+		return schedulePost(c, postService, username, body)
+	}
 	if err := postService.New(
-		username, body.Vsb, body.Content, body.Attachments,
+		username, body.Vsb, body.Content, body.Attachments, body.Recipients, body.options(),
 	); err != nil {
 		switch err {
 		case posts.ErrUserNotFound:
@@ -184,6 +357,23 @@ func newPost(c *fiber.Ctx) error {
 		case posts.ErrContentTooLong:
 			c.Status(fiber.StatusBadRequest)
 			return c.SendString("Content is too long to post.")
+		case posts.ErrNoRecipient:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Acquire recipients of direct post.")
+		case posts.ErrPoll:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid poll.")
+		case posts.ErrReplyPolicy:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid reply policy.")
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post quoted not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrNotQuotable:
+			c.Status(fiber.StatusForbidden)
+			return c.SendString("Post cannot be quoted.")
 		default:
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
@@ -218,7 +408,7 @@ func replyPost(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
 	if err := postService.Reply(
-		username, postID, content.Vsb, content.Content, content.Attachments,
+		username, postID, content.Vsb, content.Content, content.Attachments, content.Recipients, content.options(),
 	); err != nil {
 		switch err {
 		case posts.ErrUserNotFound:
@@ -227,12 +417,26 @@ func replyPost(c *fiber.Ctx) error {
 		case posts.ErrPostNotFound:
 			c.Status(fiber.StatusNotFound)
 			return c.SendString("Post not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrReplyNotAllowed:
+			c.Status(fiber.StatusForbidden)
+			return c.SendString("Replies are limited by the author.")
+		case posts.ErrReplyPolicy:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid reply policy.")
 		case posts.ErrContentEmpty:
 			c.Status(fiber.StatusBadRequest)
 			return c.SendString("Acquire content to post.")
 		case posts.ErrContentTooLong:
 			c.Status(fiber.StatusBadRequest)
 			return c.SendString("Content is too long to post.")
+		case posts.ErrNoRecipient:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Acquire recipients of direct post.")
+		case posts.ErrNotQuotable:
+			c.Status(fiber.StatusForbidden)
+			return c.SendString("Post cannot be quoted.")
 		default:
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
@@ -267,7 +471,7 @@ func editPost(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
 	if err := postService.Edit(
-		username, postID, content.Content, content.Attachments,
+		username, postID, content.Content, content.Attachments, content.options(),
 	); err != nil {
 		switch err {
 		case posts.ErrOwner:
@@ -282,6 +486,9 @@ func editPost(c *fiber.Ctx) error {
 		case posts.ErrContentTooLong:
 			c.Status(fiber.StatusBadRequest)
 			return c.SendString("Content is too long to post.")
+		case posts.ErrReplyPolicy:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid reply policy.")
 		default:
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
@@ -351,6 +558,8 @@ func likePost(c *fiber.Ctx) error {
 		case posts.ErrPostNotFound:
 			c.Status(fiber.StatusNotFound)
 			return c.SendString("Post not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
 		default:
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
@@ -421,6 +630,8 @@ func sharePost(c *fiber.Ctx) error {
 		case posts.ErrPostNotFound:
 			c.Status(fiber.StatusNotFound)
 			return c.SendString("Post not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
 		default:
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
@@ -467,3 +678,61 @@ func unsharePost(c *fiber.Ctx) error {
 	logger.Info(msg)
 	return c.SendStatus(fiber.StatusOK)
 }
+
+type voteBody struct {
+	Choices []int64 `json:"choices"` // indexes of options
+}
+
+func votePost(c *fiber.Ctx) error {
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	body := new(voteBody)
+	if err := c.BodyParser(body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Wrong request body.")
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	poll, err := postService.Vote(username, postID, body.Choices)
+	if err != nil {
+		switch err {
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post not found.")
+		case posts.ErrPollNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Poll not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrPollExpired:
+			c.Status(fiber.StatusUnprocessableEntity)
+			return c.SendString("Poll has expired.")
+		case posts.ErrVoted:
+			c.Status(fiber.StatusUnprocessableEntity)
+			return c.SendString("Already voted.")
+		case posts.ErrChoice:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid choices.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[POSTS]VOTE: %s votes in %s", username, postID)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(poll)
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+// emoji in path is percent-encoded
+func paramEmoji(c *fiber.Ctx) (emoji string, ok bool) {
+	emoji, e := url.PathUnescape(c.Params("emoji"))
+	return emoji, e == nil && emoji != ""
+}
+
+func reactPost(c *fiber.Ctx) error {
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+	emoji, ok := paramEmoji(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire emoji.")
+	}
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := postService.React(username, postID, emoji); err != nil {
+		switch err {
+		case posts.ErrEmoji:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid emoji.")
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[POSTS]REACT: %s reacts to %s with %s", username, postID, emoji)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func unreactPost(c *fiber.Ctx) error {
+	postID := c.Params("postID")
+	if postID == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire post id.")
+	}
+	emoji, ok := paramEmoji(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire emoji.")
+	}
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := postService.Unreact(username, postID, emoji); err != nil {
+		switch err {
+		case posts.ErrEmoji:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid emoji.")
+		case posts.ErrReactionNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Reaction not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[POSTS]UNREACT: %s removes reaction %s to %s", username, emoji, postID)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -19,6 +19,18 @@ func Route(app *fiber.App) {
 	routeAuth(app.Group("/auth"))
 	routeUsers(app.Group("/users"))
 	routePosts(app.Group("/posts"))
+	routeScheduled(app.Group("/scheduled"))
+	routeTimelines(app.Group("/"))
+	routeLists(app.Group("/lists"))
+	routeFilters(app.Group("/filters"))
+	routeTags(app.Group("/tags"))
+	routeSearch(app.Group("/search"))
+	routeTrends(app.Group("/trends"))
+	routeBookmarks(app.Group("/bookmarks"))
+	routeEmojis(app.Group("/custom_emojis"))
+	routeNotifications(app.Group("/notification"))
+	routeConversations(app.Group("/conversations"))
+	routeStreaming(app.Group("/streaming"))
 	app.Use("/", func(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusNotFound)
 	})
@@ -0,0 +1,199 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+func routeScheduled(router fiber.Router) {
+	router.Get("/", mAuth, getScheduled)
+	router.Post("/:id", mAuth, editScheduled)
+	router.Delete("/:id", mAuth, cancelScheduled)
+}
+
+// PUT /posts with scheduledAt
+func schedulePost(c *fiber.Ctx, postService *posts.PostService, username string, body *contentBody) error {
+	at, err := time.Parse(time.RFC3339, body.ScheduledAt)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid scheduledAt.")
+	}
+	scheduled, err := postService.Schedule(
+		username, body.Vsb, body.Content, body.Attachments, body.Recipients, body.options(), at,
+	)
+	if err != nil {
+		switch err {
+		case posts.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Actor(User) not found.")
+		case posts.ErrContentEmpty:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Acquire content to post.")
+		case posts.ErrContentTooLong:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Content is too long to post.")
+		case posts.ErrPoll:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid poll.")
+		case posts.ErrReplyPolicy:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid reply policy.")
+		case posts.ErrSchedule:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Schedule at least 5 minutes later.")
+		case posts.ErrNoRecipient:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Acquire recipients of direct post.")
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post quoted not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrNotQuotable:
+			c.Status(fiber.StatusForbidden)
+			return c.SendString("Post cannot be quoted.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[POSTS]SCHEDULE: %s schedules a post at %s", username, body.ScheduledAt)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(scheduled)
+}
+
+func getScheduled(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := postService.GetScheduled(username)
+	if err != nil {
+		switch err {
+		case posts.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("User not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[SCHEDULED]GET: scheduled posts of %s", username)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func editScheduled(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	id := c.Params("id")
+	body := new(contentBody)
+	if err := c.BodyParser(body); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Wrong request body.")
+	}
+	at, err := time.Parse(time.RFC3339, body.ScheduledAt)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid scheduledAt.")
+	}
+
+	var postService *posts.PostService
+	err = services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	scheduled, err := postService.EditScheduled(
+		username, id, body.Vsb, body.Content, body.Attachments, body.Recipients, body.options(), at,
+	)
+	if err != nil {
+		switch err {
+		case posts.ErrScheduledNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Scheduled post not found.")
+		case posts.ErrContentEmpty:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Acquire content to post.")
+		case posts.ErrContentTooLong:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Content is too long to post.")
+		case posts.ErrPoll:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid poll.")
+		case posts.ErrReplyPolicy:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid reply policy.")
+		case posts.ErrSchedule:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Schedule at least 5 minutes later.")
+		case posts.ErrNoRecipient:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Acquire recipients of direct post.")
+		case posts.ErrPostNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Post quoted not found.")
+		case posts.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case posts.ErrNotQuotable:
+			c.Status(fiber.StatusForbidden)
+			return c.SendString("Post cannot be quoted.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[SCHEDULED]EDIT: %s edits scheduled post %s", username, id)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(scheduled)
+}
+
+func cancelScheduled(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	id := c.Params("id")
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := postService.CancelScheduled(username, id); err != nil {
+		switch err {
+		case posts.ErrScheduledNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Scheduled post not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[SCHEDULED]CANCEL: %s cancels scheduled post %s", username, id)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
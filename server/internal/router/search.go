@@ -0,0 +1,103 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+func routeSearch(router fiber.Router) {
+	router.Get("/", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, search)
+}
+
+type searchResult struct {
+	Users []*users.UserInfo `json:"users,omitempty"`
+	Posts []*posts.Post     `json:"posts,omitempty"`
+	Tags  []*posts.Tag      `json:"tags,omitempty"`
+}
+
+func searchErr(c *fiber.Ctx, err error) error {
+	switch err {
+	case users.ErrQuery, posts.ErrQuery:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid query: 1 to 200 characters.")
+	default:
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
+
+// only one type is paged. without type, the first page of each is responded
+func search(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	q := c.Query("q")
+	typ := c.Query("type")
+	switch typ {
+	case "", "users", "posts", "tags":
+	default:
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid type: users, posts or tags.")
+	}
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+	if typ == "" {
+		page = utils.Page{Limit: page.Limit}
+	}
+
+	var userService *users.UserService
+	err := services.Get(reflect.ValueOf(&userService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	var postService *posts.PostService
+	err = services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	result := searchResult{}
+	var links utils.PageLinks
+	if typ == "" || typ == "users" {
+		result.Users, links, err = userService.Search(q, page)
+		if err != nil {
+			return searchErr(c, err)
+		}
+	}
+	if typ == "" || typ == "posts" {
+		result.Posts, links, err = postService.Search(username, q, page)
+		if err != nil {
+			return searchErr(c, err)
+		}
+	}
+	if typ == "" || typ == "tags" {
+		result.Tags, links, err = postService.SearchTags(q, page)
+		if err != nil {
+			return searchErr(c, err)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[SEARCH]GET: search %s of \"%s\"", typ, q)
+	logger.Info(msg)
+	if typ != "" {
+		setLinks(c, links)
+	}
+	c.Status(fiber.StatusOK)
+	return c.JSON(result)
+}
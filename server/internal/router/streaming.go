@@ -0,0 +1,168 @@
+package router
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/streaming"
+)
+
+func routeStreaming(router fiber.Router) {
+	router.Get("/ws", mAuth, func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+		return c.Next()
+	}, websocket.New(streamWebSocket))
+	router.Get("/", mAuth, streamSSE)
+}
+
+// server-sent events. one stream per connection
+func streamSSE(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	stream := c.Query("stream")
+	param, ok := streamParam(stream, c.Query("tag"), c.Query("list"))
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid stream.")
+	}
+
+	var streamingService *streaming.StreamingService
+	err := services.Get(reflect.ValueOf(&streamingService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	sub := streamingService.Subscribe(username)
+	if err := sub.Add(stream, param); err != nil {
+		streamingService.Unsubscribe(sub)
+		switch err {
+		case streaming.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		default:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid stream.")
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[STREAMING]GET: %s of %s", stream, username)
+	logger.Info(msg)
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		send := func(e *streaming.Event) error {
+			b, err := json.Marshal(e.Payload)
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Event, b)
+			return w.Flush()
+		}
+		beat := func() error {
+			fmt.Fprint(w, ":thump\n\n")
+			return w.Flush()
+		}
+		if err := beat(); err != nil {
+			streamingService.Unsubscribe(sub)
+			return
+		}
+		streamingService.Serve(sub, send, beat)
+	})
+	return nil
+}
+
+// the parameter of the stream: tag of hashtag, list of list, and none of others.
+// not ok when the other is given
+func streamParam(stream, tag, list string) (param string, ok bool) {
+	switch stream {
+	case "hashtag":
+		return tag, list == ""
+	case "list":
+		return list, tag == ""
+	default:
+		return "", tag == "" && list == ""
+	}
+}
+
+// a request from WebSocket clients
+type streamRequest struct {
+	Type   string `json:"type"` // "subscribe" or "unsubscribe"
+	Stream string `json:"stream"`
+	Tag    string `json:"tag"`
+	List   string `json:"list"`
+}
+
+// WebSocket. streams are subscribed and unsubscribed by messages from the client
+func streamWebSocket(conn *websocket.Conn) {
+	username, _ := conn.Locals("username").(string)
+	var streamingService *streaming.StreamingService
+	err := services.Get(reflect.ValueOf(&streamingService).Elem())
+	if err != nil {
+		conn.Close()
+		return
+	}
+	sub := streamingService.Subscribe(username)
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[STREAMING]WS: connected by %s", username)
+	logger.Info(msg)
+
+	// only Serve writes to the connection. reading stops when the connection is closed
+	go func() {
+		defer streamingService.Unsubscribe(sub)
+		for {
+			req := streamRequest{}
+			if err := conn.ReadJSON(&req); err != nil {
+				if _, ok := err.(*json.SyntaxError); ok {
+					sub.Notice("Invalid request.")
+					continue
+				}
+				return
+			}
+			param, ok := streamParam(req.Stream, req.Tag, req.List)
+			if !ok {
+				sub.Notice("Invalid stream: " + req.Stream)
+				continue
+			}
+			var err error
+			switch req.Type {
+			case "subscribe":
+				err = sub.Add(req.Stream, param)
+			case "unsubscribe":
+				err = sub.Remove(req.Stream, param)
+			default:
+				sub.Notice("Invalid request type.")
+				continue
+			}
+			switch err {
+			case nil:
+			case streaming.ErrNotPermitted:
+				sub.Notice("Not permitted stream: " + req.Stream)
+			default:
+				sub.Notice("Invalid stream: " + req.Stream)
+			}
+		}
+	}()
+
+	send := func(e *streaming.Event) error {
+		return conn.WriteJSON(e)
+	}
+	beat := func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streaming.HEARTBEAT))
+	}
+	streamingService.Serve(sub, send, beat)
+	conn.Close()
+}
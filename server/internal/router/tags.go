@@ -0,0 +1,122 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+func routeTags(router fiber.Router) {
+	router.Get("/:tag", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getTagTimeline)
+	router.Put("/:tag/follow", mAuth, followTag)
+	router.Delete("/:tag/follow", mAuth, unfollowTag)
+}
+
+func getTagTimeline(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	tag := c.Params("tag")
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := postService.GetByTag(username, tag, page)
+	if err != nil {
+		switch err {
+		case posts.ErrTag:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid hashtag.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TAGS]GET: timeline of #%s", tag)
+	logger.Info(msg)
+	setLinks(c, links)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func followTag(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	tag := c.Params("tag")
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := postService.FollowTag(username, tag); err != nil {
+		switch err {
+		case posts.ErrTag:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid hashtag.")
+		case posts.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("User not found.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TAGS]FOLLOW: %s follows #%s", username, tag)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func unfollowTag(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	tag := c.Params("tag")
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := postService.UnfollowTag(username, tag); err != nil {
+		switch err {
+		case posts.ErrTag:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid hashtag.")
+		case posts.ErrTagFollowNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("Not following the hashtag.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TAGS]UNFOLLOW: %s unfollows #%s", username, tag)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
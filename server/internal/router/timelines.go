@@ -0,0 +1,85 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+func routeTimelines(router fiber.Router) {
+	router.Get("/home", mAuth, getHomeTimeline)
+	router.Get("/public", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getPublicTimeline)
+}
+
+func getHomeTimeline(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	from := c.Query("from")
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	tl, err := postService.GetHome(username, from)
+	if err != nil {
+		switch err {
+		case posts.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString("User not found.")
+		case posts.ErrCursor:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid cursor: from.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TIMELINES]GET: home of %s", username)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(tl)
+}
+
+func getPublicTimeline(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	from := c.Query("from")
+	local := c.QueryBool("local", false)
+
+	var postService *posts.PostService
+	err := services.Get(reflect.ValueOf(&postService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	tl, err := postService.GetPublic(username, from, local)
+	if err != nil {
+		switch err {
+		case posts.ErrCursor:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid cursor: from.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TIMELINES]GET: public for %s", username)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(tl)
+}
@@ -0,0 +1,184 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services"
+	"github.com/kidommoc/gustrody/internal/services/trends"
+)
+
+func routeTrends(router fiber.Router) {
+	router.Get("/tags", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getTrendTags)
+	router.Get("/posts", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getTrendPosts)
+	router.Get("/links", func(c *fiber.Ctx) error {
+		c.Locals("forced", false)
+		return c.Next()
+	}, mAuth, getTrendLinks)
+	router.Put("/tags/:tag", mAuth, approveTrendTag)
+	router.Delete("/tags/:tag", mAuth, hideTrendTag)
+	router.Put("/posts/:postID", mAuth, approveTrendPost)
+	router.Delete("/posts/:postID", mAuth, hideTrendPost)
+	// ?url= for the url reviewed
+	router.Put("/links", mAuth, approveTrendLink)
+	router.Delete("/links", mAuth, hideTrendLink)
+}
+
+// ?pending=true for trends not reviewed yet, only for admins
+func getTrendTags(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	pending := c.QueryBool("pending", false)
+
+	var trendService *trends.TrendService
+	err := services.Get(reflect.ValueOf(&trendService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := trendService.GetTags(username, pending)
+	if err != nil {
+		switch err {
+		case trends.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TRENDS]GET: trending hashtags, pending: %t", pending)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+// ?pending=true for trends not reviewed yet, only for admins
+func getTrendPosts(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	pending := c.QueryBool("pending", false)
+
+	var trendService *trends.TrendService
+	err := services.Get(reflect.ValueOf(&trendService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := trendService.GetPosts(username, pending)
+	if err != nil {
+		switch err {
+		case trends.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TRENDS]GET: trending posts, pending: %t", pending)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+// ?pending=true for trends not reviewed yet, only for admins
+func getTrendLinks(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		username = ""
+	}
+	pending := c.QueryBool("pending", false)
+
+	var trendService *trends.TrendService
+	err := services.Get(reflect.ValueOf(&trendService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	list, err := trendService.GetLinks(username, pending)
+	if err != nil {
+		switch err {
+		case trends.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TRENDS]GET: trending links, pending: %t", pending)
+	logger.Info(msg)
+	c.Status(fiber.StatusOK)
+	return c.JSON(list)
+}
+
+func approveTrendTag(c *fiber.Ctx) error {
+	return reviewTrend(c, models.Trd_TAG, c.Params("tag"), true)
+}
+
+func hideTrendTag(c *fiber.Ctx) error {
+	return reviewTrend(c, models.Trd_TAG, c.Params("tag"), false)
+}
+
+func approveTrendPost(c *fiber.Ctx) error {
+	return reviewTrend(c, models.Trd_POST, c.Params("postID"), true)
+}
+
+func hideTrendPost(c *fiber.Ctx) error {
+	return reviewTrend(c, models.Trd_POST, c.Params("postID"), false)
+}
+
+func approveTrendLink(c *fiber.Ctx) error {
+	return reviewTrend(c, models.Trd_LINK, c.Query("url"), true)
+}
+
+func hideTrendLink(c *fiber.Ctx) error {
+	return reviewTrend(c, models.Trd_LINK, c.Query("url"), false)
+}
+
+func reviewTrend(c *fiber.Ctx, kind models.TrendKind, key string, approved bool) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var trendService *trends.TrendService
+	err := services.Get(reflect.ValueOf(&trendService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := trendService.Review(username, kind, key, approved); err != nil {
+		switch err {
+		case trends.ErrNotPermitted:
+			return c.SendStatus(fiber.StatusForbidden)
+		case trends.ErrTag:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid hashtag.")
+		case trends.ErrLink:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Invalid link.")
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[TRENDS]REVIEW: %s reviews %s %s, approved: %t", username, kind, key, approved)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
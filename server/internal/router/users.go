@@ -28,6 +28,8 @@ func routeUsers(router fiber.Router) {
 	router.Get("/:username/followers", getUserFollowers)
 	router.Put("/follow/:username", mAuth, follow)
 	router.Delete("/follow/:username", mAuth, unfollow)
+	router.Put("/mute/:username", mAuth, mute)
+	router.Delete("/mute/:username", mAuth, unmute)
 }
 
 type registerBody struct {
@@ -226,7 +228,13 @@ func getUserPosts(c *fiber.Ctx) error {
 		// ?
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
-	list, err := postService.GetByUser(username, target)
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := postService.GetByUser(username, target, page)
 	if err != nil {
 		switch err {
 		case posts.ErrUserNotFound:
@@ -240,6 +248,7 @@ func getUserPosts(c *fiber.Ctx) error {
 	logger := logging.Get()
 	msg := fmt.Sprintf("[USERS]GET: request for posts of %s", username)
 	logger.Info(msg)
+	setLinks(c, links)
 	c.Status(fiber.StatusOK)
 	return c.JSON(list)
 }
@@ -257,7 +266,13 @@ func getUserFollowings(c *fiber.Ctx) error {
 		// ?
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
-	list, err := userService.GetFollowings(username)
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := userService.GetFollowings(username, page)
 	if err != nil {
 		switch err {
 		case users.ErrUserNotFound:
@@ -271,6 +286,7 @@ func getUserFollowings(c *fiber.Ctx) error {
 	logger := logging.Get()
 	msg := fmt.Sprintf("[USERS]GET: request for followings of %s", username)
 	logger.Info(msg)
+	setLinks(c, links)
 	c.Status(fiber.StatusOK)
 	return c.JSON(fiber.Map{
 		"list": list,
@@ -290,7 +306,13 @@ func getUserFollowers(c *fiber.Ctx) error {
 		// ?
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
-	list, err := userService.GetFollowers(username)
+	page, ok := queryPage(c)
+	if !ok {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Invalid page: max_id, since_id or min_id.")
+	}
+
+	list, links, err := userService.GetFollowers(username, page)
 	if err != nil {
 		switch err {
 		case users.ErrUserNotFound:
@@ -304,6 +326,7 @@ func getUserFollowers(c *fiber.Ctx) error {
 	logger := logging.Get()
 	msg := fmt.Sprintf("[USERS]GET: request for followers of %s", username)
 	logger.Info(msg)
+	setLinks(c, links)
 	c.Status(fiber.StatusOK)
 	return c.JSON(fiber.Map{
 		"list": list,
@@ -387,3 +410,78 @@ func unfollow(c *fiber.Ctx) error {
 	logger.Info(msg)
 	return c.SendStatus(fiber.StatusOK)
 }
+
+func mute(c *fiber.Ctx) error {
+	target := c.Params("username")
+	if target == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire target username")
+	}
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var userService *users.UserService
+	err := services.Get(reflect.ValueOf(&userService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := userService.Mute(username, target); err != nil {
+		switch err {
+		case users.ErrSelfMute:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Try to self-mute")
+		case users.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString(fmt.Sprintf("User not found: %s", username))
+		case users.ErrMuteToNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString(fmt.Sprintf("User not found: %s", target))
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[USERS]MUTE: %s mutes %s", username, target)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func unmute(c *fiber.Ctx) error {
+	target := c.Params("username")
+	if target == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.SendString("Acquire target username")
+	}
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var userService *users.UserService
+	err := services.Get(reflect.ValueOf(&userService).Elem())
+	if err != nil {
+		// ?
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := userService.Unmute(username, target); err != nil {
+		switch err {
+		case users.ErrSelfMute:
+			c.Status(fiber.StatusBadRequest)
+			return c.SendString("Try to self-unmute")
+		case users.ErrUserNotFound:
+			c.Status(fiber.StatusNotFound)
+			return c.SendString(fmt.Sprintf("User not found: %s", username))
+		default:
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger := logging.Get()
+	msg := fmt.Sprintf("[USERS]UNMUTE: %s unmutes %s", username, target)
+	logger.Info(msg)
+	return c.SendStatus(fiber.StatusOK)
+}
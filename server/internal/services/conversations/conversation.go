@@ -0,0 +1,152 @@
+package conversations
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// participants other than the user
+func others(username string, participants []string) []string {
+	list := make([]string, 0, len(participants))
+	for _, v := range participants {
+		if v != username {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func parseID(id string) (int64, bool) {
+	n, e := strconv.ParseInt(id, 10, 64)
+	if e != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// ERRORS
+//
+//   - UserNotFound
+//   - Internal
+func (service *ConversationService) GetList(username string, page utils.Page) (list []*Conversation, links utils.PageLinks, err error) {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return nil, links, ErrUserNotFound
+	}
+
+	result, e := service.db.Conversation.QueryConversations(username, page)
+	if e != nil {
+		msg := fmt.Sprintf("[Conversations] Cannot query conversations of %s", username)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+
+	cursors := make([]utils.Cursor, 0, len(result))
+	ids := make([]string, 0, len(result))
+	for _, v := range result {
+		cursors = append(cursors, v.Cursor())
+		ids = append(ids, v.LastPost)
+	}
+	ps := service.post.GetByIDs(username, ids)
+
+	us := make(map[string]*users.UserInfo)
+	gu := func(u string) *users.UserInfo {
+		if us[u] != nil {
+			return us[u]
+		}
+		ui, e := service.user.GetInfo(u)
+		if e != nil {
+			msg := fmt.Sprintf("[Conversations] Cannot get info of %s", u)
+			logger.Error(msg, e)
+			return nil
+		}
+		us[u] = &ui
+		return &ui
+	}
+
+	list = make([]*Conversation, 0, len(result))
+	for _, v := range result {
+		c := Conversation{
+			ID:           strconv.FormatInt(v.ID, 10),
+			Participants: make([]*users.UserInfo, 0, len(v.Participants)),
+			Last:         ps[v.LastPost],
+			Unread:       v.Unread,
+		}
+		if c.Last == nil {
+			continue
+		}
+		for _, u := range others(username, v.Participants) {
+			if ui := gu(u); ui != nil {
+				c.Participants = append(c.Participants, ui)
+			}
+		}
+		list = append(list, &c)
+	}
+
+	return list, page.Links(cursors), nil
+}
+
+// posts of the conversation readable by the user, newest first
+//
+// ERRORS
+//
+//   - ConversationNotFound
+//   - Internal
+func (service *ConversationService) Get(username, id string, page utils.Page) (list []*posts.Post, links utils.PageLinks, err error) {
+	logger := service.lg
+	cid, ok := parseID(id)
+	if !ok || !service.db.Conversation.IsConversationMember(cid, username) {
+		return nil, links, ErrConversationNotFound
+	}
+
+	result, e := service.db.Conversation.QueryConversationPosts(cid, username, page)
+	if e != nil {
+		msg := fmt.Sprintf("[Conversations] Cannot query posts of conversation %s", id)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+
+	cursors := make([]utils.Cursor, 0, len(result))
+	ids := make([]string, 0, len(result))
+	for _, v := range result {
+		cursors = append(cursors, v.Cursor())
+		ids = append(ids, v.ID)
+	}
+	ps := service.post.GetByIDs(username, ids)
+	list = make([]*posts.Post, 0, len(result))
+	for _, v := range ids {
+		if p := ps[v]; p != nil {
+			list = append(list, p)
+		}
+	}
+
+	return list, page.Links(cursors), nil
+}
+
+// ERRORS
+//
+//   - ConversationNotFound
+//   - Internal
+func (service *ConversationService) MarkRead(username, id string) error {
+	logger := service.lg
+	cid, ok := parseID(id)
+	if !ok {
+		return ErrConversationNotFound
+	}
+	if e := service.db.Conversation.MarkConversationRead(cid, username); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrConversationNotFound
+		default:
+			msg := fmt.Sprintf("[Conversations] Cannot mark conversation %s read", id)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package conversations
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestOthers(t *testing.T) {
+	test.AssertEqual(t, []string{"a", "c@remote.sns"}, others("b", []string{"a", "b", "c@remote.sns"}))
+	test.AssertEqual(t, []string{}, others("a", []string{"a"}))
+}
+
+func TestParseID(t *testing.T) {
+	cases := []struct {
+		id   string
+		want int64
+		ok   bool
+	}{
+		{"12", 12, true},
+		{"0", 0, false},
+		{"-1", 0, false},
+		{"x", 0, false},
+		{"", 0, false},
+	}
+	for _, v := range cases {
+		id, ok := parseID(v.id)
+		test.AssertEqual(t, v.ok, ok)
+		test.AssertEqual(t, v.want, id)
+	}
+}
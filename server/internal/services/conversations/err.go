@@ -0,0 +1,7 @@
+package conversations
+
+import "errors"
+
+var ErrUserNotFound = errors.New("UserNotFound")
+var ErrConversationNotFound = errors.New("ConversationNotFound")
+var ErrInternal = errors.New("Internal")
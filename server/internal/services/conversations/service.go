@@ -0,0 +1,39 @@
+package conversations
+
+import (
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+)
+
+// direct posts among the same participants
+type Conversation struct {
+	ID           string            `json:"id"`
+	Participants []*users.UserInfo `json:"participants"` // except the user
+	Last         *posts.Post       `json:"last"`
+	Unread       bool              `json:"unread"`
+}
+
+// service
+
+type ConversationDbs struct {
+	Conversation models.IConversation
+}
+
+type ConversationService struct {
+	lg   logging.Logger
+	db   ConversationDbs
+	user *users.UserService
+	post *posts.PostService
+}
+
+func NewService(us *users.UserService, ps *posts.PostService, dbs ConversationDbs, cfg config.Config, lg logging.Logger) *ConversationService {
+	return &ConversationService{
+		lg:   lg,
+		db:   dbs,
+		user: us,
+		post: ps,
+	}
+}
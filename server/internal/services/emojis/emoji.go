@@ -0,0 +1,223 @@
+package emojis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/files"
+)
+
+const (
+	max_emoji_size = 256 * 1024 // bytes
+)
+
+// ":shortcode:" not in the middle of a word, or right after another
+var shortcodeRegexp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_:]):([a-zA-Z0-9_]{2,30}):`)
+var shortcodeNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]{2,30}$`)
+
+// shortcode without colons
+func IsShortcode(s string) bool {
+	return shortcodeNameRegexp.MatchString(s)
+}
+
+// shortcodes in the texts, in order and deduplicated
+func ParseShortcodes(texts ...string) []string {
+	list := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, t := range texts {
+		for _, m := range shortcodeRegexp.FindAllStringSubmatch(t, -1) {
+			if seen[m[1]] {
+				continue
+			}
+			seen[m[1]] = true
+			list = append(list, m[1])
+		}
+	}
+	return list
+}
+
+// domain of the user. empty if of this site
+func DomainOf(user string) string {
+	if i := strings.Index(user, "@"); i >= 0 {
+		return user[i+1:]
+	}
+	return ""
+}
+
+// custom emoji of the domain used in the texts. ones not found are absent
+//
+// ERRORS
+//
+//   - DbInternal
+func Resolve(db models.ICustomEmoji, domain string, texts ...string) (list []*Emoji, err error) {
+	codes := ParseShortcodes(texts...)
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	result, e := db.QueryEmojisByShortcodes(domain, codes)
+	if e != nil {
+		return nil, e
+	}
+	list = make([]*Emoji, 0, len(result))
+	for _, v := range result {
+		list = append(list, makeEmoji(v))
+	}
+	return list, nil
+}
+
+// custom emoji of this site
+//
+// ERRORS
+//
+//   - Internal
+func (service *EmojiService) List() (list []*Emoji, err error) {
+	logger := service.lg
+	result, e := service.db.Emoji.QueryEmojis("")
+	if e != nil {
+		logger.Error("[Emojis] Cannot get custom emoji", e)
+		return nil, ErrInternal
+	}
+	list = make([]*Emoji, 0, len(result))
+	for _, v := range result {
+		list = append(list, makeEmoji(v))
+	}
+	return list, nil
+}
+
+// upload a custom emoji of this site. only by admins
+//
+// ERRORS
+//
+//   - NotPermitted
+//   - Shortcode
+//   - Image: not jpeg or png, or too large
+//   - EmojiExist
+//   - Internal
+func (service *EmojiService) Upload(username, shortcode, category string, buf []byte) (emoji *Emoji, err error) {
+	logger := service.lg
+	if !service.IsAdmin(username) {
+		return nil, ErrNotPermitted
+	}
+	shortcode = strings.Trim(shortcode, ":")
+	if !IsShortcode(shortcode) {
+		return nil, ErrShortcode
+	}
+	if len(buf) > max_emoji_size {
+		return nil, ErrImage
+	}
+	// checked before storing the image
+	if l, e := service.db.Emoji.QueryEmojisByShortcodes("", []string{shortcode}); e == nil && len(l) != 0 {
+		return nil, ErrEmojiExist
+	}
+	url, _, e := service.file.StoreImage(username, buf)
+	if e != nil {
+		switch e {
+		case files.ErrFile:
+			return nil, ErrImage
+		default:
+			return nil, ErrInternal
+		}
+	}
+
+	ce := models.CustomEmoji{
+		Shortcode: shortcode, Url: url,
+		Category: strings.TrimSpace(category), Date: time.Now(),
+	}
+	if e := service.db.Emoji.SetEmoji(&ce); e != nil {
+		switch e {
+		case models.ErrDunplicate:
+			return nil, ErrEmojiExist
+		default:
+			msg := fmt.Sprintf("[Emojis] Cannot set :%s:", shortcode)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	return makeEmoji(&ce), nil
+}
+
+// remove a custom emoji of this site. only by admins. texts using it keep the shortcode
+//
+// ERRORS
+//
+//   - NotPermitted
+//   - EmojiNotFound
+//   - Internal
+func (service *EmojiService) Remove(username, shortcode string) error {
+	logger := service.lg
+	if !service.IsAdmin(username) {
+		return ErrNotPermitted
+	}
+	if e := service.db.Emoji.RemoveEmoji(strings.Trim(shortcode, ":"), ""); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrEmojiNotFound
+		default:
+			msg := fmt.Sprintf("[Emojis] Cannot remove :%s:", shortcode)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// custom emoji of another site, as in an Emoji tag
+type RemoteEmoji struct {
+	Shortcode string // without colons
+	Url       string // url of the icon
+}
+
+// cache custom emoji of another site in the objects received from it.
+// images are stored on this site, and fetched again only when changed
+func (service *EmojiService) Ingest(domain string, list []RemoteEmoji) {
+	logger := service.lg
+	if domain == "" || len(list) == 0 {
+		return
+	}
+	codes := make([]string, 0, len(list))
+	for _, v := range list {
+		codes = append(codes, v.Shortcode)
+	}
+	cached, e := service.db.Emoji.QueryEmojisByShortcodes(domain, codes)
+	if e != nil {
+		msg := fmt.Sprintf("[Emojis] Cannot get custom emoji of %s", domain)
+		logger.Error(msg, e)
+		return
+	}
+	m := make(map[string]string)
+	for _, v := range cached {
+		m[v.Shortcode] = v.RemoteUrl
+	}
+
+	for _, v := range list {
+		if !IsShortcode(v.Shortcode) || m[v.Shortcode] == v.Url {
+			continue
+		}
+		buf, e := service.file.Fetch(v.Url, max_emoji_size)
+		if e != nil {
+			logger.Warning("[Emojis] Cannot fetch custom emoji",
+				"domain", domain, "shortcode", v.Shortcode, "url", v.Url,
+			)
+			continue
+		}
+		url, _, e := service.file.StoreImage("", buf)
+		if e != nil {
+			logger.Warning("[Emojis] Cannot store custom emoji",
+				"domain", domain, "shortcode", v.Shortcode, "url", v.Url,
+			)
+			continue
+		}
+		ce := models.CustomEmoji{
+			Shortcode: v.Shortcode, Domain: domain,
+			Url: url, RemoteUrl: v.Url, Date: time.Now(),
+		}
+		if e := service.db.Emoji.ReplaceEmoji(&ce); e != nil {
+			msg := fmt.Sprintf("[Emojis] Cannot cache :%s: of %s", v.Shortcode, domain)
+			logger.Error(msg, e)
+		}
+		m[v.Shortcode] = v.Url
+	}
+}
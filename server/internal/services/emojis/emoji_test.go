@@ -0,0 +1,27 @@
+package emojis
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestParseShortcodes(t *testing.T) {
+	cases := []struct {
+		texts []string
+		want  []string
+	}{
+		{[]string{":blobcat: and :blob_fox:, :blobcat:"}, []string{"blobcat", "blob_fox"}},
+		{[]string{"cw :a1:", "<p>:a1: :b2:</p>"}, []string{"a1", "b2"}},
+		{[]string{"at 12:30:45, a:bc: :d: ::"}, []string{}},
+		{[]string{":too_long_shortcode_of_an_emoji_x:"}, []string{}},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, ParseShortcodes(v.texts...))
+	}
+}
+
+func TestDomainOf(t *testing.T) {
+	test.AssertEqual(t, "", DomainOf("u1"))
+	test.AssertEqual(t, "other.sns", DomainOf("u1@other.sns"))
+}
@@ -0,0 +1,10 @@
+package emojis
+
+import "errors"
+
+var ErrNotPermitted = errors.New("NotPermitted")
+var ErrShortcode = errors.New("Shortcode")
+var ErrImage = errors.New("Image")
+var ErrEmojiExist = errors.New("EmojiExist")
+var ErrEmojiNotFound = errors.New("EmojiNotFound")
+var ErrInternal = errors.New("Internal")
@@ -0,0 +1,49 @@
+package emojis
+
+import (
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/files"
+)
+
+// custom emoji, written as ":shortcode:" in texts
+type Emoji struct {
+	Shortcode string `json:"shortcode"` // without colons
+	Url       string `json:"url"`
+	Category  string `json:"category,omitempty"`
+}
+
+func makeEmoji(e *models.CustomEmoji) *Emoji {
+	return &Emoji{Shortcode: e.Shortcode, Url: e.Url, Category: e.Category}
+}
+
+// service
+
+type EmojiDbs struct {
+	Emoji models.ICustomEmoji
+}
+
+type EmojiService struct {
+	lg     logging.Logger
+	admins map[string]bool
+	db     EmojiDbs
+	file   *files.FileService
+}
+
+func NewService(fs *files.FileService, dbs EmojiDbs, cfg config.Config, lg logging.Logger) *EmojiService {
+	admins := make(map[string]bool)
+	for _, v := range cfg.Admins {
+		admins[v] = true
+	}
+	return &EmojiService{
+		lg:     lg,
+		admins: admins,
+		db:     dbs,
+		file:   fs,
+	}
+}
+
+func (service *EmojiService) IsAdmin(username string) bool {
+	return service.admins[username]
+}
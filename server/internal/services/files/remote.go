@@ -0,0 +1,105 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+const (
+	fetch_timeout = 10 * time.Second
+	max_redirects = 3
+)
+
+var errNotPublic = errors.New("address not public")
+var errRedirect = errors.New("too many or unsafe redirects")
+
+// addresses of this host and its networks, which urls of other sites must not reach
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// checked on each connection with the address resolved, so a name resolved
+// again, by redirects or a rebinding dns, cannot point inside either
+var dialer = &net.Dialer{
+	Timeout: fetch_timeout,
+	Control: func(network, address string, c syscall.RawConn) error {
+		host, _, e := net.SplitHostPort(address)
+		if e != nil {
+			return e
+		}
+		if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+			return errNotPublic
+		}
+		return nil
+	},
+}
+
+var client = &http.Client{
+	Timeout: fetch_timeout,
+	// no proxy, as the addresses dialed are checked
+	Transport: &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: fetch_timeout,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) > max_redirects || !utils.IsSafeUrl(req.URL.String()) {
+			return errRedirect
+		}
+		return nil
+	},
+}
+
+// fetch a file of other sites, no larger than the limit.
+// addresses not public are refused
+//
+// ERRORS
+//
+//   - File: not http(s), not public, redirected too many times, not found or too large
+//   - FsInternal: cannot reach
+func (service *FileService) Fetch(url string, limit int64) (buf []byte, err error) {
+	logger := service.lg
+	if !utils.IsSafeUrl(url) {
+		return nil, ErrFile
+	}
+	r, e := client.Get(url)
+	if e != nil {
+		if errors.Is(e, errNotPublic) || errors.Is(e, errRedirect) {
+			logger.Warning("[Files.Remote] Refused to fetch",
+				"url", url,
+				"reason", e.Error(),
+			)
+			return nil, ErrFile
+		}
+		msg := fmt.Sprintf("[Files.Remote] Cannot fetch %s", url)
+		logger.Error(msg, e)
+		return nil, ErrFsInternal
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		logger.Warning("[Files.Remote] Failed to fetch",
+			"url", url,
+			"status", r.StatusCode,
+		)
+		return nil, ErrFile
+	}
+	// one more byte to know if it's too large
+	buf, e = io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if e != nil {
+		msg := fmt.Sprintf("[Files.Remote] Cannot read %s", url)
+		logger.Error(msg, e)
+		return nil, ErrFsInternal
+	}
+	if int64(len(buf)) > limit {
+		return nil, ErrFile
+	}
+	return buf, nil
+}
@@ -0,0 +1,46 @@
+package files
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	cases := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, isPublicIP(net.ParseIP(v.ip)))
+	}
+}
+
+func TestFetchRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer server.Close()
+
+	service := &FileService{lg: test.NewMockingLogger(t)}
+	_, err := service.Fetch(server.URL, 1024)
+	test.AssertEqual(t, ErrFile, err)
+	_, err = service.Fetch("file:///etc/passwd", 1024)
+	test.AssertEqual(t, ErrFile, err)
+}
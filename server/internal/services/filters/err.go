@@ -0,0 +1,11 @@
+package filters
+
+import "errors"
+
+var ErrUserNotFound = errors.New("UserNotFound")
+var ErrFilterNotFound = errors.New("FilterNotFound")
+var ErrPhrase = errors.New("Phrase")
+var ErrContext = errors.New("Context")
+var ErrAction = errors.New("Action")
+var ErrExpiry = errors.New("Expiry")
+var ErrInternal = errors.New("Internal")
@@ -0,0 +1,260 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/lib/pq"
+)
+
+const filter_max_phrase = 100 // characters
+
+// fields absent are the defaults on creating, or unchanged on updating
+type FilterBody struct {
+	Phrase    string   `json:"phrase,omitempty"`
+	Context   []string `json:"context,omitempty"`
+	WholeWord *bool    `json:"wholeWord,omitempty"` // true by default
+	Action    string   `json:"action,omitempty"`    // warn by default
+	ExpiresIn *int64   `json:"expiresIn,omitempty"` // seconds from now. 0 means never, by default
+}
+
+// trimmed, 1 to filter_max_phrase characters
+func phraseOf(phrase string) (string, bool) {
+	phrase = strings.TrimSpace(phrase)
+	if phrase == "" || utf8.RuneCountInString(phrase) > filter_max_phrase {
+		return "", false
+	}
+	return phrase, true
+}
+
+// at least one, deduplicated
+func contextsOf(literals []string) (pq.StringArray, bool) {
+	if len(literals) == 0 {
+		return nil, false
+	}
+	seen := make(map[models.FilterContext]bool)
+	list := make(pq.StringArray, 0, len(literals))
+	for _, v := range literals {
+		c, ok := models.GetFilterContext(strings.TrimSpace(v))
+		if !ok {
+			return nil, false
+		}
+		if !seen[c] {
+			seen[c] = true
+			list = append(list, string(c))
+		}
+	}
+	return list, true
+}
+
+// zero time means never
+func expiresOf(seconds int64, now time.Time) (time.Time, bool) {
+	if seconds < 0 {
+		return time.Time{}, false
+	}
+	if seconds == 0 {
+		return time.Time{}, true
+	}
+	return now.Add(time.Duration(seconds) * time.Second), true
+}
+
+func toFilter(f *models.Filter, now time.Time) *Filter {
+	r := Filter{
+		ID: f.ID, Phrase: f.Phrase, Context: f.Contexts,
+		WholeWord: f.WholeWord, Action: string(f.Action),
+	}
+	if !f.Expires.IsZero() {
+		r.ExpiresAt = f.Expires.Format(time.RFC3339)
+		r.Expired = !f.Expires.After(now)
+	}
+	return &r
+}
+
+// apply fields present in the body to the filter
+func (body *FilterBody) apply(f *models.Filter, now time.Time) error {
+	if body.Phrase != "" {
+		phrase, ok := phraseOf(body.Phrase)
+		if !ok {
+			return ErrPhrase
+		}
+		f.Phrase = phrase
+	}
+	if body.Context != nil {
+		contexts, ok := contextsOf(body.Context)
+		if !ok {
+			return ErrContext
+		}
+		f.Contexts = contexts
+	}
+	if body.WholeWord != nil {
+		f.WholeWord = *body.WholeWord
+	}
+	if body.Action != "" {
+		action, ok := models.GetFilterAction(body.Action)
+		if !ok {
+			return ErrAction
+		}
+		f.Action = action
+	}
+	if body.ExpiresIn != nil {
+		exp, ok := expiresOf(*body.ExpiresIn, now)
+		if !ok {
+			return ErrExpiry
+		}
+		f.Expires = exp
+	}
+	return nil
+}
+
+// the filter if owned by the user. filters of others are not found
+func (service *FilterService) owned(username, id string) (*models.Filter, error) {
+	logger := service.lg
+	f, e := service.db.Filter.QueryFilterByID(id)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return nil, ErrFilterNotFound
+		default:
+			msg := fmt.Sprintf("[Filters] Cannot get filter %s", id)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	if f.User != username {
+		return nil, ErrFilterNotFound
+	}
+	return f, nil
+}
+
+// filters of the user including expired ones, descending by date of creating
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - Internal
+func (service *FilterService) GetFilters(username string) (list []*Filter, err error) {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return nil, ErrUserNotFound
+	}
+	result, e := service.db.Filter.QueryFilters(username)
+	if e != nil {
+		msg := fmt.Sprintf("[Filters] Cannot query filters of %s", username)
+		logger.Error(msg, e)
+		return nil, ErrInternal
+	}
+	now := time.Now()
+	list = make([]*Filter, 0, len(result))
+	for _, v := range result {
+		list = append(list, toFilter(v, now))
+	}
+	return list, nil
+}
+
+// ERRORS
+//
+//   - FilterNotFound
+//   - Internal
+func (service *FilterService) Get(username, id string) (filter *Filter, err error) {
+	f, e := service.owned(username, id)
+	if e != nil {
+		return nil, e
+	}
+	return toFilter(f, time.Now()), nil
+}
+
+// phrase and context are required
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - Phrase
+//   - Context
+//   - Action
+//   - Expiry
+//   - Internal
+func (service *FilterService) Create(username string, body *FilterBody) (filter *Filter, err error) {
+	logger := service.lg
+	if body.Phrase == "" {
+		return nil, ErrPhrase
+	}
+	if len(body.Context) == 0 {
+		return nil, ErrContext
+	}
+	now := time.Now()
+	f := models.Filter{
+		ID: uuid.New().String(), User: username,
+		WholeWord: true, Action: models.Fla_WARN, Date: now,
+	}
+	if e := body.apply(&f, now); e != nil {
+		return nil, e
+	}
+	if !service.user.IsUserExist(username) {
+		return nil, ErrUserNotFound
+	}
+
+	if e := service.db.Filter.SetFilter(&f); e != nil {
+		msg := fmt.Sprintf("[Filters] Cannot create filter of %s", username)
+		logger.Error(msg, e)
+		return nil, ErrInternal
+	}
+	return toFilter(&f, now), nil
+}
+
+// ERRORS
+//
+//   - FilterNotFound
+//   - Phrase
+//   - Context
+//   - Action
+//   - Expiry
+//   - Internal
+func (service *FilterService) Update(username, id string, body *FilterBody) (filter *Filter, err error) {
+	logger := service.lg
+	f, e := service.owned(username, id)
+	if e != nil {
+		return nil, e
+	}
+	now := time.Now()
+	if e := body.apply(f, now); e != nil {
+		return nil, e
+	}
+
+	if e := service.db.Filter.UpdateFilter(f); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return nil, ErrFilterNotFound
+		default:
+			msg := fmt.Sprintf("[Filters] Cannot update filter %s", id)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	return toFilter(f, now), nil
+}
+
+// ERRORS
+//
+//   - FilterNotFound
+//   - Internal
+func (service *FilterService) Remove(username, id string) error {
+	logger := service.lg
+	if _, e := service.owned(username, id); e != nil {
+		return e
+	}
+	if e := service.db.Filter.RemoveFilter(id); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrFilterNotFound
+		default:
+			msg := fmt.Sprintf("[Filters] Cannot remove filter %s", id)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
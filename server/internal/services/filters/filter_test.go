@@ -0,0 +1,89 @@
+package filters
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+	"github.com/lib/pq"
+)
+
+func TestPhraseOf(t *testing.T) {
+	cases := []struct {
+		phrase string
+		want   string
+		ok     bool
+	}{
+		{"spoiler", "spoiler", true},
+		{"  season finale ", "season finale", true},
+		{" ", "", false},
+		{strings.Repeat("a", filter_max_phrase+1), "", false},
+	}
+	for _, v := range cases {
+		phrase, ok := phraseOf(v.phrase)
+		test.AssertEqual(t, v.ok, ok)
+		test.AssertEqual(t, v.want, phrase)
+	}
+}
+
+func TestContextsOf(t *testing.T) {
+	cases := []struct {
+		literals []string
+		want     pq.StringArray
+		ok       bool
+	}{
+		{[]string{"home", "public"}, pq.StringArray{"home", "public"}, true},
+		{[]string{"thread", "thread", " profile"}, pq.StringArray{"thread", "profile"}, true},
+		{[]string{"home", "everywhere"}, nil, false},
+		{[]string{}, nil, false},
+	}
+	for _, v := range cases {
+		contexts, ok := contextsOf(v.literals)
+		test.AssertEqual(t, v.ok, ok)
+		test.AssertEqual(t, v.want, contexts)
+	}
+}
+
+func TestBodyApply(t *testing.T) {
+	now := time.Now()
+	f := models.Filter{
+		Phrase: "old", Contexts: pq.StringArray{"home"},
+		WholeWord: true, Action: models.Fla_WARN,
+		Expires: now.Add(time.Hour),
+	}
+
+	// absent fields are unchanged
+	body := FilterBody{Action: "hide"}
+	test.AssertNoError(t, body.apply(&f, now))
+	test.AssertEqual(t, "old", f.Phrase)
+	test.AssertEqual(t, models.Fla_HIDE, f.Action)
+	test.AssertEqual(t, now.Add(time.Hour), f.Expires)
+
+	never, no := int64(0), false
+	body = FilterBody{Phrase: " new ", WholeWord: &no, ExpiresIn: &never}
+	test.AssertNoError(t, body.apply(&f, now))
+	test.AssertEqual(t, "new", f.Phrase)
+	test.AssertEqual(t, false, f.WholeWord)
+	test.AssertEqual(t, true, f.Expires.IsZero())
+
+	day := int64(86400)
+	body = FilterBody{ExpiresIn: &day}
+	test.AssertNoError(t, body.apply(&f, now))
+	test.AssertEqual(t, now.Add(24*time.Hour), f.Expires)
+
+	past := int64(-1)
+	test.AssertEqual(t, ErrExpiry, (&FilterBody{ExpiresIn: &past}).apply(&f, now))
+	test.AssertEqual(t, ErrAction, (&FilterBody{Action: "mute"}).apply(&f, now))
+	test.AssertEqual(t, ErrContext, (&FilterBody{Context: []string{"x"}}).apply(&f, now))
+}
+
+func TestToFilter(t *testing.T) {
+	now := time.Now()
+	f := models.Filter{ID: "1", Phrase: "a", Action: models.Fla_WARN}
+	test.AssertEqual(t, "", toFilter(&f, now).ExpiresAt)
+	test.AssertEqual(t, false, toFilter(&f, now).Expired)
+	f.Expires = now.Add(-time.Minute)
+	test.AssertEqual(t, true, toFilter(&f, now).Expired)
+}
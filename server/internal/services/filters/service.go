@@ -0,0 +1,39 @@
+package filters
+
+import (
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/users"
+)
+
+// a word or phrase hiding posts, or warning of them
+type Filter struct {
+	ID        string   `json:"id"`
+	Phrase    string   `json:"phrase"`
+	Context   []string `json:"context"` // home, notifications, public, thread or profile
+	WholeWord bool     `json:"wholeWord"`
+	Action    string   `json:"action"`              // hide or warn
+	ExpiresAt string   `json:"expiresAt,omitempty"` // absent if never
+	Expired   bool     `json:"expired"`
+}
+
+// service
+
+type FilterDbs struct {
+	Filter models.IFilter
+}
+
+type FilterService struct {
+	lg   logging.Logger
+	db   FilterDbs
+	user *users.UserService
+}
+
+func NewService(us *users.UserService, dbs FilterDbs, cfg config.Config, lg logging.Logger) *FilterService {
+	return &FilterService{
+		lg:   lg,
+		db:   dbs,
+		user: us,
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/trends"
+)
+
+const (
+	poll_closing_interval = time.Minute
+	publishing_interval   = 30 * time.Second
+	trending_interval     = 10 * time.Minute
+)
+
+// run the job at each interval in background
+func every(interval time.Duration, job func(now time.Time)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			job(now)
+		}
+	}()
+}
+
+// start jobs running in background
+func StartJobs() {
+	lg := logging.Get()
+	var ps *posts.PostService
+	if err := Get(reflect.ValueOf(&ps).Elem()); err != nil {
+		lg.Error("[Jobs] Cannot get post service", err)
+		return
+	}
+	every(poll_closing_interval, ps.ClosePolls)
+	lg.Info("[Jobs] Started closing expired polls")
+	// posts due while stopped are published at once
+	go ps.PublishScheduled(time.Now())
+	every(publishing_interval, ps.PublishScheduled)
+	lg.Info("[Jobs] Started publishing scheduled posts")
+
+	var ts *trends.TrendService
+	if err := Get(reflect.ValueOf(&ts).Elem()); err != nil {
+		lg.Error("[Jobs] Cannot get trend service", err)
+		return
+	}
+	// cached trends may have expired while stopped
+	go ts.Refresh(time.Now())
+	every(trending_interval, ts.Refresh)
+	lg.Info("[Jobs] Started computing trends")
+}
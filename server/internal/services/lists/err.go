@@ -0,0 +1,11 @@
+package lists
+
+import "errors"
+
+var ErrUserNotFound = errors.New("UserNotFound")
+var ErrListNotFound = errors.New("ListNotFound")
+var ErrTitle = errors.New("Title")
+var ErrRepliesPolicy = errors.New("RepliesPolicy")
+var ErrNotFollowing = errors.New("NotFollowing")
+var ErrCursor = errors.New("Cursor")
+var ErrInternal = errors.New("Internal")
@@ -0,0 +1,308 @@
+package lists
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+const list_max_title = 100 // characters
+
+// trimmed, 1 to list_max_title characters
+func titleOf(title string) (string, bool) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > list_max_title {
+		return "", false
+	}
+	return title, true
+}
+
+// the policy of the literal, or dflt when it's empty
+func repliesPolicyOf(literal string, dflt models.RepliesPolicy) (models.RepliesPolicy, error) {
+	if literal == "" {
+		return dflt, nil
+	}
+	p, ok := models.GetRepliesPolicy(literal)
+	if !ok {
+		return "", ErrRepliesPolicy
+	}
+	return p, nil
+}
+
+func toList(l *models.List) List {
+	return List{ID: l.ID, Title: l.Title, RepliesPolicy: string(l.Replies)}
+}
+
+// the list if owned by the user. lists of others are not found
+func (service *ListService) owned(username, id string) (*models.List, error) {
+	logger := service.lg
+	l, e := service.db.List.QueryListByID(id)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return nil, ErrListNotFound
+		default:
+			msg := fmt.Sprintf("[Lists] Cannot get list %s", id)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	if l.User != username {
+		return nil, ErrListNotFound
+	}
+	return l, nil
+}
+
+// evict the cached timeline after its members or policy changed
+func (service *ListService) invalidate(id string) {
+	logger := service.lg
+	if e := service.db.TimelineCache.RemoveListTimeline(id); e != nil {
+		msg := fmt.Sprintf("[Lists] Cannot evict timeline of list %s", id)
+		logger.Error(msg, e)
+	}
+}
+
+// ERRORS
+//
+//   - UserNotFound
+//   - Internal
+func (service *ListService) GetLists(username string) (list []*List, err error) {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return nil, ErrUserNotFound
+	}
+	result, e := service.db.List.QueryLists(username)
+	if e != nil {
+		msg := fmt.Sprintf("[Lists] Cannot query lists of %s", username)
+		logger.Error(msg, e)
+		return nil, ErrInternal
+	}
+	list = make([]*List, 0, len(result))
+	for _, v := range result {
+		l := toList(v)
+		list = append(list, &l)
+	}
+	return list, nil
+}
+
+// ERRORS
+//
+//   - ListNotFound
+//   - Internal
+func (service *ListService) Get(username, id string) (list List, err error) {
+	l, e := service.owned(username, id)
+	if e != nil {
+		return list, e
+	}
+	return toList(l), nil
+}
+
+// replies policy is "list" when empty
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - Title
+//   - RepliesPolicy
+//   - Internal
+func (service *ListService) Create(username, title, replies string) (list List, err error) {
+	logger := service.lg
+	t, ok := titleOf(title)
+	if !ok {
+		return list, ErrTitle
+	}
+	policy, e := repliesPolicyOf(replies, models.Lrp_LIST)
+	if e != nil {
+		return list, e
+	}
+	if !service.user.IsUserExist(username) {
+		return list, ErrUserNotFound
+	}
+
+	l := models.List{
+		ID: uuid.New().String(), User: username,
+		Title: t, Replies: policy, Date: time.Now(),
+	}
+	if e := service.db.List.SetList(&l); e != nil {
+		msg := fmt.Sprintf("[Lists] Cannot create list of %s", username)
+		logger.Error(msg, e)
+		return list, ErrInternal
+	}
+	return toList(&l), nil
+}
+
+// empty title or replies policy is unchanged
+//
+// ERRORS
+//
+//   - ListNotFound
+//   - Title
+//   - RepliesPolicy
+//   - Internal
+func (service *ListService) Update(username, id, title, replies string) (list List, err error) {
+	logger := service.lg
+	l, e := service.owned(username, id)
+	if e != nil {
+		return list, e
+	}
+	if title != "" {
+		t, ok := titleOf(title)
+		if !ok {
+			return list, ErrTitle
+		}
+		l.Title = t
+	}
+	policy, e := repliesPolicyOf(replies, l.Replies)
+	if e != nil {
+		return list, e
+	}
+	changed := policy != l.Replies
+	l.Replies = policy
+
+	if e := service.db.List.UpdateList(l); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return list, ErrListNotFound
+		default:
+			msg := fmt.Sprintf("[Lists] Cannot update list %s", id)
+			logger.Error(msg, e)
+			return list, ErrInternal
+		}
+	}
+	if changed {
+		service.invalidate(id)
+	}
+	return toList(l), nil
+}
+
+// ERRORS
+//
+//   - ListNotFound
+//   - Internal
+func (service *ListService) Remove(username, id string) error {
+	logger := service.lg
+	if _, e := service.owned(username, id); e != nil {
+		return e
+	}
+	if e := service.db.List.RemoveList(id); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrListNotFound
+		default:
+			msg := fmt.Sprintf("[Lists] Cannot remove list %s", id)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	service.invalidate(id)
+	return nil
+}
+
+// members of the list, descending by date of adding
+//
+// ERRORS
+//
+//   - ListNotFound
+//   - Internal
+func (service *ListService) GetMembers(username, id string, page utils.Page) (list []*users.UserInfo, links utils.PageLinks, err error) {
+	logger := service.lg
+	if _, e := service.owned(username, id); e != nil {
+		return nil, links, e
+	}
+	result, e := service.db.Member.QueryListMembers(id, page)
+	if e != nil {
+		msg := fmt.Sprintf("[Lists] Cannot query members of list %s", id)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+
+	cursors := make([]utils.Cursor, 0, len(result))
+	list = make([]*users.UserInfo, 0, len(result))
+	for _, v := range result {
+		cursors = append(cursors, v.Cursor())
+		ui, e := service.user.GetInfo(v.User)
+		if e != nil {
+			msg := fmt.Sprintf("[Lists] Cannot get info of %s", v.User)
+			logger.Error(msg, e)
+			continue
+		}
+		list = append(list, &ui)
+	}
+	return list, page.Links(cursors), nil
+}
+
+// add users followed by the owner. none is added if any is not followed
+//
+// ERRORS
+//
+//   - ListNotFound
+//   - NotFollowing
+//   - Internal
+func (service *ListService) AddMembers(username, id string, members []string) error {
+	logger := service.lg
+	if _, e := service.owned(username, id); e != nil {
+		return e
+	}
+	for _, v := range members {
+		if !service.user.IsFollowing(username, v) {
+			return ErrNotFollowing
+		}
+	}
+	if e := service.db.Member.SetListMembers(id, members, time.Now()); e != nil {
+		msg := fmt.Sprintf("[Lists] Cannot add members to list %s", id)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	service.invalidate(id)
+	return nil
+}
+
+// users not in the list are skipped
+//
+// ERRORS
+//
+//   - ListNotFound
+//   - Internal
+func (service *ListService) RemoveMembers(username, id string, members []string) error {
+	logger := service.lg
+	if _, e := service.owned(username, id); e != nil {
+		return e
+	}
+	if e := service.db.Member.RemoveListMembers(id, members); e != nil {
+		msg := fmt.Sprintf("[Lists] Cannot remove members from list %s", id)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	service.invalidate(id)
+	return nil
+}
+
+// posts and shares of members, with replies by the replies policy
+//
+// ERRORS
+//
+//   - ListNotFound
+//   - Cursor
+//   - Internal
+func (service *ListService) GetTimeline(username, id, from string) (tl posts.Timeline, err error) {
+	if _, e := service.owned(username, id); e != nil {
+		return tl, e
+	}
+	tl, e := service.post.GetListTimeline(username, id, from)
+	if e != nil {
+		switch e {
+		case posts.ErrCursor:
+			return tl, ErrCursor
+		default:
+			return tl, ErrInternal
+		}
+	}
+	return tl, nil
+}
@@ -0,0 +1,80 @@
+package lists
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+type mockingListDb struct {
+	models.IList
+	data map[string]*models.List
+}
+
+func (db *mockingListDb) QueryListByID(id string) (*models.List, error) {
+	l := db.data[id]
+	if l == nil {
+		return nil, models.ErrNotFound
+	}
+	c := *l
+	return &c, nil
+}
+
+func TestTitleOf(t *testing.T) {
+	cases := []struct {
+		title string
+		want  string
+		ok    bool
+	}{
+		{"work", "work", true},
+		{"  friends \n", "friends", true},
+		{"   ", "", false},
+		{strings.Repeat("猫", list_max_title), strings.Repeat("猫", list_max_title), true},
+		{strings.Repeat("a", list_max_title+1), "", false},
+	}
+	for _, v := range cases {
+		title, ok := titleOf(v.title)
+		test.AssertEqual(t, v.ok, ok)
+		test.AssertEqual(t, v.want, title)
+	}
+}
+
+func TestRepliesPolicyOf(t *testing.T) {
+	cases := []struct {
+		literal string
+		dflt    models.RepliesPolicy
+		want    models.RepliesPolicy
+		err     error
+	}{
+		{"", models.Lrp_LIST, models.Lrp_LIST, nil},
+		{"", models.Lrp_NONE, models.Lrp_NONE, nil},
+		{"followed", models.Lrp_LIST, models.Lrp_FOLLOWED, nil},
+		{"none", models.Lrp_LIST, models.Lrp_NONE, nil},
+		{"everyone", models.Lrp_LIST, "", ErrRepliesPolicy},
+	}
+	for _, v := range cases {
+		p, e := repliesPolicyOf(v.literal, v.dflt)
+		test.AssertEqual(t, v.err, e)
+		test.AssertEqual(t, v.want, p)
+	}
+}
+
+func TestOwned(t *testing.T) {
+	logger := test.NewMockingLogger(t)
+	db := &mockingListDb{data: map[string]*models.List{
+		"1": {ID: "1", User: "u1", Title: "work", Replies: models.Lrp_LIST},
+	}}
+	service := NewService(nil, nil, ListDbs{List: db}, config.Config{}, logger)
+
+	l, e := service.owned("u1", "1")
+	test.AssertNoError(t, e)
+	test.AssertEqual(t, "work", l.Title)
+	// lists of others are not found
+	_, e = service.owned("u2", "1")
+	test.AssertEqual(t, ErrListNotFound, e)
+	_, e = service.owned("u1", "2")
+	test.AssertEqual(t, ErrListNotFound, e)
+}
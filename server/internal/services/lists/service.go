@@ -0,0 +1,40 @@
+package lists
+
+import (
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+)
+
+// a named group of users followed, read as a timeline
+type List struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	RepliesPolicy string `json:"repliesPolicy"` // which replies by members are shown: followed, list or none
+}
+
+// service
+
+type ListDbs struct {
+	List          models.IList
+	Member        models.IListMember
+	TimelineCache models.IListTimeline
+}
+
+type ListService struct {
+	lg   logging.Logger
+	db   ListDbs
+	user *users.UserService
+	post *posts.PostService
+}
+
+func NewService(us *users.UserService, ps *posts.PostService, dbs ListDbs, cfg config.Config, lg logging.Logger) *ListService {
+	return &ListService{
+		lg:   lg,
+		db:   dbs,
+		user: us,
+		post: ps,
+	}
+}
@@ -0,0 +1,8 @@
+package notifications
+
+import "errors"
+
+var ErrUserNotFound = errors.New("UserNotFound")
+var ErrType = errors.New("Type")
+var ErrCursor = errors.New("Cursor")
+var ErrInternal = errors.New("Internal")
@@ -0,0 +1,199 @@
+package notifications
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+)
+
+const notification_page = 40
+
+// likes and shares are collapsed by post, follows are collapsed together
+func groupKey(n *models.Notification) string {
+	switch n.Type {
+	case models.Ntf_LIKE, models.Ntf_SHARE:
+		return string(n.Type) + ":" + n.Post
+	case models.Ntf_FOLLOW:
+		return string(n.Type)
+	default:
+		return strconv.FormatInt(n.ID, 10)
+	}
+}
+
+// collapse notifications descending by id into groups, keeping the order
+func group(list []*models.Notification) (groups [][]*models.Notification) {
+	index := make(map[string]int)
+	for _, n := range list {
+		k := groupKey(n)
+		if i, ok := index[k]; ok {
+			groups[i] = append(groups[i], n)
+			continue
+		}
+		index[k] = len(groups)
+		groups = append(groups, []*models.Notification{n})
+	}
+	return groups
+}
+
+func parseTypes(types string) (list []models.NtfType, ok bool) {
+	list = make([]models.NtfType, 0)
+	if types == "" {
+		return list, true
+	}
+	for _, v := range strings.Split(types, ",") {
+		t, ok := models.GetNtfType(strings.TrimSpace(v))
+		if !ok {
+			return nil, false
+		}
+		list = append(list, t)
+	}
+	return list, true
+}
+
+// posts not hidden by filters of the user. notifications of posts hidden are dropped
+func (service *NotificationService) filtered(username string, ps map[string]*posts.Post) map[string]*posts.Post {
+	list := make([]*posts.Post, 0, len(ps))
+	for _, p := range ps {
+		list = append(list, p)
+	}
+	m := make(map[string]*posts.Post)
+	for _, p := range service.post.ApplyFilters(username, models.Flc_NOTIFICATIONS, list) {
+		m[p.ID] = p
+	}
+	return m
+}
+
+func (service *NotificationService) makeGroups(username string, groups [][]*models.Notification) (list []*Group) {
+	logger := service.lg
+	ids := make([]string, 0)
+	for _, g := range groups {
+		if g[0].Post != "" {
+			ids = append(ids, g[0].Post)
+		}
+	}
+	ps := service.filtered(username, service.post.GetByIDs(username, ids))
+
+	us := make(map[string]*users.UserInfo)
+	gu := func(u string) *users.UserInfo {
+		if us[u] != nil {
+			return us[u]
+		}
+		ui, e := service.user.GetInfo(u)
+		if e != nil {
+			msg := fmt.Sprintf("[Notifications] Cannot get info of %s", u)
+			logger.Error(msg, e)
+			return nil
+		}
+		us[u] = &ui
+		return &ui
+	}
+
+	list = make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		first := g[0]
+		gr := Group{
+			ID:     strconv.FormatInt(first.ID, 10),
+			Type:   string(first.Type),
+			Actors: make([]*users.UserInfo, 0, len(g)),
+			Date:   first.Date.Format(time.RFC3339),
+			Read:   true,
+		}
+		if first.Post != "" {
+			gr.Post = ps[first.Post]
+			// post removed, no more permitted or hidden by filters
+			if gr.Post == nil {
+				continue
+			}
+		}
+		seen := make(map[string]bool)
+		for _, n := range g {
+			if !n.Read {
+				gr.Read = false
+			}
+			if seen[n.Actor] {
+				continue
+			}
+			seen[n.Actor] = true
+			if u := gu(n.Actor); u != nil {
+				gr.Actors = append(gr.Actors, u)
+			}
+		}
+		gr.Count = len(gr.Actors)
+		if gr.Count == 0 {
+			continue
+		}
+		list = append(list, &gr)
+	}
+	return list
+}
+
+// render a single notification. nil if the post is removed or not permitted
+func (service *NotificationService) Render(username string, n *models.Notification) *Group {
+	list := service.makeGroups(username, [][]*models.Notification{{n}})
+	if len(list) == 0 {
+		return nil
+	}
+	return list[0]
+}
+
+// types: comma-separated. empty means all types
+func (service *NotificationService) Get(username, from, types string) (page Page, err error) {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return page, ErrUserNotFound
+	}
+	ts, ok := parseTypes(types)
+	if !ok {
+		return page, ErrType
+	}
+	var before int64 = 0
+	if from != "" {
+		before, err = strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			return page, ErrCursor
+		}
+	}
+
+	list, e := service.db.Notification.QueryNotifications(username, ts, before, notification_page)
+	if e != nil {
+		msg := fmt.Sprintf("[Notifications] Cannot get notifications of %s", username)
+		logger.Error(msg, e)
+		return page, ErrInternal
+	}
+
+	page.List = service.makeGroups(username, group(list))
+	if len(list) != 0 {
+		page.Next = strconv.FormatInt(list[len(list)-1].ID, 10)
+	}
+	return page, nil
+}
+
+// mark notifications up to the id as read
+func (service *NotificationService) MarkRead(username, id string) error {
+	logger := service.lg
+	upTo, e := strconv.ParseInt(id, 10, 64)
+	if e != nil {
+		return ErrCursor
+	}
+	if e := service.db.Notification.MarkNotificationsRead(username, upTo); e != nil {
+		msg := fmt.Sprintf("[Notifications] Cannot mark notifications of %s", username)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	return nil
+}
+
+func (service *NotificationService) Clear(username string) error {
+	logger := service.lg
+	if e := service.db.Notification.ClearNotifications(username); e != nil {
+		msg := fmt.Sprintf("[Notifications] Cannot clear notifications of %s", username)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestGroup(t *testing.T) {
+	ns := []*models.Notification{
+		{ID: 7, Type: models.Ntf_LIKE, Actor: "a", Post: "p1"},
+		{ID: 6, Type: models.Ntf_FOLLOW, Actor: "b"},
+		{ID: 5, Type: models.Ntf_LIKE, Actor: "b", Post: "p1"},
+		{ID: 4, Type: models.Ntf_REPLY, Actor: "c", Post: "p3"},
+		{ID: 3, Type: models.Ntf_LIKE, Actor: "c", Post: "p2"},
+		{ID: 2, Type: models.Ntf_REPLY, Actor: "c", Post: "p4"},
+		{ID: 1, Type: models.Ntf_FOLLOW, Actor: "c"},
+	}
+	want := [][]int64{{7, 5}, {6, 1}, {4}, {3}, {2}}
+
+	got := make([][]int64, 0)
+	for _, g := range group(ns) {
+		ids := make([]int64, 0, len(g))
+		for _, n := range g {
+			ids = append(ids, n.ID)
+		}
+		got = append(got, ids)
+	}
+	test.AssertEqual(t, want, got)
+}
+
+func TestParseTypes(t *testing.T) {
+	got, ok := parseTypes("like, share")
+	if !ok {
+		t.Fatal("Cannot parse types")
+	}
+	test.AssertEqual(t, []models.NtfType{models.Ntf_LIKE, models.Ntf_SHARE}, got)
+
+	if _, ok := parseTypes("like,foo"); ok {
+		t.Error("Parsed a wrong type")
+	}
+}
@@ -0,0 +1,47 @@
+package notifications
+
+import (
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+)
+
+// notifications collapsed. e.g. "3 people liked your post"
+type Group struct {
+	ID     string            `json:"id"` // id of the newest notification in group
+	Type   string            `json:"type"`
+	Actors []*users.UserInfo `json:"actors"`
+	Count  int               `json:"count"`
+	Post   *posts.Post       `json:"post,omitempty"`
+	Date   string            `json:"date"` // date of the newest
+	Read   bool              `json:"read"` // false if any is unread
+}
+
+type Page struct {
+	List []*Group `json:"list"`
+	Next string   `json:"next,omitempty"` // pass as "from" to get the next page
+}
+
+// service
+
+type NotificationDbs struct {
+	Notification models.INotification
+}
+
+type NotificationService struct {
+	lg   logging.Logger
+	db   NotificationDbs
+	user *users.UserService
+	post *posts.PostService
+}
+
+func NewService(us *users.UserService, ps *posts.PostService, dbs NotificationDbs, cfg config.Config, lg logging.Logger) *NotificationService {
+	return &NotificationService{
+		lg:   lg,
+		db:   dbs,
+		user: us,
+		post: ps,
+	}
+}
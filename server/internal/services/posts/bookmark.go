@@ -0,0 +1,95 @@
+package posts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// bookmark a post permitted to the user. bookmarks are private
+//
+// ERRORS
+//
+//   - PostNotFound
+//   - NotPermitted
+//   - Internal
+func (service *PostService) Bookmark(username, postID string) error {
+	logger := service.lg
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Bookmark] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	if !service.checkPermission(username, p.User, p.ID, p.Vsb) {
+		return ErrNotPermitted
+	}
+
+	if e := service.db.Bookmark.SetBookmark(username, postID, time.Now()); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Bookmark] Cannot have %s bookmark %s", username, postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - BookmarkNotFound
+//   - Internal
+func (service *PostService) Unbookmark(username, postID string) error {
+	logger := service.lg
+	if e := service.db.Bookmark.RemoveBookmark(username, postID); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrBookmarkNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Bookmark] Cannot remove bookmark of %s to %s", username, postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// posts bookmarked by the user, descending by date of bookmarking.
+// posts no more permitted are absent
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - Internal
+func (service *PostService) GetBookmarks(username string, page utils.Page) (list []*Post, links utils.PageLinks, err error) {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return nil, links, ErrUserNotFound
+	}
+	posts, e := service.db.Bookmark.QueryBookmarks(username, page)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Bookmark] Cannot get bookmarks of %s", username)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+	cursors := make([]utils.Cursor, 0, len(posts))
+	items := make([]models.TimelineItem, 0, len(posts))
+	ps := make(map[string]*models.Post)
+	for _, p := range posts {
+		cursors = append(cursors, p.Cursor())
+		items = append(items, toTimelineItem(p))
+		ps[p.ID] = p
+	}
+
+	return service.makeTimeline(username, items, ps), page.Links(cursors), nil
+}
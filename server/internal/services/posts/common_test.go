@@ -0,0 +1,296 @@
+package posts
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/test"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// posts, timelines and users in memory. parts of posts are mocked in the
+// tests of each part. methods not implemented panic, as the tests shouldn't reach them
+type mockingDb struct {
+	models.IPostQuery
+	models.IPostSet
+	models.IPostTimeline
+	models.ITimeline
+	models.IListMember
+	models.IListTimeline
+	models.INotification
+	models.IStream
+	models.IUserAccount
+	models.IUserInfo
+	models.IUserFollow
+	models.IUserMute
+
+	posts     map[string]*models.Post
+	users     map[string]bool
+	follows   map[string]bool // "from>to"
+	home      map[string][]*models.Post
+	timelines map[string][]models.TimelineItem // cached
+
+	failSet    error         // returned by SetPost when set
+	publicArgs []interface{} // of the last QueryPublicTimeline
+	notified   []*models.Notification
+}
+
+func newMockingDb(usernames ...string) *mockingDb {
+	db := &mockingDb{
+		posts:     make(map[string]*models.Post),
+		users:     make(map[string]bool),
+		follows:   make(map[string]bool),
+		home:      make(map[string][]*models.Post),
+		timelines: make(map[string][]models.TimelineItem),
+	}
+	for _, u := range usernames {
+		db.users[u] = true
+	}
+	return db
+}
+
+func newTestService(t *testing.T, db *mockingDb) *PostService {
+	logger := test.NewMockingLogger(t)
+	us := users.NewService(users.UserDbs{
+		Account: db, Info: db, Follow: db, Mute: db,
+	}, config.Config{Site: "https://example.com"}, logger)
+	return NewService(us, PostDbs{
+		Query: db, Set: db,
+		Timeline: db, TimelineCache: db,
+		List: db, ListTimelineCache: db,
+		Notification: db, Stream: db,
+		// parts saved or read along with posts. tests of a part replace its mock
+		Mention: newMockingMentionDb(), Tag: mockingTagDb{}, TagFollow: mockingTagDb{},
+		Link: newMockingLinkDb(),
+		Poll: &mockingPollDb{}, Viewer: mockingViewerDb{},
+		Reaction: mockingReactionDb{}, Filter: mockingFilterDb{},
+		Conversation: &mockingConversationDb{db: db},
+	}, config.Config{
+		Site: "https://example.com", MaxContentLength: 500,
+		MaxImgInPost: 4, TimelineLength: 100,
+	}, logger)
+}
+
+// public unless vsb is set
+func (db *mockingDb) addPost(p *models.Post) *models.Post {
+	db.posts[p.ID] = p
+	return p
+}
+
+// posts
+
+func (db *mockingDb) IsPostExist(id string) bool {
+	return db.posts[id] != nil
+}
+
+func (db *mockingDb) QueryPostByID(id string) (models.Post, error) {
+	if db.posts[id] == nil {
+		return models.Post{}, models.ErrNotFound
+	}
+	return *db.posts[id], nil
+}
+
+func (db *mockingDb) QueryPostsByIDs(ids []string) ([]*models.Post, error) {
+	list := make([]*models.Post, 0, len(ids))
+	for _, id := range ids {
+		if p := db.posts[id]; p != nil {
+			c := *p
+			list = append(list, &c)
+		}
+	}
+	return list, nil
+}
+
+func (db *mockingDb) SetPost(p *models.Post, attachments []models.Img) error {
+	if db.failSet != nil {
+		return db.failSet
+	}
+	if db.posts[p.ID] != nil {
+		return models.ErrDunplicate
+	}
+	if p.Replying != "" && db.posts[p.Replying] == nil {
+		return models.ErrNotFound
+	}
+	c := *p
+	db.posts[p.ID] = &c
+	return nil
+}
+
+func (db *mockingDb) RemovePost(id string) error {
+	if db.posts[id] == nil {
+		return models.ErrNotFound
+	}
+	delete(db.posts, id)
+	return nil
+}
+
+// the post of the author just saved, by the order of posting
+func (db *mockingDb) lastPostOf(author string) *models.Post {
+	var last *models.Post
+	for _, p := range db.posts {
+		if p.User == author && (last == nil || !p.Date.Before(last.Date)) {
+			last = p
+		}
+	}
+	return last
+}
+
+// timelines
+
+// before (date, id), where dates are in milliseconds as the cache and main database compare
+func isBefore(item models.TimelineItem, before time.Time, beforeID string) bool {
+	if before.IsZero() {
+		return true
+	}
+	date := item.Date.Truncate(time.Millisecond)
+	return date.Before(before) || (date.Equal(before) && item.ID() < beforeID)
+}
+
+// descending, as the main database orders
+func (db *mockingDb) QueryHomeTimeline(user string, before time.Time, beforeID string, limit int) ([]*models.Post, error) {
+	list := make([]*models.Post, 0)
+	for _, p := range db.home[user] {
+		if isBefore(toTimelineItem(p), before, beforeID) {
+			list = append(list, p)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := itemCursor(toTimelineItem(list[i])), itemCursor(toTimelineItem(list[j]))
+		if a.date.Equal(b.date) {
+			return a.id > b.id
+		}
+		return a.date.After(b.date)
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (db *mockingDb) QueryPublicTimeline(viewer string, local bool, before time.Time, beforeID string, limit int) ([]*models.Post, error) {
+	db.publicArgs = []interface{}{viewer, local, before, beforeID, limit}
+	list := make([]*models.Post, 0)
+	for _, p := range db.posts {
+		if p.Vsb != utils.Vsb_PUBLIC || p.Replying != "" {
+			continue
+		}
+		if local && strings.Contains(p.User, "@") {
+			continue
+		}
+		if !before.IsZero() && !(p.Date.Before(before) || (p.Date.Equal(before) && p.ID < beforeID)) {
+			continue
+		}
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Date.Equal(list[j].Date) {
+			return list[i].ID > list[j].ID
+		}
+		return list[i].Date.After(list[j].Date)
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (db *mockingDb) QueryTimeline(user string, before time.Time, beforeID string, count int) ([]models.TimelineItem, error) {
+	items, ok := db.timelines[user]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	list := make([]models.TimelineItem, 0)
+	for _, v := range items {
+		if isBefore(v, before, beforeID) && len(list) < count {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+func (db *mockingDb) SetTimeline(user string, items []models.TimelineItem) error {
+	db.timelines[user] = items
+	return nil
+}
+
+// cached timelines only, and an item once
+func (db *mockingDb) PushTimeline(users []string, item models.TimelineItem, max int) error {
+	for _, u := range users {
+		items, ok := db.timelines[u]
+		if !ok {
+			continue
+		}
+		pushed := false
+		for _, v := range items {
+			pushed = pushed || v.PostID == item.PostID
+		}
+		if !pushed {
+			db.timelines[u] = append([]models.TimelineItem{item}, items...)
+		}
+	}
+	return nil
+}
+
+func (db *mockingDb) QueryListsOfMember(member, replyTo string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (db *mockingDb) PushListTimeline(ids []string, item models.TimelineItem, max int) error {
+	return nil
+}
+
+// notifications and streams
+
+func (db *mockingDb) SetNotification(n *models.Notification) error {
+	db.notified = append(db.notified, n)
+	return nil
+}
+
+func (db *mockingDb) Publish(streams []string, e models.StreamEvent) error {
+	return nil
+}
+
+// users
+
+func (db *mockingDb) QueryUserPreferences(username string) (*models.Preferences, error) {
+	if !db.users[username] {
+		return nil, models.ErrNotFound
+	}
+	return &models.Preferences{PostVsb: "public", ShareVsb: "public"}, nil
+}
+
+func (db *mockingDb) IsUserExist(username string) bool {
+	return db.users[username]
+}
+
+func (db *mockingDb) QueryUser(username string) (models.User, error) {
+	if !db.users[username] && !strings.Contains(username, "@") {
+		return models.User{}, models.ErrNotFound
+	}
+	return models.User{Username: username, Nickname: username}, nil
+}
+
+func (db *mockingDb) IsFollowing(username, target string) bool {
+	return db.follows[username+">"+target]
+}
+
+func (db *mockingDb) QueryLocalFollowers(username string) ([]string, error) {
+	list := make([]string, 0)
+	for k := range db.follows {
+		fields := strings.SplitN(k, ">", 2)
+		if fields[1] == username && !strings.Contains(fields[0], "@") {
+			list = append(list, fields[0])
+		}
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+func (db *mockingDb) IsMuting(username, target string) bool {
+	return false
+}
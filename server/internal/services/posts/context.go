@@ -0,0 +1,148 @@
+package posts
+
+import (
+	"fmt"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+const (
+	context_max_ancestors = 40
+	context_max_depth     = 20
+)
+
+// posts around a post in its thread
+type Context struct {
+	Ancestors   []*Post `json:"ancestors"`   // from the farthest
+	Descendants []*Post `json:"descendants"` // descending by date
+}
+
+// depth <= 0 means the max. depth is clamped to the max
+func depthOf(depth int) int {
+	if depth <= 0 || depth > context_max_depth {
+		return context_max_depth
+	}
+	return depth
+}
+
+// ancestors and a page of descendants of a post permitted to the user.
+// descendants deeper than maxDepth are absent, and so are replies to posts not permitted
+//
+// ERRORS
+//
+//   - PostNotFound
+//   - NotPermitted
+//   - Internal
+func (service *PostService) GetContext(username, postID string, maxDepth int, page utils.Page) (ctx Context, links utils.PageLinks, err error) {
+	logger := service.lg
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ctx, links, ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Context] Cannot get post %s", postID)
+			logger.Error(msg, e)
+			return ctx, links, ErrInternal
+		}
+	}
+	if !service.checkPermission(username, p.User, p.ID, p.Vsb) {
+		return ctx, links, ErrNotPermitted
+	}
+
+	as, e := service.db.Query.QueryPostAncestors(postID, context_max_ancestors)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ctx, links, ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Context] Cannot get ancestors of %s", postID)
+			logger.Error(msg, e)
+			return ctx, links, ErrInternal
+		}
+	}
+	ds, e := service.db.Query.QueryPostDescendants(postID, depthOf(maxDepth), page)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ctx, links, ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Context] Cannot get descendants of %s", postID)
+			logger.Error(msg, e)
+			return ctx, links, ErrInternal
+		}
+	}
+	// pages are counted before filtering
+	cursors := make([]utils.Cursor, 0, len(ds))
+	for _, v := range ds {
+		cursors = append(cursors, v.Cursor())
+	}
+
+	ms := service.matchers(username, models.Flc_THREAD)
+	ctx.Ancestors = applyFilters(username, ms, service.makeThread(username, as))
+	ctx.Descendants = applyFilters(username, ms, service.makeThread(username, service.unguarded(username, ds)))
+	return ctx, page.Links(cursors), nil
+}
+
+// descendants whose non-public posts on the way down are permitted to the user
+func (service *PostService) unguarded(username string, list []*models.Post) []*models.Post {
+	logger := service.lg
+	known := make(map[string]*models.Post)
+	for _, v := range list {
+		known[v.ID] = v
+	}
+	missing := make([]string, 0)
+	for _, v := range list {
+		for _, g := range v.Guarded {
+			if known[g] == nil {
+				known[g] = &models.Post{} // placeholder until fetched
+				missing = append(missing, g)
+			}
+		}
+	}
+	if len(missing) != 0 {
+		fetched, e := service.db.Query.QueryPostsByIDs(missing)
+		if e != nil {
+			logger.Error("[Posts.Context] Cannot get posts guarding descendants", e)
+			return make([]*models.Post, 0)
+		}
+		for _, v := range fetched {
+			known[v.ID] = v
+		}
+	}
+
+	permitted := make(map[string]bool)
+	result := make([]*models.Post, 0, len(list))
+	for _, v := range list {
+		ok := true
+		for _, g := range v.Guarded {
+			p, checked := permitted[g]
+			if !checked {
+				gp := known[g]
+				// removed meanwhile if still a placeholder
+				p = gp.ID != "" && service.checkPermission(username, gp.User, gp.ID, gp.Vsb)
+				permitted[g] = p
+			}
+			if !p {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// make posts in a thread in order, dropping ones not permitted
+func (service *PostService) makeThread(username string, list []*models.Post) []*Post {
+	items := make([]models.TimelineItem, 0, len(list))
+	ps := make(map[string]*models.Post)
+	for _, v := range list {
+		items = append(items, models.TimelineItem{PostID: v.ID})
+		ps[v.ID] = v
+	}
+	return service.makeTimeline(username, items, ps)
+}
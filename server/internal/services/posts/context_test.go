@@ -0,0 +1,23 @@
+package posts
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestDepthOf(t *testing.T) {
+	cases := []struct {
+		depth int
+		want  int
+	}{
+		{0, context_max_depth},
+		{-1, context_max_depth},
+		{1, 1},
+		{context_max_depth, context_max_depth},
+		{context_max_depth + 1, context_max_depth},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, depthOf(v.depth))
+	}
+}
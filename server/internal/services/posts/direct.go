@@ -0,0 +1,74 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kidommoc/gustrody/internal/models"
+)
+
+// recipients of a direct post: ones given, users mentioned, and participants of the post replied.
+// the author and users not found are excluded
+func (service *PostService) recipientsOf(username, content string, given []string, replying *models.Post) []string {
+	candidates := make([]string, 0)
+	if replying != nil {
+		candidates = append(candidates, replying.User)
+		candidates = append(candidates, replying.Recipients...)
+	}
+	candidates = append(candidates, given...)
+	candidates = append(candidates, service.resolveMentions(content)...)
+
+	list := make([]string, 0, len(candidates))
+	seen := map[string]bool{username: true}
+	for _, u := range candidates {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		if !service.user.IsUserExist(u) {
+			continue
+		}
+		list = append(list, u)
+	}
+	return list
+}
+
+// whether the user is the author or a recipient of the direct post
+func (service *PostService) isParticipant(user, target, postID string) bool {
+	if user == "" {
+		return false
+	}
+	if user == target {
+		return true
+	}
+	return postID != "" && service.db.Query.IsPostRecipient(postID, user)
+}
+
+// author and recipients of this site
+func localParticipants(username string, recipients []string) []string {
+	list := []string{username}
+	for _, u := range recipients {
+		if !strings.Contains(u, "@") {
+			list = append(list, u)
+		}
+	}
+	return list
+}
+
+// put a direct post saved into a conversation: the one of the post replied if any,
+// otherwise the one of its participants
+func (service *PostService) joinConversation(p *models.Post, replying *models.Post) error {
+	logger := service.lg
+	var id int64 = 0
+	if replying != nil {
+		id = replying.Conversation
+	}
+	id, e := service.db.Conversation.SetConversationPost(id, p.ID, p.User, p.Recipients, p.Date)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Direct] Cannot put %s into conversation", p.ID)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	p.Conversation = id
+	return nil
+}
@@ -0,0 +1,133 @@
+package posts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+func (db *mockingDb) IsPostRecipient(id, user string) bool {
+	p := db.posts[id]
+	if p == nil {
+		return false
+	}
+	for _, v := range p.Recipients {
+		if v == user {
+			return true
+		}
+	}
+	return false
+}
+
+// conversations. a new one is numbered 1
+type mockingConversationDb struct {
+	models.IConversation
+	db       *mockingDb
+	failJoin error // returned by SetConversationPost when set
+	joined   []joining
+}
+
+// a call of SetConversationPost
+type joining struct {
+	id           int64
+	post         string
+	author       string
+	participants []string
+}
+
+func (conv *mockingConversationDb) SetConversationPost(id int64, post, author string, participants []string, date time.Time) (int64, error) {
+	conv.joined = append(conv.joined, joining{id, post, author, participants})
+	if conv.failJoin != nil {
+		return 0, conv.failJoin
+	}
+	if conv.db.posts[post] == nil {
+		return 0, models.ErrNotFound
+	}
+	if id == 0 {
+		id = 1
+	}
+	conv.db.posts[post].Conversation = id
+	return id, nil
+}
+
+func TestRecipientsOf(t *testing.T) {
+	db := newMockingDb("u1", "u2", "u3", "u4", "r@remote.example")
+	service := newTestService(t, db)
+	replying := &models.Post{ID: "d", User: "u2", Recipients: []string{"u1", "u3", "r@remote.example"}}
+
+	// participants of the post replied first, then ones given and users mentioned.
+	// the author and users not found are excluded
+	list := service.recipientsOf("u1", "hi @u4 @nobody @u1 @u2", []string{"u3", "x"}, replying)
+	test.AssertEqual(t, []string{"u2", "u3", "r@remote.example", "u4"}, list)
+
+	list = service.recipientsOf("u1", "hi", []string{"u1"}, nil)
+	test.AssertEqual(t, []string{}, list)
+}
+
+func TestNewDirectJoinsConversation(t *testing.T) {
+	db := newMockingDb("u1", "u2", "u3", "r@remote.example")
+	service := newTestService(t, db)
+	conv := &mockingConversationDb{db: db}
+	service.db.Conversation = conv
+
+	err := service.New("u1", "direct", "hi @u2", nil, []string{"u3", "r@remote.example"}, Options{})
+	test.AssertNoError(t, err)
+	p := db.lastPostOf("u1")
+	test.AssertEqual(t, utils.Vsb_DIRECT, p.Vsb)
+	test.AssertEqual(t, []joining{
+		{0, p.ID, "u1", []string{"u3", "r@remote.example", "u2"}},
+	}, conv.joined)
+	test.AssertEqual(t, int64(1), p.Conversation)
+
+	// participants are not bumped for posts failed to save
+	db.failSet = models.ErrDbInternal
+	err = service.New("u1", "direct", "again @u2", nil, nil, Options{})
+	test.AssertEqual(t, ErrInternal, err)
+	test.AssertEqual(t, 1, len(conv.joined))
+
+	// nor posts saved if the conversation can't be joined
+	db.failSet = nil
+	conv.failJoin = models.ErrDbInternal
+	err = service.New("u1", "direct", "once more @u2", nil, nil, Options{})
+	test.AssertEqual(t, ErrInternal, err)
+	test.AssertEqual(t, 2, len(conv.joined))
+	test.AssertEqual(t, 1, len(db.posts))
+
+	err = service.New("u1", "direct", "to nobody", nil, nil, Options{})
+	test.AssertEqual(t, ErrNoRecipient, err)
+	test.AssertEqual(t, 2, len(conv.joined))
+}
+
+func TestReplyDirectJoinsConversation(t *testing.T) {
+	db := newMockingDb("u1", "u2", "u3")
+	service := newTestService(t, db)
+	conv := &mockingConversationDb{db: db}
+	service.db.Conversation = conv
+	now := time.Now()
+	db.addPost(&models.Post{
+		ID: "d", User: "u1", Vsb: utils.Vsb_DIRECT, Date: now.Add(-time.Hour),
+		Recipients: []string{"u2"}, Conversation: 5,
+	})
+	db.addPost(&models.Post{ID: "p", User: "u1", Date: now.Add(-time.Hour)})
+
+	// the conversation replied, bringing in users mentioned
+	err := service.Reply("u2", "d", "", "thanks @u3", nil, nil, Options{})
+	test.AssertNoError(t, err)
+	r := db.lastPostOf("u2")
+	test.AssertEqual(t, utils.Vsb_DIRECT, r.Vsb)
+	test.AssertEqual(t, []joining{{5, r.ID, "u2", []string{"u1", "u3"}}}, conv.joined)
+
+	// a direct reply to a post not direct starts its own
+	err = service.Reply("u3", "p", "direct", "psst", nil, nil, Options{})
+	test.AssertNoError(t, err)
+	r = db.lastPostOf("u3")
+	test.AssertEqual(t, joining{0, r.ID, "u3", []string{"u1"}}, conv.joined[1])
+
+	// users not participating cannot reply
+	err = service.Reply("u3", "d", "", "hey", nil, nil, Options{})
+	test.AssertEqual(t, ErrNotPermitted, err)
+	test.AssertEqual(t, 2, len(conv.joined))
+}
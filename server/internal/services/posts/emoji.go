@@ -0,0 +1,45 @@
+package posts
+
+import (
+	"github.com/kidommoc/gustrody/internal/services/emojis"
+)
+
+// set custom emoji in spoilers and contents of posts in bulk.
+// emoji of a post are of the site of its author
+func (service *PostService) setEmojis(list []*Post) {
+	logger := service.lg
+	texts := make(map[string][]string)
+	for _, p := range list {
+		if p.User == nil {
+			continue
+		}
+		d := emojis.DomainOf(p.User.Username)
+		texts[d] = append(texts[d], p.Spoiler, p.Content)
+	}
+	m := make(map[string]map[string]*emojis.Emoji)
+	for d, ts := range texts {
+		result, e := emojis.Resolve(service.db.Emoji, d, ts...)
+		if e != nil {
+			logger.Error("[Posts.Emoji] Cannot get custom emoji", e)
+			continue
+		}
+		m[d] = make(map[string]*emojis.Emoji)
+		for _, v := range result {
+			m[d][v.Shortcode] = v
+		}
+	}
+	for _, p := range list {
+		if p.User == nil {
+			continue
+		}
+		found := m[emojis.DomainOf(p.User.Username)]
+		if len(found) == 0 {
+			continue
+		}
+		for _, c := range emojis.ParseShortcodes(p.Spoiler, p.Content) {
+			if v := found[c]; v != nil {
+				p.Emojis = append(p.Emojis, v)
+			}
+		}
+	}
+}
@@ -10,4 +10,23 @@ var ErrContentTooLong = errors.New("ContentTooLong")
 var ErrContentEmpty = errors.New("ContentEmpty")
 var ErrOwner = errors.New("Owner")
 var ErrNotPermitted = errors.New("NotPermitted")
+var ErrNoRecipient = errors.New("NoRecipient")
+var ErrCursor = errors.New("Cursor")
+var ErrTag = errors.New("Tag")
+var ErrTagFollowNotFound = errors.New("TagFollowNotFound")
+var ErrPoll = errors.New("Poll")
+var ErrPollNotFound = errors.New("PollNotFound")
+var ErrPollExpired = errors.New("PollExpired")
+var ErrChoice = errors.New("Choice")
+var ErrVoted = errors.New("Voted")
+var ErrPostExist = errors.New("PostExist")
+var ErrSchedule = errors.New("Schedule")
+var ErrScheduledNotFound = errors.New("ScheduledNotFound")
+var ErrBookmarkNotFound = errors.New("BookmarkNotFound")
+var ErrNotQuotable = errors.New("NotQuotable")
+var ErrEmoji = errors.New("Emoji")
+var ErrReactionNotFound = errors.New("ReactionNotFound")
+var ErrReplyPolicy = errors.New("ReplyPolicy")
+var ErrReplyNotAllowed = errors.New("ReplyNotAllowed")
+var ErrQuery = errors.New("Query")
 var ErrInternal = errors.New("Internal")
@@ -0,0 +1,133 @@
+package posts
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kidommoc/gustrody/internal/models"
+)
+
+// a filter with action warn matching a post
+type Filtered struct {
+	ID     string `json:"id"`
+	Phrase string `json:"phrase"`
+}
+
+type matcher struct {
+	filter *models.Filter
+	re     *regexp.Regexp
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// text of the rendered content without markups
+func plainText(content string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(content, " "))
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// case insensitive. when whole word, the phrase can't be a part of a longer word.
+// ends of the phrase not of a word are not bounded
+func compileFilter(f *models.Filter) matcher {
+	phrase := strings.TrimSpace(f.Phrase)
+	pattern := regexp.QuoteMeta(phrase)
+	if f.WholeWord && phrase != "" {
+		rs := []rune(phrase)
+		// \b is ascii only. look at the runes around instead
+		if isWordRune(rs[0]) {
+			pattern = `(?:^|[^\p{L}\p{N}_])` + pattern
+		}
+		if isWordRune(rs[len(rs)-1]) {
+			pattern = pattern + `(?:$|[^\p{L}\p{N}_])`
+		}
+	}
+	return matcher{filter: f, re: regexp.MustCompile(`(?i)` + pattern)}
+}
+
+// content warning, content and descriptions of media
+func textOf(p *Post) string {
+	parts := []string{p.Spoiler, plainText(p.Content)}
+	for _, v := range p.Attachments {
+		parts = append(parts, v.Alt)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// drop posts matching filters of hide, and annotate ones matching filters of warn.
+// posts of the user are not filtered
+func applyFilters(username string, ms []matcher, list []*Post) []*Post {
+	if len(ms) == 0 {
+		return list
+	}
+	result := make([]*Post, 0, len(list))
+	for _, p := range list {
+		if p.User != nil && p.User.Username == username {
+			result = append(result, p)
+			continue
+		}
+		text := textOf(p)
+		hidden := false
+		for _, m := range ms {
+			if !m.re.MatchString(text) {
+				continue
+			}
+			if m.filter.Action == models.Fla_HIDE {
+				hidden = true
+				break
+			}
+			p.Filtered = append(p.Filtered, &Filtered{ID: m.filter.ID, Phrase: m.filter.Phrase})
+		}
+		if !hidden {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// filters of the user in the context, not expired
+func (service *PostService) matchers(username string, context models.FilterContext) []matcher {
+	logger := service.lg
+	if username == "" {
+		return nil
+	}
+	fs, e := service.db.Filter.QueryActiveFilters(username, context, time.Now())
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Filter] Cannot get filters of %s", username)
+		logger.Error(msg, e)
+		return nil
+	}
+	ms := make([]matcher, 0, len(fs))
+	for _, f := range fs {
+		ms = append(ms, compileFilter(f))
+	}
+	return ms
+}
+
+// apply filters of the user in the context. posts matching filters of hide are dropped,
+// and ones matching filters of warn are annotated with them
+func (service *PostService) ApplyFilters(username string, context models.FilterContext, list []*Post) []*Post {
+	return applyFilters(username, service.matchers(username, context), list)
+}
+
+// filters of a user in a context compiled, to be applied many times such as to events of streams
+type Filters struct {
+	user string
+	ms   []matcher
+}
+
+// filters of the user in the context, not expired now
+func (service *PostService) GetFilters(username string, context models.FilterContext) *Filters {
+	return &Filters{user: username, ms: service.matchers(username, context)}
+}
+
+// as ApplyFilters
+func (fs *Filters) Apply(list []*Post) []*Post {
+	return applyFilters(fs.user, fs.ms, list)
+}
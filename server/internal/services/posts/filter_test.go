@@ -0,0 +1,76 @@
+package posts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+// no filters
+type mockingFilterDb struct {
+	models.IFilter
+}
+
+func (db mockingFilterDb) QueryActiveFilters(user string, context models.FilterContext, now time.Time) ([]*models.Filter, error) {
+	return []*models.Filter{}, nil
+}
+
+func TestPlainText(t *testing.T) {
+	got := plainText(`<p><a href="x">Tom</a> &amp; <b>Jerry</b></p>`)
+	test.AssertEqual(t, "  Tom  &  Jerry  ", got)
+}
+
+func TestCompileFilter(t *testing.T) {
+	cases := []struct {
+		phrase    string
+		wholeWord bool
+		text      string
+		want      bool
+	}{
+		{"cat", true, "A Cat sat.", true},
+		{"cat", true, "concatenate", false},
+		{"cat", false, "concatenate", true},
+		{"cat", true, "cat_food", false},
+		{"café", true, "le café.", true},
+		{"café", true, "cafés", false},
+		{"#go", true, "learning #golang", false}, // bounded only at the end
+		{"#go", true, "#go!", true},
+		{"猫", true, "黑猫", false},
+		{"猫", false, "黑猫", true},
+		{"a.b", true, "axb", false},
+	}
+	for _, v := range cases {
+		m := compileFilter(&models.Filter{Phrase: v.phrase, WholeWord: v.wholeWord})
+		if m.re.MatchString(v.text) != v.want {
+			t.Errorf("%q (whole word: %t) on %q: want %t", v.phrase, v.wholeWord, v.text, v.want)
+		}
+	}
+}
+
+func TestApplyFilters(t *testing.T) {
+	u1 := &users.UserInfo{Username: "u1"}
+	u2 := &users.UserInfo{Username: "u2"}
+	ms := []matcher{
+		compileFilter(&models.Filter{ID: "1", Phrase: "spoiler", WholeWord: true, Action: models.Fla_WARN}),
+		compileFilter(&models.Filter{ID: "2", Phrase: "crypto", WholeWord: true, Action: models.Fla_HIDE}),
+	}
+	list := []*Post{
+		{ID: "a", User: u2, Content: "<p>no spoiler here</p>"},
+		{ID: "b", User: u2, Content: "<p>buy crypto</p>"},
+		{ID: "c", User: u2, Content: "<p>hello</p>", Attachments: []AttachImg{{Alt: "SPOILER"}}},
+		{ID: "d", User: u1, Content: "<p>my crypto</p>"},
+		{ID: "e", User: u2, Spoiler: "crypto", Content: "<p>hidden by warning</p>"},
+	}
+	result := applyFilters("u1", ms, list)
+	ids := make([]string, 0, len(result))
+	for _, v := range result {
+		ids = append(ids, v.ID)
+	}
+	test.AssertEqual(t, []string{"a", "c", "d"}, ids)
+	test.AssertEqual(t, []*Filtered{{ID: "1", Phrase: "spoiler"}}, result[0].Filtered)
+	test.AssertEqual(t, 1, len(result[1].Filtered))
+	test.AssertEqual(t, 0, len(result[2].Filtered))
+}
@@ -0,0 +1,80 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// a version of a post
+type Revision struct {
+	Spoiler     string      `json:"spoiler,omitempty"`
+	Sensitive   bool        `json:"sensitive"`
+	Content     string      `json:"content"` // html
+	Attachments []AttachImg `json:"attachments"`
+	Date        string      `json:"date"` // when this version was published or edited
+}
+
+// versions of a post permitted to the user, the current first
+//
+// ERRORS
+//
+//   - PostNotFound
+//   - NotPermitted
+//   - Internal
+func (service *PostService) GetHistory(user, postID string) (list []*Revision, err error) {
+	logger := service.lg
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return nil, ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.History] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	if !service.checkPermission(user, p.User, p.ID, p.Vsb) {
+		return nil, ErrNotPermitted
+	}
+
+	rs, e := service.db.Revision.QueryRevisions(postID)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.History] Cannot get revisions of %s", postID)
+		logger.Error(msg, e)
+		return nil, ErrInternal
+	}
+
+	date := p.Date
+	if !p.Edited.IsZero() {
+		date = p.Edited
+	}
+	list = make([]*Revision, 0, len(rs)+1)
+	list = append(list, service.makeRevision(p.User, &models.Revision{
+		Post: p.ID, Content: p.Content, Media: p.Media,
+		Spoiler: p.Spoiler, Sensitive: p.Sensitive, Date: date,
+	}))
+	for _, v := range rs {
+		list = append(list, service.makeRevision(p.User, v))
+	}
+	return list, nil
+}
+
+func (service *PostService) makeRevision(user string, v *models.Revision) *Revision {
+	r := Revision{
+		Spoiler:     v.Spoiler,
+		Sensitive:   v.Sensitive,
+		Content:     v.Content,
+		Attachments: service.makeAttachments(v.Media.Data()),
+		Date:        v.Date.Format(time.RFC3339),
+	}
+	// content of other sites is html of anything
+	if strings.Contains(user, "@") {
+		r.Content = utils.SanitizeHTML(v.Content)
+	}
+	return &r
+}
@@ -0,0 +1,160 @@
+package posts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// posts edited keep their revisions
+type mockingRevisionDb struct {
+	*mockingDb
+	models.IPostRevision
+	revisions map[string][]*models.Revision // newest first
+}
+
+func newMockingRevisionDb(db *mockingDb) *mockingRevisionDb {
+	return &mockingRevisionDb{mockingDb: db, revisions: make(map[string][]*models.Revision)}
+}
+
+// media are kept
+func (db *mockingRevisionDb) UpdatePost(p *models.Post, attachments []models.Img) error {
+	cur := db.posts[p.ID]
+	if cur == nil {
+		return models.ErrNotFound
+	}
+	date := cur.Date
+	if !cur.Edited.IsZero() {
+		date = cur.Edited
+	}
+	db.revisions[p.ID] = append([]*models.Revision{{
+		Post: cur.ID, Content: cur.Content, Source: cur.Source, Media: cur.Media,
+		Spoiler: cur.Spoiler, Sensitive: cur.Sensitive, Date: date,
+	}}, db.revisions[p.ID]...)
+	cur.Edited, cur.Content, cur.Source = p.Edited, p.Content, p.Source
+	cur.Spoiler, cur.Sensitive = p.Spoiler, p.Sensitive
+	cur.Quotable, cur.ReplyPolicy = p.Quotable, p.ReplyPolicy
+	return nil
+}
+
+func (db *mockingRevisionDb) QueryRevisions(id string) ([]*models.Revision, error) {
+	return db.revisions[id], nil
+}
+
+// a service editing posts of db
+func newEditingService(t *testing.T, db *mockingDb) (*PostService, *mockingRevisionDb) {
+	service := newTestService(t, db)
+	rev := newMockingRevisionDb(db)
+	service.db.Set, service.db.Revision = rev, rev
+	return service, rev
+}
+
+func TestEditKeepsHistory(t *testing.T) {
+	db := newMockingDb("u1", "u2")
+	service, rev := newEditingService(t, db)
+	published := time.Now().Add(-time.Hour)
+	db.addPost(&models.Post{
+		ID: "p", User: "u1", Date: published,
+		Content: service.render("v1", nil), Source: "v1",
+		Spoiler: "cw", Sensitive: true,
+	})
+
+	// the current version only, dated when published
+	list, err := service.GetHistory("u1", "p")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 1, len(list))
+	test.AssertEqual(t, published.Format(time.RFC3339), list[0].Date)
+
+	spoiler := "cw2"
+	err = service.Edit("u1", "p", "v2", nil, Options{Spoiler: &spoiler})
+	test.AssertNoError(t, err)
+	edited := db.posts["p"].Edited
+	test.AssertEqual(t, false, edited.IsZero())
+
+	list, err = service.GetHistory("u1", "p")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 2, len(list))
+	test.AssertEqual(t, service.render("v2", nil), list[0].Content)
+	test.AssertEqual(t, "cw2", list[0].Spoiler)
+	test.AssertEqual(t, true, list[0].Sensitive)
+	test.AssertEqual(t, edited.Format(time.RFC3339), list[0].Date)
+	test.AssertEqual(t, service.render("v1", nil), list[1].Content)
+	test.AssertEqual(t, "cw", list[1].Spoiler)
+	test.AssertEqual(t, published.Format(time.RFC3339), list[1].Date)
+
+	// a previous edit is dated when edited, not published
+	db.posts["p"].Edited = edited.Add(-time.Minute)
+	edited = db.posts["p"].Edited
+	err = service.Edit("u1", "p", "v3", nil, Options{})
+	test.AssertNoError(t, err)
+	list, err = service.GetHistory("u1", "p")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 3, len(list))
+	test.AssertEqual(t, edited.Format(time.RFC3339), list[1].Date)
+	test.AssertEqual(t, published.Format(time.RFC3339), list[2].Date)
+
+	// only the author edits
+	err = service.Edit("u2", "p", "v4", nil, Options{})
+	test.AssertEqual(t, ErrOwner, err)
+	test.AssertEqual(t, 2, len(rev.revisions["p"]))
+	err = service.Edit("u1", "x", "v4", nil, Options{})
+	test.AssertEqual(t, ErrPostNotFound, err)
+}
+
+func TestGetHistoryPermission(t *testing.T) {
+	db := newMockingDb("u1", "u2")
+	service, _ := newEditingService(t, db)
+	db.addPost(&models.Post{
+		ID: "f", User: "u1", Vsb: utils.Vsb_FOLLOWER, Date: time.Now(),
+		Content: service.render("v1", nil), Source: "v1",
+	})
+
+	_, err := service.GetHistory("u2", "f")
+	test.AssertEqual(t, ErrNotPermitted, err)
+	_, err = service.GetHistory("", "f")
+	test.AssertEqual(t, ErrNotPermitted, err)
+
+	db.follows["u2>u1"] = true
+	list, err := service.GetHistory("u2", "f")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 1, len(list))
+
+	_, err = service.GetHistory("u2", "x")
+	test.AssertEqual(t, ErrPostNotFound, err)
+}
+
+func TestEditSpoiler(t *testing.T) {
+	db := newMockingDb("u1")
+	service, rev := newEditingService(t, db)
+	db.addPost(&models.Post{
+		ID: "p", User: "u1", Date: time.Now(),
+		Content: service.render("v1", nil), Source: "v1",
+		Spoiler: "cw", Sensitive: true,
+	})
+
+	// kept when absent, as sensitive is
+	err := service.Edit("u1", "p", "v2", nil, Options{})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, "cw", db.posts["p"].Spoiler)
+	test.AssertEqual(t, true, db.posts["p"].Sensitive)
+
+	// and counted in the length
+	err = service.Edit("u1", "p", strings.Repeat("a", 499), nil, Options{})
+	test.AssertEqual(t, ErrContentTooLong, err)
+
+	spoiler := "new"
+	err = service.Edit("u1", "p", "v3", nil, Options{Spoiler: &spoiler})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, "new", db.posts["p"].Spoiler)
+
+	// removed when empty
+	spoiler = ""
+	err = service.Edit("u1", "p", "v4", nil, Options{Spoiler: &spoiler})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, "", db.posts["p"].Spoiler)
+	test.AssertEqual(t, 3, len(rev.revisions["p"]))
+}
@@ -2,59 +2,64 @@ package posts
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kidommoc/gustrody/internal/models"
 	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/utils"
 )
 
-func (service *PostService) GetLikes(user, postID string) (list []*users.UserInfo, err error) {
+func (service *PostService) GetLikes(user, postID string, page utils.Page) (list []*users.UserInfo, links utils.PageLinks, err error) {
 	logger := service.lg
-	result, owner, vsb, e := service.db.Like.QueryLikes(postID)
+	result, owner, vsb, e := service.db.Like.QueryLikes(postID, page)
 	if e != nil && e != models.ErrNotFound {
 		msg := fmt.Sprintf("[Posts.Like] Cannot get likes of %s", postID)
 		logger.Error(msg, e)
-		return nil, ErrInternal
+		return nil, links, ErrInternal
 	}
 
 	if !service.checkPermission(user, owner, postID, vsb) {
-		return nil, ErrNotPermitted
+		return nil, links, ErrNotPermitted
 	}
 
 	if e != nil {
-		return list, ErrPostNotFound
+		return nil, links, ErrPostNotFound
 	}
 
-	us := make(map[string]*users.UserInfo)
-	gu := func(u string) *users.UserInfo {
-		if u == "" {
-			return nil
-		}
-		if us[u] != nil {
-			return us[u]
-		}
-		info, e := service.user.GetInfo(u)
+	list = make([]*users.UserInfo, 0, len(result))
+	cursors := make([]utils.Cursor, 0, len(result))
+	for _, v := range result {
+		cursors = append(cursors, v.Cursor())
+		info, e := service.user.GetInfo(v.User)
 		if e != nil {
-			msg := fmt.Sprintf("[Posts.Like] Cannot get info of %s", u)
+			msg := fmt.Sprintf("[Posts.Like] Cannot get info of %s", v.User)
 			logger.Error(msg, e)
-			return nil
-		}
-		us[u] = &info
-		return &info
-	}
-	list = make([]*users.UserInfo, 0, len(result))
-	for _, u := range result {
-		info := gu(u)
-		if info != nil {
-			list = append(list, info)
+			continue
 		}
+		list = append(list, &info)
 	}
 
-	return list, nil
+	return list, page.Links(cursors), nil
 }
 
 func (service *PostService) Like(username, postID string) error {
 	logger := service.lg
-	if err := service.db.Like.SetLike(username, postID); err != nil {
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Like] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	if !service.checkPermission(username, p.User, p.ID, p.Vsb) {
+		return ErrNotPermitted
+	}
+
+	if err := service.db.Like.SetLike(username, postID, time.Now()); err != nil {
 		switch {
 		case err == models.ErrNotFound:
 			return ErrPostNotFound
@@ -64,6 +69,7 @@ func (service *PostService) Like(username, postID string) error {
 			return ErrInternal
 		}
 	}
+	service.notify(models.Ntf_LIKE, username, p.User, postID)
 	return nil
 }
 
@@ -0,0 +1,38 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// urls linked when the text is rendered, in order and deduplicated
+func parseLinks(text string, mentionUrl func(user string) string) []string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	list := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, l := range findLinks(text, mentionUrl, func(tag string) string { return "" }) {
+		if l.url == "" || seen[l.url] {
+			continue
+		}
+		seen[l.url] = true
+		list = append(list, l.url)
+	}
+	return list
+}
+
+// urls linked in the source of a post of this site, as rendered
+func (service *PostService) linksOf(source string, mentions []string) []string {
+	return parseLinks(source, service.mentionUrls(mentions))
+}
+
+// save urls linked in the post, for trends
+func (service *PostService) saveLinks(postID string, links, old []string) {
+	logger := service.lg
+	if len(links) == 0 && len(old) == 0 {
+		return
+	}
+	if e := service.db.Link.SetLinks(postID, links); e != nil {
+		msg := fmt.Sprintf("[Posts.Link] Cannot save links of %s", postID)
+		logger.Error(msg, e)
+	}
+}
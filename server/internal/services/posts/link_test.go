@@ -0,0 +1,78 @@
+package posts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+type mockingLinkDb struct {
+	models.IPostLink
+	links map[string][]string
+}
+
+func newMockingLinkDb() *mockingLinkDb {
+	return &mockingLinkDb{links: make(map[string][]string)}
+}
+
+func (db *mockingLinkDb) SetLinks(id string, urls []string) error {
+	db.links[id] = urls
+	return nil
+}
+
+func TestParseLinks(t *testing.T) {
+	noMention := func(user string) string { return "" }
+	cases := []struct {
+		content string
+		want    []string
+	}{
+		{"see https://a.example/x, and https://a.example/x.", []string{"https://a.example/x"}},
+		{"https://a.example/#go and http://b.example/?q=1", []string{"https://a.example/#go", "http://b.example/?q=1"}},
+		// not linked when rendered
+		{"#https://a.example ftp://b.example", []string{}},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, parseLinks(v.content, noMention))
+	}
+
+	// inside a mention linked
+	mention := func(user string) string { return "https://example.com/users/" + user }
+	test.AssertEqual(t, []string{}, parseLinks("@ahttps://a.example", mention))
+	test.AssertEqual(t, []string{"https://a.example"}, parseLinks("@ahttps://a.example", noMention))
+}
+
+func TestSaveLinks(t *testing.T) {
+	db := newMockingDb("u1")
+	service, _ := newEditingService(t, db)
+	links := newMockingLinkDb()
+	service.db.Link = links
+
+	err := service.New("u1", "public", "read https://a.example/x", nil, nil, Options{})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 1, len(links.links))
+	for _, v := range links.links {
+		test.AssertEqual(t, []string{"https://a.example/x"}, v)
+	}
+
+	db.addPost(&models.Post{
+		ID: "p", User: "u1", Date: time.Now().Add(-time.Hour),
+		Content: service.render("https://a.example/y", nil), Source: "https://a.example/y",
+	})
+	err = service.Edit("u1", "p", "https://b.example/z", nil, Options{})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"https://b.example/z"}, links.links["p"])
+
+	// links removed are cleared
+	err = service.Edit("u1", "p", "no link", nil, Options{})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{}, links.links["p"])
+
+	// nothing saved for posts never linking
+	delete(links.links, "p")
+	err = service.Edit("u1", "p", "still none", nil, Options{})
+	test.AssertNoError(t, err)
+	_, ok := links.links["p"]
+	test.AssertEqual(t, false, ok)
+}
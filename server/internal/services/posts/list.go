@@ -0,0 +1,81 @@
+package posts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// lists having the actor, into which a post or share fans out. none for direct posts
+func (service *PostService) listsOf(username string, vsb utils.Vsb, replyTo string) []string {
+	logger := service.lg
+	if vsb == utils.Vsb_DIRECT {
+		return nil
+	}
+	ls, e := service.db.List.QueryListsOfMember(username, replyTo)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.List] Cannot get lists of %s", username)
+		logger.Error(msg, e)
+		return nil
+	}
+	return ls
+}
+
+// user replied by the post, empty if not a reply or the post replied is removed
+func (service *PostService) replyToOf(p *models.Post) string {
+	if p.Replying == "" {
+		return ""
+	}
+	rp, e := service.db.Query.QueryPostByID(p.Replying)
+	if e != nil {
+		return ""
+	}
+	return rp.User
+}
+
+func listStreams(ls []string) []string {
+	streams := make([]string, 0, len(ls))
+	for _, v := range ls {
+		streams = append(streams, models.StreamOfList(v))
+	}
+	return streams
+}
+
+// rebuild cached list timeline from main database
+func (service *PostService) rebuildList(listID string) error {
+	logger := service.lg
+	posts, e := service.db.Timeline.QueryListTimeline(listID, time.Time{}, "", service.timelineLength)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.List] Cannot query timeline of list %s", listID)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	items := make([]models.TimelineItem, 0, len(posts))
+	for _, p := range posts {
+		items = append(items, toTimelineItem(p))
+	}
+	if e := service.db.ListTimelineCache.SetListTimeline(listID, items); e != nil {
+		msg := fmt.Sprintf("[Posts.List] Cannot cache timeline of list %s", listID)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	return nil
+}
+
+// timeline of a list, read as the home timeline is.
+// the owner of the list should be checked by the caller
+func (service *PostService) GetListTimeline(username, listID, from string) (tl Timeline, err error) {
+	return service.readCached(username, from, timelineSource{
+		name:    "timeline of list " + listID,
+		context: models.Flc_HOME,
+		cached: func(before time.Time, beforeID string, count int) ([]models.TimelineItem, error) {
+			return service.db.ListTimelineCache.QueryCachedListTimeline(listID, before, beforeID, count)
+		},
+		rebuild: func() error { return service.rebuildList(listID) },
+		query: func(before time.Time, beforeID string, limit int) ([]*models.Post, error) {
+			return service.db.Timeline.QueryListTimeline(listID, before, beforeID, limit)
+		},
+	})
+}
@@ -0,0 +1,128 @@
+package posts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+type Mention struct {
+	Username string `json:"username"`
+	Url      string `json:"url,omitempty"` // profile. absent for users of other sites
+}
+
+// "@user" or "@user@domain", not in the middle of a word or an address
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w@/])@(\w+(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+
+// users mentioned in the content, in order and deduplicated
+func parseMentions(content string) []string {
+	list := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range mentionRegexp.FindAllStringSubmatch(content, -1) {
+		u := strings.ToLower(m[1])
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		list = append(list, u)
+	}
+	return list
+}
+
+// users mentioned in the content: users of this site found, and users of other sites.
+// "@user@<this site>" is taken as "@user"
+func (service *PostService) resolveMentions(content string) []string {
+	list := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, u := range parseMentions(content) {
+		u = strings.TrimSuffix(u, "@"+service.site)
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		// remote users cannot be checked until federation
+		if strings.Contains(u, "@") || service.user.IsUserExist(u) {
+			list = append(list, u)
+		}
+	}
+	return list
+}
+
+// users addressed by a post besides followers: recipients when direct, otherwise users mentioned
+func addressed(vsb utils.Vsb, recipients []string, mentions []string) []string {
+	if vsb == utils.Vsb_DIRECT {
+		return recipients
+	}
+	return mentions
+}
+
+// save users mentioned by the post, and notify ones newly mentioned except the skipped
+func (service *PostService) saveMentions(username, postID string, mentions, old []string, skip string) {
+	logger := service.lg
+	if len(mentions) == 0 && len(old) == 0 {
+		return
+	}
+	if e := service.db.Mention.SetMentions(postID, mentions); e != nil {
+		msg := fmt.Sprintf("[Posts.Mention] Cannot save mentions of %s", postID)
+		logger.Error(msg, e)
+		return
+	}
+	notified := map[string]bool{skip: true}
+	for _, u := range old {
+		notified[u] = true
+	}
+	for _, u := range mentions {
+		if notified[u] {
+			continue
+		}
+		service.notify(models.Ntf_MENTION, username, u, postID)
+	}
+}
+
+// users mentioned by the post
+func (service *PostService) queryMentions(postID string) []string {
+	logger := service.lg
+	m, e := service.db.Mention.QueryMentions([]string{postID})
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Mention] Cannot get mentions of %s", postID)
+		logger.Error(msg, e)
+		return []string{}
+	}
+	return m[postID]
+}
+
+// set mentions of posts in bulk
+func (service *PostService) setMentions(list []*Post) {
+	logger := service.lg
+	ids := make([]string, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	m, e := service.db.Mention.QueryMentions(ids)
+	if e != nil {
+		logger.Error("[Posts.Mention] Cannot get mentions", e)
+		return
+	}
+
+	us := make(map[string]*users.UserInfo)
+	for _, p := range list {
+		for _, u := range m[p.ID] {
+			mt := Mention{Username: u}
+			if !strings.Contains(u, "@") {
+				if us[u] == nil {
+					ui, e := service.user.GetInfo(u)
+					if e != nil {
+						continue
+					}
+					us[u] = &ui
+				}
+				mt.Url = us[u].ID
+			}
+			p.Mentions = append(p.Mentions, &mt)
+		}
+	}
+}
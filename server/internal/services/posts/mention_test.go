@@ -0,0 +1,57 @@
+package posts
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+type mockingMentionDb struct {
+	models.IPostMention
+	mentions map[string][]string
+}
+
+func newMockingMentionDb() *mockingMentionDb {
+	return &mockingMentionDb{mentions: make(map[string][]string)}
+}
+
+func (db *mockingMentionDb) IsPostMentioning(id, user string) bool {
+	for _, v := range db.mentions[id] {
+		if v == user {
+			return true
+		}
+	}
+	return false
+}
+
+func (db *mockingMentionDb) SetMentions(id string, users []string) error {
+	db.mentions[id] = users
+	return nil
+}
+
+func (db *mockingMentionDb) QueryMentions(ids []string) (map[string][]string, error) {
+	m := make(map[string][]string)
+	for _, id := range ids {
+		if len(db.mentions[id]) != 0 {
+			m[id] = db.mentions[id]
+		}
+	}
+	return m, nil
+}
+
+func TestParseMentions(t *testing.T) {
+	cases := []struct {
+		content string
+		want    []string
+	}{
+		{"hi @a and @B", []string{"a", "b"}},
+		{"@a@remote.sns, @a @a@remote.sns", []string{"a@remote.sns", "a"}},
+		{"mail me: x@a.sns", []string{}},
+		{"see https://site.sns/@a", []string{}},
+		{"(@a_1) @b.", []string{"a_1", "b"}},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, parseMentions(v.content))
+	}
+}
@@ -0,0 +1,249 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kidommoc/gustrody/internal/models"
+)
+
+type PollOption struct {
+	Title string `json:"title"`
+	Votes *int64 `json:"votes"` // null when totals are hidden
+}
+
+type Poll struct {
+	Options   []*PollOption `json:"options"`
+	Multiple  bool          `json:"multiple"`
+	ExpiresAt string        `json:"expiresAt"`
+	Expired   bool          `json:"expired"`
+	Voters    *int64        `json:"voters"` // null when totals are hidden
+	Voted     bool          `json:"voted"`
+	OwnVotes  []int64       `json:"ownVotes,omitempty"`
+}
+
+// poll attached when posting
+type NewPoll struct {
+	Options    []string `json:"options"`
+	Multiple   bool     `json:"multiple"`
+	ExpiresIn  int64    `json:"expiresIn"`  // seconds
+	HideTotals bool     `json:"hideTotals"` // hide counts until expired
+}
+
+const (
+	max_poll_options       = 4
+	max_poll_option_length = 50
+	min_poll_expiry        = 5 * time.Minute
+	max_poll_expiry        = 30 * 24 * time.Hour
+	poll_closing_batch     = 100
+)
+
+// ERRORS
+//
+//   - Poll
+func (np *NewPoll) toModel(postID string, now time.Time) (poll models.Poll, err error) {
+	if len(np.Options) < 2 || len(np.Options) > max_poll_options {
+		return poll, ErrPoll
+	}
+	seen := make(map[string]bool)
+	options := make([]string, 0, len(np.Options))
+	for _, v := range np.Options {
+		o := strings.TrimSpace(v)
+		if o == "" || utf8.RuneCountInString(o) > max_poll_option_length || seen[o] {
+			return poll, ErrPoll
+		}
+		seen[o] = true
+		options = append(options, o)
+	}
+	expiry := time.Duration(np.ExpiresIn) * time.Second
+	if expiry < min_poll_expiry || expiry > max_poll_expiry {
+		return poll, ErrPoll
+	}
+	return models.Poll{
+		Post: postID, Options: options, Multiple: np.Multiple,
+		HideTotals: np.HideTotals, Expires: now.Add(expiry),
+	}, nil
+}
+
+func isExpired(p *models.Poll, now time.Time) bool {
+	return p.Closed || !now.Before(p.Expires)
+}
+
+// ERRORS
+//
+//   - Choice
+func checkChoices(p *models.Poll, choices []int64) error {
+	if len(choices) == 0 || (!p.Multiple && len(choices) > 1) {
+		return ErrChoice
+	}
+	seen := make(map[int64]bool)
+	for _, c := range choices {
+		if c < 0 || c >= int64(len(p.Options)) || seen[c] {
+			return ErrChoice
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+func makePoll(p *models.Poll, votes []int64, now time.Time) *Poll {
+	poll := Poll{
+		Options:   make([]*PollOption, 0, len(p.Options)),
+		Multiple:  p.Multiple,
+		ExpiresAt: p.Expires.Format(time.RFC3339),
+		Expired:   isExpired(p, now),
+		Voted:     votes != nil,
+		OwnVotes:  votes,
+	}
+	// totals are hidden until expired
+	shown := !p.HideTotals || poll.Expired
+	if shown {
+		voters := p.Voters
+		poll.Voters = &voters
+	}
+	for i, v := range p.Options {
+		o := PollOption{Title: v}
+		if shown && i < len(p.Counts) {
+			count := p.Counts[i]
+			o.Votes = &count
+		}
+		poll.Options = append(poll.Options, &o)
+	}
+	return &poll
+}
+
+// set polls of posts in bulk, with votes of the user
+func (service *PostService) setPolls(username string, list []*Post) {
+	logger := service.lg
+	ids := make([]string, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	m, e := service.db.Poll.QueryPolls(ids)
+	if e != nil {
+		logger.Error("[Posts.Poll] Cannot get polls", e)
+		return
+	}
+	if len(m) == 0 {
+		return
+	}
+	votes, e := service.db.Poll.QueryVotes(ids, username)
+	if e != nil {
+		logger.Error("[Posts.Poll] Cannot get votes", e)
+		votes = make(map[string][]int64)
+	}
+	now := time.Now()
+	for _, p := range list {
+		if poll := m[p.ID]; poll != nil {
+			p.Poll = makePoll(poll, votes[p.ID], now)
+		}
+	}
+}
+
+// vote in the poll of a post permitted to the user. the poll is returned with the vote
+//
+// ERRORS
+//
+//   - PostNotFound
+//   - PollNotFound
+//   - NotPermitted
+//   - PollExpired
+//   - Choice
+//   - Voted
+//   - Internal
+func (service *PostService) Vote(username, postID string, choices []int64) (poll *Poll, err error) {
+	logger := service.lg
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return nil, ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Poll] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	if !service.checkPermission(username, p.User, p.ID, p.Vsb) {
+		return nil, ErrNotPermitted
+	}
+
+	m, e := service.db.Poll.QueryPolls([]string{postID})
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Poll] Cannot get poll of %s", postID)
+		logger.Error(msg, e)
+		return nil, ErrInternal
+	}
+	mp := m[postID]
+	if mp == nil {
+		return nil, ErrPollNotFound
+	}
+	now := time.Now()
+	if isExpired(mp, now) {
+		return nil, ErrPollExpired
+	}
+	if e := checkChoices(mp, choices); e != nil {
+		return nil, e
+	}
+
+	if e := service.db.Poll.SetVote(postID, username, choices, now); e != nil {
+		switch e {
+		case models.ErrDunplicate:
+			return nil, ErrVoted
+		default:
+			msg := fmt.Sprintf("[Posts.Poll] Cannot have %s vote in %s", username, postID)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	mp.Voters++
+	for _, c := range choices {
+		mp.Counts[c]++
+	}
+	return makePoll(mp, choices, now), nil
+}
+
+// close polls expired before the date and notify their voters, a batch at a time.
+// used by the scheduled job
+func (service *PostService) ClosePolls(before time.Time) {
+	logger := service.lg
+	ids, e := service.db.Poll.QueryExpiredPolls(before, poll_closing_batch)
+	if e != nil {
+		logger.Error("[Posts.Poll] Cannot get expired polls", e)
+		return
+	}
+	for _, id := range ids {
+		service.closePoll(id)
+	}
+}
+
+func (service *PostService) closePoll(postID string) {
+	logger := service.lg
+	voters, e := service.db.Poll.ClosePoll(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			// closed by another
+		default:
+			msg := fmt.Sprintf("[Posts.Poll] Cannot close poll of %s", postID)
+			logger.Error(msg, e)
+		}
+		return
+	}
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Poll] Cannot get %s", postID)
+		logger.Error(msg, e)
+		return
+	}
+	for _, v := range voters {
+		service.notify(models.Ntf_POLL, p.User, v, postID)
+	}
+	// the post is shown with the results
+	us := service.audience(p.User, p.Vsb, voters)
+	service.publish(service.streamsOf(us, p.Vsb, p.Replying), models.StreamEvent{
+		Event: models.Event_EDIT, PostID: postID,
+	})
+}
@@ -0,0 +1,95 @@
+package posts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+// polls saved, but none read
+type mockingPollDb struct {
+	models.IPostPoll
+	polls []string // posts of polls saved
+}
+
+func (db *mockingPollDb) SetPoll(p *models.Poll) error {
+	for _, v := range db.polls {
+		if v == p.Post {
+			return models.ErrDunplicate
+		}
+	}
+	db.polls = append(db.polls, p.Post)
+	return nil
+}
+
+func (db *mockingPollDb) QueryPolls(ids []string) (map[string]*models.Poll, error) {
+	return map[string]*models.Poll{}, nil
+}
+
+func TestNewPollToModel(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		poll NewPoll
+		err  error
+	}{
+		{NewPoll{Options: []string{"a", " b "}, ExpiresIn: 3600}, nil},
+		{NewPoll{Options: []string{"a"}, ExpiresIn: 3600}, ErrPoll},
+		{NewPoll{Options: []string{"a", "b", "c", "d", "e"}, ExpiresIn: 3600}, ErrPoll},
+		{NewPoll{Options: []string{"a", "a"}, ExpiresIn: 3600}, ErrPoll},
+		{NewPoll{Options: []string{"a", " "}, ExpiresIn: 3600}, ErrPoll},
+		{NewPoll{Options: []string{"a", "b"}, ExpiresIn: 60}, ErrPoll},
+	}
+	for _, v := range cases {
+		_, err := v.poll.toModel("p1", now)
+		test.AssertEqual(t, v.err, err)
+	}
+
+	np := NewPoll{Options: []string{"a", " b "}, Multiple: true, ExpiresIn: 3600}
+	p, _ := np.toModel("p1", now)
+	test.AssertEqual(t, []string{"a", "b"}, []string(p.Options))
+	test.AssertEqual(t, true, p.Multiple)
+	test.AssertEqual(t, now.Add(time.Hour), p.Expires)
+}
+
+func TestCheckChoices(t *testing.T) {
+	single := &models.Poll{Options: []string{"a", "b", "c"}}
+	multiple := &models.Poll{Options: []string{"a", "b", "c"}, Multiple: true}
+	cases := []struct {
+		poll    *models.Poll
+		choices []int64
+		err     error
+	}{
+		{single, []int64{1}, nil},
+		{single, []int64{0, 1}, ErrChoice},
+		{single, []int64{}, ErrChoice},
+		{single, []int64{3}, ErrChoice},
+		{multiple, []int64{0, 2}, nil},
+		{multiple, []int64{2, 2}, ErrChoice},
+		{multiple, []int64{-1}, ErrChoice},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.err, checkChoices(v.poll, v.choices))
+	}
+}
+
+func TestMakePollHidesTotals(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &models.Poll{
+		Options: []string{"a", "b"}, HideTotals: true,
+		Expires: now.Add(time.Hour), Counts: []int64{2, 1}, Voters: 3,
+	}
+
+	open := makePoll(p, []int64{0}, now)
+	test.AssertEqual(t, false, open.Expired)
+	test.AssertEqual(t, true, open.Voted)
+	test.AssertEqual(t, (*int64)(nil), open.Voters)
+	test.AssertEqual(t, (*int64)(nil), open.Options[0].Votes)
+
+	closed := makePoll(p, nil, now.Add(2*time.Hour))
+	test.AssertEqual(t, true, closed.Expired)
+	test.AssertEqual(t, false, closed.Voted)
+	test.AssertEqual(t, int64(3), *closed.Voters)
+	test.AssertEqual(t, int64(2), *closed.Options[0].Votes)
+}
@@ -11,11 +11,41 @@ import (
 	"github.com/kidommoc/gustrody/internal/utils"
 )
 
+func (service *PostService) makeAttachments(media []models.Img) []AttachImg {
+	logger := service.lg
+	list := make([]AttachImg, 0, len(media))
+	for _, v := range media {
+		a := strings.Split(v.Url, ".")
+		if len(a) < 2 {
+			logger.Warning("[Post] Wrong image url: no extension",
+				"url", v.Url,
+			)
+			continue
+		}
+		img := AttachImg{
+			Url: v.Url,
+			Alt: v.Alt,
+		}
+		ext := a[len(a)-1]
+		switch ext {
+		case "jpeg":
+		case "png":
+			img.Type = "image/" + ext
+		default:
+			logger.Warning("[Post] Wrong image url: wrong extension",
+				"url", v.Url,
+			)
+			continue
+		}
+		list = append(list, img)
+	}
+	return list
+}
+
 // ERRORS
 //
 //   - UserNotFound
 func (service *PostService) makePost(p *models.Post, us ...*users.UserInfo) (post Post, err error) {
-	logger := service.lg
 	var u *users.UserInfo
 	if len(us) == 0 {
 		uu, e := service.user.GetInfo(p.User)
@@ -31,38 +61,37 @@ func (service *PostService) makePost(p *models.Post, us ...*users.UserInfo) (pos
 		ID:          p.ID,
 		Url:         p.Url,
 		User:        u,
-		Date:        p.Date.Format(time.RFC3339),
+		Published:   p.Date.Format(time.RFC3339),
 		Visibility:  p.Vsb.String(),
+		Spoiler:     p.Spoiler,
+		Sensitive:   p.Sensitive,
+		Quoting:     p.Quoting,
+		Quotable:    p.Quotable,
+		ReplyPolicy: string(p.ReplyPolicy),
+		InReplyTo:   p.Replying,
 		Content:     p.Content,
 		Likes:       p.Likes,
 		Shares:      p.Shares,
-		Attachments: make([]AttachImg, 0, len(p.Media.Data())),
+	}
+	if !p.Edited.IsZero() {
+		post.EditedAt = p.Edited.Format(time.RFC3339)
+	}
+	// content of other sites is html of anything. ours is rendered and escaped
+	if strings.Contains(p.User, "@") {
+		post.Content = utils.SanitizeHTML(p.Content)
 	}
 
-	for _, v := range p.Media.Data() {
-		a := strings.Split(v.Url, ".")
-		if len(a) < 2 {
-			logger.Warning("[Post] Wrong image url: no extension",
-				"url", v.Url,
-			)
-			continue
-		}
-		img := AttachImg{
-			Url: v.Url,
-			Alt: v.Alt,
-		}
-		ext := a[len(a)-1]
-		switch ext {
-		case "jpeg":
-		case "png":
-			img.Type = "image/" + ext
-		default:
-			logger.Warning("[Post] Wrong image url: wrong extension",
-				"url", v.Url,
-			)
-			continue
+	post.Attachments = service.makeAttachments(p.Media.Data())
+
+	if p.Vsb == utils.Vsb_DIRECT {
+		post.Recipients = make([]*users.UserInfo, 0, len(p.Recipients))
+		for _, v := range p.Recipients {
+			r, e := service.user.GetInfo(v)
+			if e != nil {
+				continue
+			}
+			post.Recipients = append(post.Recipients, &r)
 		}
-		post.Attachments = append(post.Attachments, img)
 	}
 
 	return post, nil
@@ -97,33 +126,84 @@ func (service *PostService) Get(user, postID string) (post Post, err error) {
 			return post, ErrInternal
 		}
 	}
-	service.setReplies(&post)
+	list := []*Post{&post}
+	service.setMentions(list)
+	service.setTags(list)
+	service.setPolls(user, list)
+	service.setViewerStates(user, list)
+	service.setReactions(user, list)
+	service.setEmojis(list)
+	service.setQuotes(user, list)
 
 	return post, nil
 }
 
-func (service *PostService) GetByUser(username, target string) (list []*Post, err error) {
+// source text of a post permitted to the user, for editing
+//
+// ERRORS
+//
+//   - PostNotFound
+//   - NotPermitted
+//   - Internal
+func (service *PostService) GetSource(user, postID string) (source Source, err error) {
 	logger := service.lg
-	user, e := service.user.GetInfo(target)
+	p, e := service.db.Query.QueryPostByID(postID)
 	if e != nil {
 		switch e {
 		case models.ErrNotFound:
-			return nil, ErrUserNotFound
+			return source, ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return source, ErrInternal
+		}
+	}
+	if !service.checkPermission(user, p.User, p.ID, p.Vsb) {
+		return source, ErrNotPermitted
+	}
+	// posts of other sites have no source
+	if p.Source == "" {
+		return source, ErrPostNotFound
+	}
+	return Source{ID: p.ID, Source: p.Source, Spoiler: p.Spoiler}, nil
+}
+
+// posts permitted to the user, by id. posts not found or not permitted are absent
+func (service *PostService) GetByIDs(username string, ids []string) map[string]*Post {
+	items := make([]models.TimelineItem, 0, len(ids))
+	for _, v := range ids {
+		items = append(items, models.TimelineItem{PostID: v})
+	}
+	m := make(map[string]*Post)
+	for _, p := range service.hydrate(username, items) {
+		m[p.ID] = p
+	}
+	return m
+}
+
+func (service *PostService) GetByUser(username, target string, page utils.Page) (list []*Post, links utils.PageLinks, err error) {
+	logger := service.lg
+	user, e := service.user.GetInfo(target)
+	if e != nil {
+		switch e {
+		case users.ErrUserNotFound:
+			return nil, links, ErrUserNotFound
 		default:
 			msg := fmt.Sprintf("[Posts] Cannot get info of %s", target)
 			logger.Error(msg, e)
-			return nil, ErrInternal
+			return nil, links, ErrInternal
 		}
 	}
 	us := make(map[string]*users.UserInfo)
 	us[target] = &user
 
-	posts, e := service.db.Query.QueryPostsAndSharesByUser(target, false) // descending by date
+	posts, e := service.db.Query.QueryPostsAndSharesByUser(target, page)
 	if e != nil {
 		logger.Error("[Posts] Error when GetByUser", e)
-		return list, nil
+		return nil, links, ErrInternal
 	}
 	list = make([]*Post, 0, len(posts))
+	cursors := make([]utils.Cursor, 0, len(posts))
 	gu := func(u string) *users.UserInfo {
 		if u == "" {
 			return nil
@@ -142,6 +222,8 @@ func (service *PostService) GetByUser(username, target string) (list []*Post, er
 	}
 	fo_only := service.checkPermission(username, target, "", utils.Vsb_FOLLOWER)
 	for _, v := range posts {
+		// pages are counted before filtering
+		cursors = append(cursors, v.Cursor())
 		switch v.Vsb {
 		case utils.Vsb_FOLLOWER:
 			if !fo_only {
@@ -164,26 +246,73 @@ func (service *PostService) GetByUser(username, target string) (list []*Post, er
 		p.SharedBy = gu(v.SharedBy)
 		list = append(list, &p)
 	}
+	service.setMentions(list)
+	service.setTags(list)
+	service.setPolls(username, list)
+	service.setViewerStates(username, list)
+	service.setReactions(username, list)
+	service.setEmojis(list)
+	service.setQuotes(username, list)
 
-	return list, nil
+	return service.ApplyFilters(username, models.Flc_PROFILE, list), page.Links(cursors), nil
 }
 
-func (service *PostService) New(username, vsb, content string, attachments []AttachImg) error {
-	logger := service.lg
-	if !service.user.IsUserExist(username) {
-		return ErrUserNotFound
-	}
+// content warning counts in the length of content
+func (service *PostService) checkContent(content string, opts *Options) error {
 	if content == "" {
 		return ErrContentEmpty
 	}
-	if utf8.RuneCountInString(content) > service.maxContentLength {
+	length := utf8.RuneCountInString(opts.spoiler()) + utf8.RuneCountInString(content)
+	if length > service.maxContentLength {
 		return ErrContentTooLong
 	}
+	return nil
+}
 
+// recipients are used only when direct
+func (service *PostService) New(username, vsb, content string, attachments []AttachImg, recipients []string, opts Options) error {
 	id := service.newID()
 	for service.db.Query.IsPostExist(id) {
 		id = service.newID()
 	}
+	return service.create(id, username, vsb, content, attachments, recipients, opts)
+}
+
+// post with the id given
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - ContentEmpty
+//   - ContentTooLong
+//   - Poll
+//   - ReplyPolicy
+//   - PostNotFound: quoted
+//   - NotPermitted: quoted
+//   - NotQuotable
+//   - NoRecipient
+//   - PostExist
+//   - Internal
+func (service *PostService) create(id, username, vsb, content string, attachments []AttachImg, recipients []string, opts Options) error {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return ErrUserNotFound
+	}
+	if e := service.checkContent(content, &opts); e != nil {
+		return e
+	}
+	policy, e := replyPolicyOf(opts.ReplyPolicy, models.Rpl_EVERYONE)
+	if e != nil {
+		return e
+	}
+	var quoted *models.Post = nil
+	if opts.Quoting != "" {
+		q, e := service.quoted(username, opts.Quoting)
+		if e != nil {
+			return e
+		}
+		quoted = &q
+	}
 	url := service.getUrl(id)
 
 	imgs := []models.Img{}
@@ -195,36 +324,90 @@ func (service *PostService) New(username, vsb, content string, attachments []Att
 	}
 
 	v, ok := utils.GetVsb(vsb)
-	if !ok {
+	sensitive := opts.Sensitive != nil && *opts.Sensitive
+	if !ok || opts.Sensitive == nil {
 		pf, err := service.user.GetPreferences(username)
 		if err != nil {
 			logger.Error("[Posts] Cannot get user preferences.", err)
 			return ErrInternal
 		}
-		v = pf.PostVsb
+		if !ok {
+			v = pf.PostVsb
+		}
+		if opts.Sensitive == nil {
+			sensitive = pf.Sensitive
+		}
 	}
 
 	p := models.Post{
 		ID: id, Url: url, User: username, Date: time.Now(),
-		Replying: "", Vsb: v, Content: content,
+		Replying: "", Vsb: v, Source: content,
+		Spoiler: opts.spoiler(), Sensitive: sensitive,
+		Quoting: opts.Quoting, Quotable: opts.Quotable == nil || *opts.Quotable,
+		ReplyPolicy: policy,
+	}
+	var poll *models.Poll = nil
+	if opts.Poll != nil {
+		mp, e := opts.Poll.toModel(id, p.Date)
+		if e != nil {
+			return e
+		}
+		poll = &mp
+	}
+	mentions := service.resolveMentions(content)
+	tags := parseTags(content)
+	links := service.linksOf(content, mentions)
+	p.Content = service.render(content, mentions)
+	if v == utils.Vsb_DIRECT {
+		p.Recipients = service.recipientsOf(username, content, recipients, nil)
+		if len(p.Recipients) == 0 {
+			return ErrNoRecipient
+		}
 	}
 	if e := service.db.Set.SetPost(&p, imgs); e != nil {
-		logger.Error("[Post] Cannot set post", e)
-		return ErrInternal
+		switch e {
+		case models.ErrDunplicate:
+			return ErrPostExist
+		default:
+			logger.Error("[Post] Cannot set post", e)
+			return ErrInternal
+		}
+	}
+	// participants are told of the post only once it's saved
+	if v == utils.Vsb_DIRECT {
+		if e := service.joinConversation(&p, nil); e != nil {
+			service.db.Set.RemovePost(id)
+			return e
+		}
+	}
+	if poll != nil {
+		if e := service.db.Poll.SetPoll(poll); e != nil {
+			logger.Error("[Post] Cannot set poll", e)
+			service.db.Set.RemovePost(id)
+			return ErrInternal
+		}
+	}
+	service.saveMentions(username, id, mentions, nil, "")
+	service.saveTags(id, tags, nil)
+	service.saveLinks(id, links, nil)
+	item := models.TimelineItem{PostID: id, Date: p.Date}
+	service.fanout(username, v, "", append(addressed(v, p.Recipients, mentions), service.tagFollowers(v, tags)...), item)
+	if v == utils.Vsb_PUBLIC {
+		streams := append(service.streamsOf(nil, v, ""), tagStreams(v, tags)...)
+		service.publish(streams, models.StreamEvent{
+			Event: models.Event_UPDATE, Item: &item,
+		})
+	}
+	// only if the author quoted can read it
+	if quoted != nil && service.checkPermission(quoted.User, username, id, v) {
+		service.notify(models.Ntf_QUOTE, username, quoted.User, id)
 	}
 
 	return nil
 }
 
-func (service *PostService) Edit(username, postID, content string, attachments []AttachImg) error {
+func (service *PostService) Edit(username, postID, content string, attachments []AttachImg, opts Options) error {
 	logger := service.lg
-	if content == "" {
-		return ErrContentEmpty
-	}
-	if utf8.RuneCountInString(content) > service.maxContentLength {
-		return ErrContentTooLong
-	}
-
 	post, e := service.db.Query.QueryPostByID(postID)
 	if e != nil {
 		switch e {
@@ -239,6 +422,16 @@ func (service *PostService) Edit(username, postID, content string, attachments [
 	if post.User != username {
 		return ErrOwner
 	}
+	if opts.Spoiler == nil {
+		opts.Spoiler = &post.Spoiler
+	}
+	if e := service.checkContent(content, &opts); e != nil {
+		return e
+	}
+	policy, e := replyPolicyOf(opts.ReplyPolicy, post.ReplyPolicy)
+	if e != nil {
+		return e
+	}
 
 	imgs := []models.Img{}
 	for i, v := range attachments {
@@ -249,8 +442,23 @@ func (service *PostService) Edit(username, postID, content string, attachments [
 	}
 
 	p := models.Post{
-		ID: postID, Date: time.Now(), Content: content,
+		ID: postID, Edited: time.Now(), Source: content,
+		Spoiler: *opts.Spoiler, Sensitive: post.Sensitive,
+		Quotable: post.Quotable, ReplyPolicy: policy,
+	}
+	if opts.Sensitive != nil {
+		p.Sensitive = *opts.Sensitive
+	}
+	if opts.Quotable != nil {
+		p.Quotable = *opts.Quotable
 	}
+	old := service.queryMentions(postID)
+	mentions := service.resolveMentions(content)
+	oldTags := service.queryTags(postID)
+	tags := parseTags(content)
+	oldLinks := service.linksOf(post.Source, old)
+	links := service.linksOf(content, mentions)
+	p.Content = service.render(content, mentions)
 	if e := service.db.Set.UpdatePost(&p, imgs); e != nil {
 		switch e {
 		case models.ErrNotFound:
@@ -261,6 +469,13 @@ func (service *PostService) Edit(username, postID, content string, attachments [
 			return ErrInternal
 		}
 	}
+	service.saveMentions(username, postID, mentions, old, "")
+	service.saveTags(postID, tags, oldTags)
+	service.saveLinks(postID, links, oldLinks)
+	us := service.audience(username, post.Vsb, addressed(post.Vsb, post.Recipients, mentions))
+	streams := append(service.streamsOf(us, post.Vsb, post.Replying), tagStreams(post.Vsb, tags)...)
+	streams = append(streams, listStreams(service.listsOf(username, post.Vsb, service.replyToOf(&post)))...)
+	service.publish(streams, models.StreamEvent{Event: models.Event_EDIT, PostID: postID})
 
 	return nil
 }
@@ -282,6 +497,9 @@ func (service *PostService) Remove(username, postID string) error {
 		return ErrOwner
 	}
 
+	// mentions and hashtags are removed with the post
+	mentions := service.queryMentions(postID)
+	tags := service.queryTags(postID)
 	if e := service.db.Set.RemovePost(postID); e != nil {
 		switch e {
 		case models.ErrNotFound:
@@ -292,6 +510,13 @@ func (service *PostService) Remove(username, postID string) error {
 			return ErrInternal
 		}
 	}
+	reached := append(addressed(post.Vsb, post.Recipients, mentions), service.tagFollowers(post.Vsb, tags)...)
+	us, ls := service.fanoutRemove(username, post.Vsb, reached, models.TimelineItem{PostID: postID})
+	streams := append(service.streamsOf(us, post.Vsb, post.Replying), tagStreams(post.Vsb, tags)...)
+	streams = append(streams, listStreams(ls)...)
+	service.publish(streams, models.StreamEvent{
+		Event: models.Event_DELETE, PostID: postID,
+	})
 
 	return nil
 }
@@ -0,0 +1,85 @@
+package posts
+
+import (
+	"fmt"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// direct posts are never quoted, and follower-only ones only by their authors.
+// others can quote a public post unless its author disallowed
+func isQuotable(username string, p *models.Post) bool {
+	switch p.Vsb {
+	case utils.Vsb_DIRECT:
+		return false
+	case utils.Vsb_FOLLOWER:
+		return p.User == username
+	}
+	return p.Quotable || p.User == username
+}
+
+// the post to be quoted by the user
+//
+// ERRORS
+//
+//   - PostNotFound
+//   - NotPermitted
+//   - NotQuotable
+//   - Internal
+func (service *PostService) quoted(username, postID string) (p models.Post, err error) {
+	logger := service.lg
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return p, ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Quote] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return p, ErrInternal
+		}
+	}
+	if !service.checkPermission(username, p.User, p.ID, p.Vsb) {
+		return p, ErrNotPermitted
+	}
+	if !isQuotable(username, &p) {
+		return p, ErrNotQuotable
+	}
+	return p, nil
+}
+
+// set posts quoted in bulk, dropping ones removed or not permitted to the user.
+// quotes of the posts quoted are not embedded
+func (service *PostService) setQuotes(username string, list []*Post) {
+	logger := service.lg
+	ids := make([]string, 0)
+	for _, p := range list {
+		if p.Quoting != "" {
+			ids = append(ids, p.Quoting)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	result, e := service.db.Query.QueryPostsByIDs(ids)
+	if e != nil {
+		logger.Error("[Posts.Quote] Cannot query posts quoted", e)
+		return
+	}
+	items := make([]models.TimelineItem, 0, len(result))
+	ps := make(map[string]*models.Post)
+	for _, p := range result {
+		items = append(items, models.TimelineItem{PostID: p.ID})
+		ps[p.ID] = p
+	}
+	m := make(map[string]*Post)
+	for _, p := range service.makePosts(username, items, ps) {
+		m[p.ID] = p
+	}
+	for _, p := range list {
+		if q := m[p.Quoting]; q != nil {
+			p.Quote = q
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package posts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+func TestIsQuotable(t *testing.T) {
+	cases := []struct {
+		user string
+		post models.Post
+		want bool
+	}{
+		{"u2", models.Post{User: "u1", Vsb: utils.Vsb_PUBLIC, Quotable: true}, true},
+		{"u2", models.Post{User: "u1", Vsb: utils.Vsb_PUBLIC, Quotable: false}, false},
+		{"u1", models.Post{User: "u1", Vsb: utils.Vsb_PUBLIC, Quotable: false}, true},
+		{"u2", models.Post{User: "u1", Vsb: utils.Vsb_FOLLOWER, Quotable: true}, false},
+		{"u1", models.Post{User: "u1", Vsb: utils.Vsb_FOLLOWER, Quotable: true}, true},
+		{"u1", models.Post{User: "u1", Vsb: utils.Vsb_DIRECT, Quotable: true}, false},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, isQuotable(v.user, &v.post))
+	}
+}
+
+// users notified of quotes
+func quoteNotified(db *mockingDb) []string {
+	list := make([]string, 0)
+	for _, n := range db.notified {
+		if n.Type == models.Ntf_QUOTE {
+			list = append(list, n.User)
+		}
+	}
+	return list
+}
+
+func TestQuoteNotification(t *testing.T) {
+	db := newMockingDb("u1", "u2", "u3")
+	service := newTestService(t, db)
+	now := time.Now()
+	db.addPost(&models.Post{ID: "q", User: "u2", Date: now.Add(-time.Hour), Quotable: true})
+	db.addPost(&models.Post{ID: "p", User: "u3", Date: now.Add(-time.Hour)})
+
+	err := service.New("u1", "public", "look", nil, nil, Options{Quoting: "q"})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"u2"}, quoteNotified(db))
+
+	// not told of posts the author quoted cannot read
+	err = service.New("u1", "follower", "look", nil, nil, Options{Quoting: "q"})
+	test.AssertNoError(t, err)
+	err = service.New("u1", "direct", "look", nil, []string{"u3"}, Options{Quoting: "q"})
+	test.AssertNoError(t, err)
+	err = service.Reply("u1", "p", "follower", "look", nil, nil, Options{Quoting: "q"})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"u2"}, quoteNotified(db))
+
+	db.follows["u2>u1"] = true
+	err = service.Reply("u1", "p", "follower", "look", nil, nil, Options{Quoting: "q"})
+	test.AssertNoError(t, err)
+	err = service.New("u1", "direct", "look", nil, []string{"u2"}, Options{Quoting: "q"})
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"u2", "u2", "u2"}, quoteNotified(db))
+}
@@ -0,0 +1,188 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/emojis"
+)
+
+type Reaction struct {
+	Emoji string `json:"emoji"`         // unicode, or ":shortcode:" of custom emoji
+	Url   string `json:"url,omitempty"` // image of custom emoji
+	Count int64  `json:"count"`
+	Me    bool   `json:"me"` // reacted by the viewer
+}
+
+const (
+	max_emoji_runes = 16 // long enough for sequences joined by ZWJ
+)
+
+// runes an emoji is made of. not exact, but enough to keep texts out
+func isEmojiRune(r rune) bool {
+	switch {
+	case r == 0xA9, r == 0xAE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139,
+		r >= 0x2194 && r <= 0x21AA,
+		r >= 0x231A && r <= 0x23FF,
+		r == 0x24C2,
+		r >= 0x25AA && r <= 0x27BF,
+		r >= 0x2934 && r <= 0x2935,
+		r >= 0x2B05 && r <= 0x2B55,
+		r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299,
+		r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	}
+	return false
+}
+
+// joiners, variation selectors, tags, and digits of keycaps
+func isEmojiComponent(r rune) bool {
+	switch {
+	case r == 0x200D, r == 0xFE0E, r == 0xFE0F,
+		r >= 0xE0020 && r <= 0xE007F,
+		r >= '0' && r <= '9', r == '#', r == '*':
+		return true
+	}
+	return false
+}
+
+// a unicode emoji, or a custom one as ":shortcode:"
+func normalizeEmoji(s string) (emoji string, ok bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2 && strings.HasPrefix(s, ":") && strings.HasSuffix(s, ":") {
+		return s, emojis.IsShortcode(s[1 : len(s)-1])
+	}
+	if s == "" || !utf8.ValidString(s) || utf8.RuneCountInString(s) > max_emoji_runes {
+		return "", false
+	}
+	base := false
+	for _, r := range s {
+		switch {
+		case isEmojiRune(r), r == 0x20E3: // a keycap makes the digit an emoji
+			base = true
+		case isEmojiComponent(r):
+		default:
+			return "", false
+		}
+	}
+	return s, base
+}
+
+// set reactions of posts in bulk, with whether the user reacted
+func (service *PostService) setReactions(username string, list []*Post) {
+	logger := service.lg
+	if len(list) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	m, e := service.db.Reaction.QueryReactions(ids, username)
+	if e != nil {
+		logger.Error("[Posts.Reaction] Cannot get reactions", e)
+		return
+	}
+	texts := make([]string, 0)
+	for _, rs := range m {
+		for _, v := range rs {
+			texts = append(texts, v.Emoji)
+		}
+	}
+	// custom emoji reacted with are of this site
+	urls := make(map[string]string)
+	if result, e := emojis.Resolve(service.db.Emoji, "", texts...); e != nil {
+		logger.Error("[Posts.Reaction] Cannot get custom emoji", e)
+	} else {
+		for _, v := range result {
+			urls[":"+v.Shortcode+":"] = v.Url
+		}
+	}
+	for _, p := range list {
+		for _, v := range m[p.ID] {
+			p.Reactions = append(p.Reactions, &Reaction{
+				Emoji: v.Emoji, Url: urls[v.Emoji], Count: v.Count, Me: v.Me,
+			})
+		}
+	}
+}
+
+// react to a post permitted to the user with an emoji. custom emoji are of this site
+//
+// ERRORS
+//
+//   - Emoji
+//   - PostNotFound
+//   - NotPermitted
+//   - Internal
+func (service *PostService) React(username, postID, emoji string) error {
+	logger := service.lg
+	emoji, ok := normalizeEmoji(emoji)
+	if !ok {
+		return ErrEmoji
+	}
+	if codes := emojis.ParseShortcodes(emoji); len(codes) != 0 {
+		result, e := service.db.Emoji.QueryEmojisByShortcodes("", codes)
+		if e != nil {
+			msg := fmt.Sprintf("[Posts.Reaction] Cannot get custom emoji %s", emoji)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+		if len(result) == 0 {
+			return ErrEmoji
+		}
+	}
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Reaction] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	if !service.checkPermission(username, p.User, p.ID, p.Vsb) {
+		return ErrNotPermitted
+	}
+
+	if e := service.db.Reaction.SetReaction(username, postID, emoji, time.Now()); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Reaction] Cannot have %s react to %s", username, postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - Emoji
+//   - ReactionNotFound
+//   - Internal
+func (service *PostService) Unreact(username, postID, emoji string) error {
+	logger := service.lg
+	emoji, ok := normalizeEmoji(emoji)
+	if !ok {
+		return ErrEmoji
+	}
+	if e := service.db.Reaction.RemoveReaction(username, postID, emoji); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrReactionNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Reaction] Cannot remove reaction of %s to %s", username, postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package posts
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+// no reactions
+type mockingReactionDb struct {
+	models.IPostReaction
+}
+
+func (db mockingReactionDb) QueryReactions(ids []string, user string) (map[string][]*models.Reaction, error) {
+	return map[string][]*models.Reaction{}, nil
+}
+
+func TestNormalizeEmoji(t *testing.T) {
+	cases := []struct {
+		emoji string
+		want  string
+		ok    bool
+	}{
+		{"👍", "👍", true},
+		{" 🎉 ", "🎉", true},
+		{"❤️", "❤️", true},
+		{"👩‍👩‍👧", "👩‍👩‍👧", true},
+		{"👍🏽", "👍🏽", true},
+		{"1️⃣", "1️⃣", true},
+		{":blobcat:", ":blobcat:", true},
+		{":blob cat:", ":blob cat:", false},
+		{"::", "", false},
+		{"a", "", false},
+		{"1", "", false},
+		{"👍a", "", false},
+		{"", "", false},
+	}
+	for _, v := range cases {
+		emoji, ok := normalizeEmoji(v.emoji)
+		test.AssertEqual(t, v.ok, ok)
+		if ok {
+			test.AssertEqual(t, v.want, emoji)
+		}
+	}
+}
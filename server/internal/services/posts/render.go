@@ -0,0 +1,135 @@
+package posts
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+var urlRegexp = regexp.MustCompile(`https?://[^\s<>"]+`)
+var paragraphRegexp = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// a part of the source text turned into a link
+type link struct {
+	start, end int
+	html       string
+	url        string // only of urls
+}
+
+// links of urls, mentions and hashtags in the text, ascending and not overlapping.
+// mentionUrl gives profile of a user mentioned, or "" if not linked
+func findLinks(text string, mentionUrl func(user string) string, tagUrl func(tag string) string) []link {
+	list := make([]link, 0)
+	for _, m := range urlRegexp.FindAllStringIndex(text, -1) {
+		u := strings.TrimRight(text[m[0]:m[1]], ".,:;!?)'")
+		if !utils.IsSafeUrl(u) {
+			continue
+		}
+		e := html.EscapeString(u)
+		list = append(list, link{m[0], m[0] + len(u),
+			`<a href="` + e + `" rel="` + utils.LinkRel + `" target="_blank">` + e + `</a>`, u,
+		})
+	}
+	for _, m := range mentionRegexp.FindAllStringSubmatchIndex(text, -1) {
+		name := text[m[2]:m[3]]
+		url := mentionUrl(strings.ToLower(name))
+		if url == "" {
+			continue
+		}
+		list = append(list, link{m[2] - 1, m[3],
+			`<span class="h-card"><a href="` + html.EscapeString(url) + `" class="u-url mention">@<span>` +
+				html.EscapeString(name) + `</span></a></span>`, "",
+		})
+	}
+	for _, m := range tagRegexp.FindAllStringSubmatchIndex(text, -1) {
+		name := text[m[2]:m[3]]
+		tag, ok := normalizeTag(name)
+		if !ok {
+			continue
+		}
+		list = append(list, link{m[2] - 1, m[3],
+			`<a href="` + html.EscapeString(tagUrl(tag)) + `" class="mention hashtag" rel="tag">#<span>` +
+				html.EscapeString(name) + `</span></a>`, "",
+		})
+	}
+
+	// urls come first, so "#" and "@" in urls are not linked again
+	sort.SliceStable(list, func(i, j int) bool { return list[i].start < list[j].start })
+	result := make([]link, 0, len(list))
+	end := 0
+	for _, l := range list {
+		if l.start < end {
+			continue
+		}
+		result = append(result, l)
+		end = l.end
+	}
+	return result
+}
+
+// escape the text, with links
+func renderLine(text string, links []link, offset int) string {
+	var b strings.Builder
+	i := 0
+	for _, l := range links {
+		s, e := l.start-offset, l.end-offset
+		if s < 0 || e > len(text) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[i:s]))
+		b.WriteString(l.html)
+		i = e
+	}
+	b.WriteString(html.EscapeString(text[i:]))
+	return b.String()
+}
+
+// turn plain text into html: paragraphs by blank lines, line breaks, and links of
+// urls, mentions and hashtags
+func renderText(text string, mentionUrl func(user string) string, tagUrl func(tag string) string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	links := findLinks(text, mentionUrl, tagUrl)
+
+	var b strings.Builder
+	offset := 0
+	for _, p := range paragraphRegexp.Split(text, -1) {
+		// find the paragraph in the text to keep offsets of links
+		offset += strings.Index(text[offset:], p)
+		ls := make([]link, 0)
+		for _, l := range links {
+			if l.start >= offset && l.end <= offset+len(p) {
+				ls = append(ls, l)
+			}
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(renderLine(p, ls, offset), "\n", "<br>"))
+		b.WriteString("</p>")
+		offset += len(p)
+	}
+	return b.String()
+}
+
+// profiles of users mentioned, if they are of this site
+func (service *PostService) mentionUrls(mentions []string) func(user string) string {
+	urls := make(map[string]string)
+	for _, u := range mentions {
+		if strings.Contains(u, "@") {
+			continue
+		}
+		if ui, e := service.user.GetInfo(u); e == nil {
+			urls[u] = ui.ID
+		}
+	}
+	return func(user string) string {
+		return urls[strings.TrimSuffix(user, "@"+service.site)]
+	}
+}
+
+// render the source of a post of this site. users mentioned are linked to
+// their profiles if they are of this site
+func (service *PostService) render(source string, mentions []string) string {
+	return renderText(source, service.mentionUrls(mentions), service.getTagUrl)
+}
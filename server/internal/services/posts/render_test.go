@@ -0,0 +1,41 @@
+package posts
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestRenderText(t *testing.T) {
+	mentionUrl := func(user string) string {
+		if user == "a" {
+			return "https://s.sns/users/a"
+		}
+		return ""
+	}
+	tagUrl := func(tag string) string {
+		return "https://s.sns/tags/" + tag
+	}
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"hi <b>&", "<p>hi &lt;b&gt;&amp;</p>"},
+		{"one\ntwo\n\n three", "<p>one<br>two</p><p>three</p>"},
+		{
+			"see https://x.sns/a#b?c=1&d=2.",
+			`<p>see <a href="https://x.sns/a#b?c=1&amp;d=2" rel="nofollow noopener noreferrer" target="_blank">https://x.sns/a#b?c=1&amp;d=2</a>.</p>`,
+		},
+		{
+			"@A and @b",
+			`<p><span class="h-card"><a href="https://s.sns/users/a" class="u-url mention">@<span>A</span></a></span> and @b</p>`,
+		},
+		{
+			"#Go!",
+			`<p><a href="https://s.sns/tags/go" class="mention hashtag" rel="tag">#<span>Go</span></a>!</p>`,
+		},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, renderText(v.in, mentionUrl, tagUrl))
+	}
+}
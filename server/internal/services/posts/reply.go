@@ -3,22 +3,105 @@ package posts
 import (
 	"fmt"
 	"time"
-	"unicode/utf8"
 
 	"github.com/kidommoc/gustrody/internal/models"
 	"github.com/kidommoc/gustrody/internal/utils"
 )
 
-func (service *PostService) Reply(username, postID, vsb, content string, attachments []AttachImg) error {
+// reply policy given, or the default if empty
+//
+// ERRORS
+//
+//   - ReplyPolicy
+func replyPolicyOf(literal string, dflt models.ReplyPolicy) (models.ReplyPolicy, error) {
+	if literal == "" {
+		return dflt, nil
+	}
+	p, ok := models.GetReplyPolicy(literal)
+	if !ok {
+		return "", ErrReplyPolicy
+	}
+	return p, nil
+}
+
+// users mentioned in the post, or recipients of it if direct
+func (service *PostService) isAddressed(username string, p *models.Post) bool {
+	for _, v := range p.Recipients {
+		if v == username {
+			return true
+		}
+	}
+	return service.db.Mention.IsPostMentioning(p.ID, username)
+}
+
+// whether the user can reply to the post by its reply policy. the author always can
+func (service *PostService) canReply(username string, p *models.Post) bool {
+	if username == p.User {
+		return true
+	}
+	switch p.ReplyPolicy {
+	case models.Rpl_NOBODY:
+		return false
+	case models.Rpl_MENTIONED:
+		return service.isAddressed(username, p)
+	case models.Rpl_FOLLOWERS:
+		return service.user.IsFollowing(username, p.User) || service.isAddressed(username, p)
+	}
+	return true
+}
+
+// replies to a direct post are direct, addressed to its participants.
+// recipients are used only when direct
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - ContentEmpty
+//   - ContentTooLong
+//   - ReplyPolicy
+//   - PostNotFound
+//   - NotPermitted
+//   - ReplyNotAllowed
+//   - NotQuotable
+//   - NoRecipient
+//   - Internal
+func (service *PostService) Reply(username, postID, vsb, content string, attachments []AttachImg, recipients []string, opts Options) error {
 	logger := service.lg
 	if !service.user.IsUserExist(username) {
 		return ErrUserNotFound
 	}
-	if content == "" {
-		return ErrContentEmpty
+	if e := service.checkContent(content, &opts); e != nil {
+		return e
+	}
+	policy, e := replyPolicyOf(opts.ReplyPolicy, models.Rpl_EVERYONE)
+	if e != nil {
+		return e
+	}
+
+	rp, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Reply] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	if !service.checkPermission(username, rp.User, rp.ID, rp.Vsb) {
+		return ErrNotPermitted
+	}
+	if !service.canReply(username, &rp) {
+		return ErrReplyNotAllowed
 	}
-	if utf8.RuneCountInString(content) > service.maxContentLength {
-		return ErrContentTooLong
+	var quoted *models.Post = nil
+	if opts.Quoting != "" {
+		q, e := service.quoted(username, opts.Quoting)
+		if e != nil {
+			return e
+		}
+		quoted = &q
 	}
 
 	id := service.newID()
@@ -28,13 +111,22 @@ func (service *PostService) Reply(username, postID, vsb, content string, attachm
 	url := service.getUrl(id)
 
 	v, ok := utils.GetVsb(vsb)
-	if !ok {
+	sensitive := opts.Sensitive != nil && *opts.Sensitive
+	if !ok || opts.Sensitive == nil {
 		pf, err := service.user.GetPreferences(username)
 		if err != nil {
 			logger.Error("[Posts.Reply] Cannot get user preferences.", err)
 			return ErrInternal
 		}
-		v = pf.PostVsb
+		if !ok {
+			v = pf.PostVsb
+		}
+		if opts.Sensitive == nil {
+			sensitive = pf.Sensitive
+		}
+	}
+	if rp.Vsb == utils.Vsb_DIRECT {
+		v = utils.Vsb_DIRECT
 	}
 
 	imgs := []models.Img{}
@@ -47,7 +139,20 @@ func (service *PostService) Reply(username, postID, vsb, content string, attachm
 
 	p := models.Post{
 		ID: id, Url: url, User: username, Date: time.Now(),
-		Replying: postID, Vsb: v, Content: content,
+		Replying: postID, Vsb: v, Source: content,
+		Spoiler: opts.spoiler(), Sensitive: sensitive,
+		Quoting: opts.Quoting, Quotable: opts.Quotable == nil || *opts.Quotable,
+		ReplyPolicy: policy,
+	}
+	mentions := service.resolveMentions(content)
+	tags := parseTags(content)
+	links := service.linksOf(content, mentions)
+	p.Content = service.render(content, mentions)
+	if v == utils.Vsb_DIRECT {
+		p.Recipients = service.recipientsOf(username, content, recipients, &rp)
+		if len(p.Recipients) == 0 {
+			return ErrNoRecipient
+		}
 	}
 	if e := service.db.Set.SetPost(&p, imgs); e != nil {
 		switch e {
@@ -59,57 +164,30 @@ func (service *PostService) Reply(username, postID, vsb, content string, attachm
 			return ErrInternal
 		}
 	}
-
-	return nil
-}
-
-// set replyings and replies of a post
-func (service *PostService) setReplies(post *Post) error {
-	logger := service.lg
-	rt, rs, e := service.db.Query.QueryPostReplies(post.ID)
-	if e != nil {
-		msg := fmt.Sprintf("[Posts.Reply] Cannot get replyings and replies of %s", post.ID)
-		logger.Error(msg, e)
-		return ErrPostNotFound
-	}
-
-	// replying to. as list
-	rt = rt[1:]
-	for _, v := range rt {
-		p, e := service.makePost(v)
-		if e != nil {
-			logger.Error("[Posts.Reply] Cannot make post", e)
-			continue
+	// participants are told of the post only once it's saved
+	if v == utils.Vsb_DIRECT {
+		var replying *models.Post = nil
+		if rp.Vsb == utils.Vsb_DIRECT {
+			replying = &rp
 		}
-		post.Replyings = append(post.Replyings, &p)
-	}
-
-	// replies. as tree
-	lrs := len(rs)
-	if lrs == 0 {
-		post.Replies = make([]*Post, 0)
-		return nil
-	}
-	m := make(map[string]*Post)
-	m[post.ID] = post
-	lev := rs[0].Level
-	maxLev := rs[len(rs)-1].Level
-	i := 1
-	for lev < maxLev && len(m) != 0 {
-		m2 := make(map[string]*Post)
-		for ; i < lrs && rs[i].Level == lev+1; i += 1 {
-			p, e := service.makePost(rs[i])
-			if e != nil {
-				logger.Error("[Posts.Reply] Cannot make post", e)
-				continue
-			}
-			p.Replies = make([]*Post, 0)
-			r := rs[i].Replying
-			m[r].Replies = append(m[r].Replies, &p)
-			m2[p.ID] = &p
+		if e := service.joinConversation(&p, replying); e != nil {
+			service.db.Set.RemovePost(id)
+			return e
 		}
-		m = m2
-		lev += 1
+	}
+	// the owner replied is notified of the reply instead
+	service.saveMentions(username, id, mentions, nil, rp.User)
+	service.saveTags(id, tags, nil)
+	service.saveLinks(id, links, nil)
+	item := models.TimelineItem{PostID: id, Date: p.Date}
+	service.fanout(username, v, rp.User, append(addressed(v, p.Recipients, mentions), service.tagFollowers(v, tags)...), item)
+	if streams := tagStreams(v, tags); len(streams) != 0 {
+		service.publish(streams, models.StreamEvent{Event: models.Event_UPDATE, Item: &item})
+	}
+	service.notify(models.Ntf_REPLY, username, rp.User, id)
+	// only if the author quoted can read it
+	if quoted != nil && service.checkPermission(quoted.User, username, id, v) {
+		service.notify(models.Ntf_QUOTE, username, quoted.User, id)
 	}
 
 	return nil
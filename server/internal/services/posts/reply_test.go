@@ -0,0 +1,46 @@
+package posts
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestReplyPolicyOf(t *testing.T) {
+	cases := []struct {
+		literal string
+		dflt    models.ReplyPolicy
+		want    models.ReplyPolicy
+		err     error
+	}{
+		{"", models.Rpl_EVERYONE, models.Rpl_EVERYONE, nil},
+		{"", models.Rpl_NOBODY, models.Rpl_NOBODY, nil},
+		{"followers", models.Rpl_EVERYONE, models.Rpl_FOLLOWERS, nil},
+		{"mentioned", models.Rpl_EVERYONE, models.Rpl_MENTIONED, nil},
+		{"nobody", models.Rpl_EVERYONE, models.Rpl_NOBODY, nil},
+		{"friends", models.Rpl_EVERYONE, "", ErrReplyPolicy},
+	}
+	for _, v := range cases {
+		p, e := replyPolicyOf(v.literal, v.dflt)
+		test.AssertEqual(t, v.err, e)
+		test.AssertEqual(t, v.want, p)
+	}
+}
+
+func TestCanReply(t *testing.T) {
+	service := &PostService{}
+	cases := []struct {
+		user string
+		post models.Post
+		want bool
+	}{
+		{"u2", models.Post{User: "u1", ReplyPolicy: models.Rpl_EVERYONE}, true},
+		{"u2", models.Post{User: "u1", ReplyPolicy: models.Rpl_NOBODY}, false},
+		{"u1", models.Post{User: "u1", ReplyPolicy: models.Rpl_NOBODY}, true},
+		{"u1", models.Post{User: "u1", ReplyPolicy: models.Rpl_MENTIONED}, true},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, service.canReply(v.user, &v.post))
+	}
+}
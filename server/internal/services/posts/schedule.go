@@ -0,0 +1,291 @@
+package posts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// a post waiting to be published
+type Scheduled struct {
+	ID          string      `json:"id"` // id of the post when published
+	ScheduledAt string      `json:"scheduledAt"`
+	Visibility  string      `json:"visibility,omitempty"` // absent means by preference on publishing
+	Spoiler     string      `json:"spoiler,omitempty"`
+	Sensitive   *bool       `json:"sensitive,omitempty"`
+	Content     string      `json:"content"` // plain text
+	Attachments []AttachImg `json:"attachments,omitempty"`
+	Recipients  []string    `json:"recipients,omitempty"`
+	Poll        *NewPoll    `json:"poll,omitempty"`
+	Quoting     string      `json:"quoting,omitempty"`
+	Quotable    *bool       `json:"quotable,omitempty"`
+	ReplyPolicy string      `json:"replyPolicy,omitempty"`
+}
+
+const (
+	min_schedule_ahead   = 5 * time.Minute
+	scheduled_post_batch = 100
+	// a scheduled post claimed but not published in this long is claimed again
+	scheduled_post_lease = 5 * time.Minute
+)
+
+// ERRORS
+//
+//   - ContentEmpty
+//   - ContentTooLong
+//   - Poll
+//   - ReplyPolicy
+//   - Schedule
+//   - NoRecipient
+func (service *PostService) toScheduled(id, username, vsb, content string, attachments []AttachImg, recipients []string, opts Options, at time.Time) (s models.ScheduledPost, err error) {
+	now := time.Now()
+	if at.Before(now.Add(min_schedule_ahead)) {
+		return s, ErrSchedule
+	}
+	if e := service.checkContent(content, &opts); e != nil {
+		return s, e
+	}
+	if _, e := replyPolicyOf(opts.ReplyPolicy, models.Rpl_EVERYONE); e != nil {
+		return s, e
+	}
+	// checked again on publishing
+	if v, ok := utils.GetVsb(vsb); ok && v == utils.Vsb_DIRECT {
+		if len(service.recipientsOf(username, content, recipients, nil)) == 0 {
+			return s, ErrNoRecipient
+		}
+	}
+	if opts.Poll != nil {
+		if _, e := opts.Poll.toModel(id, now); e != nil {
+			return s, e
+		}
+	}
+
+	imgs := []models.Img{}
+	for i, v := range attachments {
+		if i >= service.maxImgInPost {
+			break
+		}
+		imgs = append(imgs, ToModelImg(v))
+	}
+	params := models.ScheduledParams{
+		Vsb: vsb, Content: content, Media: imgs, Recipients: recipients,
+		Spoiler: opts.spoiler(), Sensitive: opts.Sensitive,
+		Quoting: opts.Quoting, Quotable: opts.Quotable,
+		ReplyPolicy: opts.ReplyPolicy,
+	}
+	if opts.Poll != nil {
+		params.Poll = &models.ScheduledPoll{
+			Options: opts.Poll.Options, Multiple: opts.Poll.Multiple,
+			ExpiresIn: opts.Poll.ExpiresIn, HideTotals: opts.Poll.HideTotals,
+		}
+	}
+	return models.ScheduledPost{ID: id, User: username, Date: at, Params: params}, nil
+}
+
+func (service *PostService) makeScheduled(s *models.ScheduledPost) *Scheduled {
+	r := Scheduled{
+		ID:          s.ID,
+		ScheduledAt: s.Date.Format(time.RFC3339),
+		Visibility:  s.Params.Vsb,
+		Spoiler:     s.Params.Spoiler,
+		Sensitive:   s.Params.Sensitive,
+		Content:     s.Params.Content,
+		Attachments: service.makeAttachments(s.Params.Media),
+		Recipients:  s.Params.Recipients,
+		Quoting:     s.Params.Quoting,
+		Quotable:    s.Params.Quotable,
+		ReplyPolicy: s.Params.ReplyPolicy,
+	}
+	if p := s.Params.Poll; p != nil {
+		r.Poll = &NewPoll{
+			Options: p.Options, Multiple: p.Multiple,
+			ExpiresIn: p.ExpiresIn, HideTotals: p.HideTotals,
+		}
+	}
+	return &r
+}
+
+// schedule a post to be published at the time. recipients are used only when direct
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - ContentEmpty
+//   - ContentTooLong
+//   - Poll
+//   - ReplyPolicy
+//   - Schedule
+//   - NoRecipient
+//   - PostNotFound: quoted
+//   - NotPermitted: quoted
+//   - NotQuotable
+//   - Internal
+func (service *PostService) Schedule(username, vsb, content string, attachments []AttachImg, recipients []string, opts Options, at time.Time) (scheduled *Scheduled, err error) {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return nil, ErrUserNotFound
+	}
+	id := service.newID()
+	for service.db.Query.IsPostExist(id) {
+		id = service.newID()
+	}
+	s, e := service.toScheduled(id, username, vsb, content, attachments, recipients, opts, at)
+	if e != nil {
+		return nil, e
+	}
+	// checked again on publishing
+	if opts.Quoting != "" {
+		if _, e := service.quoted(username, opts.Quoting); e != nil {
+			return nil, e
+		}
+	}
+	if e := service.db.Scheduled.SetScheduledPost(&s); e != nil {
+		msg := fmt.Sprintf("[Posts.Schedule] Cannot schedule post of %s", username)
+		logger.Error(msg, e)
+		return nil, ErrInternal
+	}
+	return service.makeScheduled(&s), nil
+}
+
+// scheduled posts of the user, ascending by time
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - Internal
+func (service *PostService) GetScheduled(username string) (list []*Scheduled, err error) {
+	logger := service.lg
+	if !service.user.IsUserExist(username) {
+		return nil, ErrUserNotFound
+	}
+	result, e := service.db.Scheduled.QueryScheduledPosts(username)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Schedule] Cannot get scheduled posts of %s", username)
+		logger.Error(msg, e)
+		return nil, ErrInternal
+	}
+	list = make([]*Scheduled, 0, len(result))
+	for _, v := range result {
+		list = append(list, service.makeScheduled(v))
+	}
+	return list, nil
+}
+
+// replace a scheduled post of the user
+//
+// ERRORS
+//
+//   - ScheduledNotFound
+//   - ContentEmpty
+//   - ContentTooLong
+//   - Poll
+//   - ReplyPolicy
+//   - Schedule
+//   - NoRecipient
+//   - PostNotFound: quoted
+//   - NotPermitted: quoted
+//   - NotQuotable
+//   - Internal
+func (service *PostService) EditScheduled(username, id, vsb, content string, attachments []AttachImg, recipients []string, opts Options, at time.Time) (scheduled *Scheduled, err error) {
+	logger := service.lg
+	s, e := service.toScheduled(id, username, vsb, content, attachments, recipients, opts, at)
+	if e != nil {
+		return nil, e
+	}
+	// checked again on publishing
+	if opts.Quoting != "" {
+		if _, e := service.quoted(username, opts.Quoting); e != nil {
+			return nil, e
+		}
+	}
+	if e := service.db.Scheduled.UpdateScheduledPost(&s); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return nil, ErrScheduledNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Schedule] Cannot edit scheduled post %s", id)
+			logger.Error(msg, e)
+			return nil, ErrInternal
+		}
+	}
+	return service.makeScheduled(&s), nil
+}
+
+// ERRORS
+//
+//   - ScheduledNotFound
+//   - Internal
+func (service *PostService) CancelScheduled(username, id string) error {
+	logger := service.lg
+	s, e := service.db.Scheduled.QueryScheduledPost(id)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrScheduledNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Schedule] Cannot get scheduled post %s", id)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	if s.User != username {
+		return ErrScheduledNotFound
+	}
+	if e := service.db.Scheduled.RemoveScheduledPost(id); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrScheduledNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Schedule] Cannot cancel scheduled post %s", id)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// publish scheduled posts due by now, a batch at a time. used by the scheduled job.
+//
+// each is claimed by one process before publishing, so processes running the job
+// at the same time publish different ones. it's delivered only by the process
+// saving it. a scheduled post is published with its own id: if a process stops
+// after saving the post, the one claiming it after the lease finds the post
+// existing and only removes it, leaving it undelivered rather than delivered twice
+func (service *PostService) PublishScheduled(now time.Time) {
+	logger := service.lg
+	list, e := service.db.Scheduled.ClaimDueScheduledPosts(now, scheduled_post_lease, scheduled_post_batch)
+	if e != nil {
+		logger.Error("[Posts.Schedule] Cannot claim scheduled posts", e)
+		return
+	}
+	for _, s := range list {
+		service.publishScheduled(s)
+	}
+}
+
+func (service *PostService) publishScheduled(s *models.ScheduledPost) {
+	logger := service.lg
+	sc := service.makeScheduled(s)
+	opts := Options{
+		Spoiler: &sc.Spoiler, Sensitive: sc.Sensitive, Poll: sc.Poll,
+		Quoting: sc.Quoting, Quotable: sc.Quotable,
+		ReplyPolicy: sc.ReplyPolicy,
+	}
+	e := service.create(s.ID, s.User, sc.Visibility, sc.Content, sc.Attachments, sc.Recipients, opts)
+	switch e {
+	case nil, ErrPostExist:
+	case ErrInternal:
+		// claimed again when the lease expires
+		return
+	default:
+		logger.Warning("[Posts.Schedule] Dropped scheduled post",
+			"id", s.ID, "user", s.User, "reason", e.Error(),
+		)
+	}
+	if e := service.db.Scheduled.RemoveScheduledPost(s.ID); e != nil && e != models.ErrNotFound {
+		msg := fmt.Sprintf("[Posts.Schedule] Cannot remove scheduled post %s", s.ID)
+		logger.Error(msg, e)
+	}
+}
@@ -0,0 +1,167 @@
+package posts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+// scheduled posts with their claims
+type mockingScheduledDb struct {
+	models.IScheduledPost
+	posts   []*models.ScheduledPost // ascending by date
+	claimed map[string]time.Time
+	unsched []string // scheduled posts removed
+}
+
+func newMockingScheduledDb(posts ...*models.ScheduledPost) *mockingScheduledDb {
+	return &mockingScheduledDb{posts: posts, claimed: make(map[string]time.Time)}
+}
+
+func (db *mockingScheduledDb) ClaimDueScheduledPosts(now time.Time, lease time.Duration, limit int) ([]*models.ScheduledPost, error) {
+	list := make([]*models.ScheduledPost, 0)
+	for _, s := range db.posts {
+		c, ok := db.claimed[s.ID]
+		if s.Date.After(now) || (ok && c.After(now.Add(-lease))) || len(list) >= limit {
+			continue
+		}
+		db.claimed[s.ID] = now
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func (db *mockingScheduledDb) RemoveScheduledPost(id string) error {
+	for i, s := range db.posts {
+		if s.ID == id {
+			db.posts = append(db.posts[:i], db.posts[i+1:]...)
+			db.unsched = append(db.unsched, id)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func TestToScheduled(t *testing.T) {
+	service := &PostService{maxContentLength: 20, maxImgInPost: 1}
+	later := time.Now().Add(time.Hour)
+	spoiler := "a long content warning"
+	cases := []struct {
+		content string
+		opts    Options
+		at      time.Time
+		err     error
+	}{
+		{"hello", Options{}, later, nil},
+		{"hello", Options{}, time.Now().Add(time.Minute), ErrSchedule},
+		{"", Options{}, later, ErrContentEmpty},
+		{"hello", Options{Spoiler: &spoiler}, later, ErrContentTooLong},
+		{"hello", Options{Poll: &NewPoll{Options: []string{"a"}, ExpiresIn: 3600}}, later, ErrPoll},
+	}
+	for _, v := range cases {
+		_, err := service.toScheduled("s1", "u1", "", v.content, nil, nil, v.opts, v.at)
+		test.AssertEqual(t, v.err, err)
+	}
+}
+
+func TestScheduledRoundTrip(t *testing.T) {
+	service := &PostService{maxContentLength: 100, maxImgInPost: 1}
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	spoiler, sensitive := "cw", true
+	opts := Options{
+		Spoiler: &spoiler, Sensitive: &sensitive,
+		Poll: &NewPoll{Options: []string{"a", "b"}, ExpiresIn: 3600},
+	}
+	attachments := []AttachImg{
+		{Type: "image/png", Url: "site/imgs/1.png", Alt: "one"},
+		{Type: "image/png", Url: "site/imgs/2.png"},
+	}
+	s, err := service.toScheduled("s1", "u1", "followers", "hello", attachments, []string{"u2"}, opts, at)
+	test.AssertNoError(t, err)
+
+	sc := service.makeScheduled(&s)
+	test.AssertEqual(t, "s1", sc.ID)
+	test.AssertEqual(t, at.Format(time.RFC3339), sc.ScheduledAt)
+	test.AssertEqual(t, "followers", sc.Visibility)
+	test.AssertEqual(t, "cw", sc.Spoiler)
+	test.AssertEqual(t, &sensitive, sc.Sensitive)
+	test.AssertEqual(t, "hello", sc.Content)
+	test.AssertEqual(t, attachments[:1], sc.Attachments)
+	test.AssertEqual(t, []string{"u2"}, sc.Recipients)
+	test.AssertEqual(t, opts.Poll, sc.Poll)
+}
+
+func TestScheduleDirect(t *testing.T) {
+	db := newMockingDb("u1", "u2")
+	service := newTestService(t, db)
+	at := time.Now().Add(time.Hour)
+
+	_, err := service.toScheduled("s1", "u1", "direct", "hello", nil, nil, Options{}, at)
+	test.AssertEqual(t, ErrNoRecipient, err)
+	_, err = service.toScheduled("s1", "u1", "direct", "hello", nil, []string{"u3"}, Options{}, at)
+	test.AssertEqual(t, ErrNoRecipient, err)
+	_, err = service.toScheduled("s1", "u1", "direct", "hello @u2", nil, nil, Options{}, at)
+	test.AssertNoError(t, err)
+	_, err = service.Schedule("u1", "direct", "hello", nil, []string{"u1"}, Options{}, at)
+	test.AssertEqual(t, ErrNoRecipient, err)
+}
+
+func TestPublishScheduled(t *testing.T) {
+	db := newMockingDb("u1", "u2", "u3")
+	db.follows["u3>u1"] = true
+	db.timelines["u3"] = []models.TimelineItem{}
+	now := time.Now()
+	db.addPost(&models.Post{ID: "q", User: "u2", Date: now.Add(-time.Hour), Quotable: true})
+	poll := &models.ScheduledPoll{Options: []string{"a", "b"}, ExpiresIn: 3600}
+	scheduled := newMockingScheduledDb(
+		&models.ScheduledPost{ID: "s1", User: "u1", Date: now.Add(-time.Minute), Params: models.ScheduledParams{
+			Vsb: "public", Content: "hi @u2", Poll: poll, Quoting: "q",
+		}},
+		&models.ScheduledPost{ID: "s2", User: "u1", Date: now.Add(time.Hour), Params: models.ScheduledParams{
+			Vsb: "public", Content: "later",
+		}},
+	)
+	polls := &mockingPollDb{}
+	// processes running the job
+	a, b := newTestService(t, db), newTestService(t, db)
+	for _, service := range []*PostService{a, b} {
+		service.db.Scheduled, service.db.Poll = scheduled, polls
+	}
+
+	a.PublishScheduled(now)
+	test.AssertEqual(t, true, db.posts["s1"] != nil)
+	test.AssertEqual(t, []string{"s1"}, polls.polls)
+	test.AssertEqual(t, 2, len(db.notified))
+	test.AssertEqual(t, []string{"u2"}, quoteNotified(db))
+	test.AssertEqual(t, []string{"s1"}, idsOfItems(db.timelines["u3"]))
+	test.AssertEqual(t, []string{"s1"}, scheduled.unsched)
+
+	// claimed by a process stopped after saving the post
+	s3 := &models.ScheduledPost{ID: "s3", User: "u1", Date: now, Params: models.ScheduledParams{
+		Vsb: "public", Content: "bye @u2",
+	}}
+	scheduled.posts = append(scheduled.posts, s3)
+	claimed, _ := scheduled.ClaimDueScheduledPosts(now, scheduled_post_lease, scheduled_post_batch)
+	test.AssertEqual(t, []*models.ScheduledPost{s3}, claimed)
+	db.addPost(&models.Post{ID: "s3", User: "u1", Date: now, Source: "bye @u2"})
+
+	// left to it until the lease expires
+	b.PublishScheduled(now.Add(time.Minute))
+	test.AssertEqual(t, []string{"s1"}, scheduled.unsched)
+
+	// then removed without delivering it again
+	b.PublishScheduled(now.Add(scheduled_post_lease + time.Minute))
+	test.AssertEqual(t, []string{"s1", "s3"}, scheduled.unsched)
+	test.AssertEqual(t, 2, len(db.notified))
+	test.AssertEqual(t, []string{"s1"}, idsOfItems(db.timelines["u3"]))
+}
+
+func idsOfItems(items []models.TimelineItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, v := range items {
+		ids = append(ids, v.PostID)
+	}
+	return ids
+}
@@ -0,0 +1,69 @@
+package posts
+
+import (
+	"fmt"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+// posts matching the full-text query permitted to the user.
+// posts of users opting out of search are found only by themselves
+//
+// ERRORS
+//
+//   - Query
+//   - Internal
+func (service *PostService) Search(username, query string, page utils.Page) (list []*Post, links utils.PageLinks, err error) {
+	logger := service.lg
+	q, ok := utils.ParseQuery(query)
+	if !ok {
+		return nil, links, ErrQuery
+	}
+
+	posts, e := service.db.Search.SearchPosts(q, username, page)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Search] Cannot search posts of \"%s\"", q)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+	cursors := make([]utils.Cursor, 0, len(posts))
+	items := make([]models.TimelineItem, 0, len(posts))
+	ps := make(map[string]*models.Post)
+	for _, p := range posts {
+		cursors = append(cursors, p.Cursor())
+		items = append(items, toTimelineItem(p))
+		ps[p.ID] = p
+	}
+
+	// permission is checked again when making posts
+	return service.makeTimeline(username, items, ps), page.Links(cursors), nil
+}
+
+// hashtags of public posts matching the full-text query
+//
+// ERRORS
+//
+//   - Query
+//   - Internal
+func (service *PostService) SearchTags(query string, page utils.Page) (list []*Tag, links utils.PageLinks, err error) {
+	logger := service.lg
+	q, ok := utils.ParseQuery(query)
+	if !ok {
+		return nil, links, ErrQuery
+	}
+
+	tags, e := service.db.Search.SearchTags(q, page)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Search] Cannot search hashtags of \"%s\"", q)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+	list = make([]*Tag, 0, len(tags))
+	cursors := make([]utils.Cursor, 0, len(tags))
+	for _, t := range tags {
+		cursors = append(cursors, t.Cursor())
+		list = append(list, &Tag{Name: t.Tag, Url: service.getTagUrl(t.Tag)})
+	}
+	return list, page.Links(cursors), nil
+}
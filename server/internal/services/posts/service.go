@@ -1,10 +1,13 @@
 package posts
 
 import (
+	"fmt"
+
 	"github.com/google/uuid"
 	"github.com/kidommoc/gustrody/internal/config"
 	"github.com/kidommoc/gustrody/internal/logging"
 	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/emojis"
 	"github.com/kidommoc/gustrody/internal/services/users"
 	"github.com/kidommoc/gustrody/internal/utils"
 )
@@ -23,19 +26,61 @@ func ToModelImg(i AttachImg) models.Img {
 }
 
 type Post struct {
-	ID          string          `json:"id"`
-	Url         string          `json:"url"`
-	User        *users.UserInfo `json:"user"`
-	Date        string          `json:"date"`
-	ReplyTo     *users.UserInfo `json:"replyTo,omitempty"`
-	SharedBy    *users.UserInfo `json:"sharedBy,omitempty"`
-	Visibility  string          `json:"visibility"`
-	Content     string          `json:"content"`
-	Likes       int64           `json:"likes"`
-	Shares      int64           `json:"shares"`
-	Attachments []AttachImg     `json:"attachments,omitempty"`
-	Replyings   []*Post         `json:"replyings,omitempty"`
-	Replies     []*Post         `json:"replies,omitempty"`
+	ID          string            `json:"id"`
+	Url         string            `json:"url"`
+	User        *users.UserInfo   `json:"user"`
+	Published   string            `json:"published"`
+	EditedAt    string            `json:"editedAt,omitempty"`
+	ReplyTo     *users.UserInfo   `json:"replyTo,omitempty"`
+	InReplyTo   string            `json:"inReplyTo,omitempty"` // id of the post replied. only in a post and its context
+	SharedBy    *users.UserInfo   `json:"sharedBy,omitempty"`
+	Visibility  string            `json:"visibility"`
+	Spoiler     string            `json:"spoiler,omitempty"` // content warning, plain text
+	Sensitive   bool              `json:"sensitive"`
+	Content     string            `json:"content"` // html
+	Likes       int64             `json:"likes"`
+	Shares      int64             `json:"shares"`
+	Liked       bool              `json:"liked"`  // by the viewer
+	Shared      bool              `json:"shared"` // by the viewer
+	Reactions   []*Reaction       `json:"reactions,omitempty"`
+	Emojis      []*emojis.Emoji   `json:"emojis,omitempty"` // custom emoji in the spoiler and content
+	Attachments []AttachImg       `json:"attachments,omitempty"`
+	Recipients  []*users.UserInfo `json:"recipients,omitempty"` // only of direct posts
+	Mentions    []*Mention        `json:"mentions,omitempty"`
+	Tags        []*Tag            `json:"tags,omitempty"`
+	Poll        *Poll             `json:"poll,omitempty"`
+	Quoting     string            `json:"quoting,omitempty"` // id of the post quoted
+	Quote       *Post             `json:"quote,omitempty"`   // absent if removed or not permitted
+	Quotable    bool              `json:"quotable"`
+	ReplyPolicy string            `json:"replyPolicy"`        // who can reply besides the author
+	Bookmarked  bool              `json:"bookmarked"`         // by the viewer
+	Filtered    []*Filtered       `json:"filtered,omitempty"` // filters of the viewer with action warn matching it
+}
+
+// optional parts of a post
+type Options struct {
+	Spoiler   *string  // content warning. when nil, none on posting, or unchanged on editing
+	Sensitive *bool    // whether media are sensitive. when nil, by preference of the author on posting, or unchanged on editing
+	Poll      *NewPoll // only on posting
+	Quoting   string   // id of the post quoted, only on posting
+	Quotable  *bool    // whether others can quote it. when nil, true on posting, or unchanged on editing
+	// who can reply: everyone, followers, mentioned or nobody. when empty,
+	// everyone on posting, or unchanged on editing
+	ReplyPolicy string
+}
+
+// the spoiler given, or none
+func (opts *Options) spoiler() string {
+	if opts.Spoiler == nil {
+		return ""
+	}
+	return *opts.Spoiler
+}
+
+type Source struct {
+	ID      string `json:"id"`
+	Source  string `json:"source"`
+	Spoiler string `json:"spoiler"`
 }
 
 // services
@@ -45,6 +90,30 @@ type PostDbs struct {
 	Set   models.IPostSet
 	Like  models.IPostLike
 	Share models.IPostShare
+
+	Revision  models.IPostRevision
+	Poll      models.IPostPoll
+	Scheduled models.IScheduledPost
+	Bookmark  models.IPostBookmark
+	Viewer    models.IPostViewer
+	Reaction  models.IPostReaction
+	Emoji     models.ICustomEmoji
+	Search    models.IPostSearch
+	Filter    models.IFilter
+
+	Mention   models.IPostMention
+	Tag       models.IPostTag
+	TagFollow models.ITagFollow
+	Link      models.IPostLink
+
+	Timeline          models.IPostTimeline
+	TimelineCache     models.ITimeline
+	List              models.IListMember
+	ListTimelineCache models.IListTimeline
+
+	Notification models.INotification
+	Stream       models.IStream
+	Conversation models.IConversation
 }
 
 type PostService struct {
@@ -52,6 +121,7 @@ type PostService struct {
 	site             string
 	maxContentLength int
 	maxImgInPost     int
+	timelineLength   int
 	db               PostDbs
 	user             *users.UserService
 }
@@ -62,6 +132,7 @@ func NewService(us *users.UserService, dbs PostDbs, cfg config.Config, lg loggin
 		site:             cfg.Site,
 		maxContentLength: cfg.MaxContentLength,
 		maxImgInPost:     cfg.MaxImgInPost,
+		timelineLength:   cfg.TimelineLength,
 		db:               dbs,
 		user:             us,
 	}
@@ -75,14 +146,43 @@ func (service *PostService) getUrl(id string) string {
 	return service.site + "/posts/" + id
 }
 
+// notify owner of the post
+func (service *PostService) notify(t models.NtfType, actor, owner, postID string) {
+	logger := service.lg
+	n := models.Notification{User: owner, Type: t, Actor: actor, Post: postID}
+	if e := service.db.Notification.SetNotification(&n); e != nil {
+		msg := fmt.Sprintf("[Posts] Cannot notify %s of %s", owner, t)
+		logger.Error(msg, e)
+		return
+	}
+	if n.ID != 0 {
+		service.publish([]string{models.StreamOfUser(owner)}, models.StreamEvent{
+			Event: models.Event_NOTIFICATION, Notification: &n,
+		})
+	}
+}
+
+// publish event to streams
+func (service *PostService) publish(streams []string, e models.StreamEvent) {
+	logger := service.lg
+	if err := service.db.Stream.Publish(streams, e); err != nil {
+		msg := fmt.Sprintf("[Posts] Cannot publish %s", e.Event)
+		logger.Error(msg, err)
+	}
+}
+
 func (service *PostService) checkPermission(user, target, postID string, vsb utils.Vsb) bool {
 	switch vsb {
 	case utils.Vsb_FOLLOWER:
-		if user == "" || (user != target && !service.user.IsFollowing(user, target)) {
+		if user == "" {
 			return false
 		}
+		if user != target && !service.user.IsFollowing(user, target) {
+			// users mentioned are addressed
+			return postID != "" && service.db.Mention.IsPostMentioning(postID, user)
+		}
 	case utils.Vsb_DIRECT:
-		// not implement yet
+		return service.isParticipant(user, target, postID)
 	}
 	return true
 }
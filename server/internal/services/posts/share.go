@@ -9,53 +9,57 @@ import (
 	"github.com/kidommoc/gustrody/internal/utils"
 )
 
-func (service *PostService) GetShares(user, postID string) (list []*users.UserInfo, err error) {
+func (service *PostService) GetShares(user, postID string, page utils.Page) (list []*users.UserInfo, links utils.PageLinks, err error) {
 	logger := service.lg
-	result, owner, vsb, e := service.db.Share.QueryShares(postID)
+	result, owner, vsb, e := service.db.Share.QueryShares(postID, page)
 	if e != nil && e != models.ErrNotFound {
 		msg := fmt.Sprintf("[Posts.Share] Cannot get shares of %s", postID)
-		logger.Error(msg, err)
-		return nil, ErrInternal
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
 	}
 
 	if !service.checkPermission(user, owner, postID, vsb) {
-		return nil, ErrNotPermitted
+		return nil, links, ErrNotPermitted
 	}
 
 	if e != nil {
-		return list, ErrPostNotFound
+		return nil, links, ErrPostNotFound
 	}
 
-	us := make(map[string]*users.UserInfo)
-	gu := func(u string) *users.UserInfo {
-		if u == "" {
-			return nil
-		}
-		if us[u] != nil {
-			return us[u]
-		}
-		info, e := service.user.GetInfo(u)
+	list = make([]*users.UserInfo, 0, len(result))
+	cursors := make([]utils.Cursor, 0, len(result))
+	for _, v := range result {
+		cursors = append(cursors, v.Cursor())
+		info, e := service.user.GetInfo(v.User)
 		if e != nil {
-			msg := fmt.Sprintf("[Posts.Share] Cannot get info of %s", u)
+			msg := fmt.Sprintf("[Posts.Share] Cannot get info of %s", v.User)
 			logger.Error(msg, e)
-			return nil
-		}
-		us[u] = &info
-		return &info
-	}
-	list = make([]*users.UserInfo, 0, len(result))
-	for _, u := range result {
-		info := gu(u)
-		if info != nil {
-			list = append(list, info)
+			continue
 		}
+		list = append(list, &info)
 	}
 
-	return list, nil
+	return list, page.Links(cursors), nil
 }
 
+// direct posts cannot be shared
 func (service *PostService) Share(username, postID string, vsb string) error {
 	logger := service.lg
+	p, e := service.db.Query.QueryPostByID(postID)
+	if e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrPostNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Share] Cannot get %s", postID)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	if p.Vsb == utils.Vsb_DIRECT || !service.checkPermission(username, p.User, p.ID, p.Vsb) {
+		return ErrNotPermitted
+	}
+
 	v, ok := utils.GetVsb(vsb)
 	if !ok {
 		pf, err := service.user.GetPreferences(username)
@@ -66,7 +70,8 @@ func (service *PostService) Share(username, postID string, vsb string) error {
 		v = pf.ShareVsb
 	}
 
-	if err := service.db.Share.SetShare(username, postID, time.Now(), v); err != nil {
+	date := time.Now()
+	if err := service.db.Share.SetShare(username, postID, date, v); err != nil {
 		switch {
 		case err == models.ErrNotFound:
 			return ErrPostNotFound
@@ -76,6 +81,10 @@ func (service *PostService) Share(username, postID string, vsb string) error {
 			return ErrInternal
 		}
 	}
+	service.fanout(username, v, "", nil, models.TimelineItem{
+		PostID: postID, SharedBy: username, Date: date,
+	})
+	service.notify(models.Ntf_SHARE, username, p.User, postID)
 	return nil
 }
 
@@ -96,5 +105,8 @@ func (service *PostService) Unshare(username, postID string) error {
 			return ErrInternal
 		}
 	}
+	service.fanoutRemove(username, utils.Vsb_PUBLIC, nil, models.TimelineItem{
+		PostID: postID, SharedBy: username,
+	})
 	return nil
 }
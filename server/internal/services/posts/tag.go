@@ -0,0 +1,202 @@
+package posts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+type Tag struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+const max_tag_length = 100
+
+// "#tag" not in the middle of a word, an url or an html entity. tags of digits only are not tags
+var tagRegexp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#])#([\p{L}\p{N}_]*[\p{L}_][\p{L}\p{N}_]*)`)
+var tagNameRegexp = regexp.MustCompile(`^[\p{L}\p{N}_]*[\p{L}_][\p{L}\p{N}_]*$`)
+
+// hashtags are case-insensitive
+func normalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	if utf8.RuneCountInString(tag) > max_tag_length || !tagNameRegexp.MatchString(tag) {
+		return "", false
+	}
+	return tag, true
+}
+
+// hashtags in the content, normalized, in order and deduplicated
+func parseTags(content string) []string {
+	list := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range tagRegexp.FindAllStringSubmatch(content, -1) {
+		t, ok := normalizeTag(m[1])
+		if !ok || seen[t] {
+			continue
+		}
+		seen[t] = true
+		list = append(list, t)
+	}
+	return list
+}
+
+func (service *PostService) getTagUrl(tag string) string {
+	return service.site + "/tags/" + tag
+}
+
+// save hashtags of the post
+func (service *PostService) saveTags(postID string, tags, old []string) {
+	logger := service.lg
+	if len(tags) == 0 && len(old) == 0 {
+		return
+	}
+	if e := service.db.Tag.SetTags(postID, tags); e != nil {
+		msg := fmt.Sprintf("[Posts.Tag] Cannot save hashtags of %s", postID)
+		logger.Error(msg, e)
+	}
+}
+
+// hashtags of the post
+func (service *PostService) queryTags(postID string) []string {
+	logger := service.lg
+	m, e := service.db.Tag.QueryTags([]string{postID})
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Tag] Cannot get hashtags of %s", postID)
+		logger.Error(msg, e)
+		return []string{}
+	}
+	return m[postID]
+}
+
+// users following any hashtag of the post. only public posts reach them
+func (service *PostService) tagFollowers(vsb utils.Vsb, tags []string) []string {
+	logger := service.lg
+	if vsb != utils.Vsb_PUBLIC || len(tags) == 0 {
+		return []string{}
+	}
+	list, e := service.db.TagFollow.QueryTagFollowers(tags)
+	if e != nil {
+		logger.Error("[Posts.Tag] Cannot get followers of hashtags", e)
+		return []string{}
+	}
+	return list
+}
+
+// streams of hashtags of the post. only public posts are streamed
+func tagStreams(vsb utils.Vsb, tags []string) []string {
+	streams := make([]string, 0, len(tags))
+	if vsb != utils.Vsb_PUBLIC {
+		return streams
+	}
+	for _, t := range tags {
+		streams = append(streams, models.StreamOfHashtag(t))
+	}
+	return streams
+}
+
+// set hashtags of posts in bulk
+func (service *PostService) setTags(list []*Post) {
+	logger := service.lg
+	ids := make([]string, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	m, e := service.db.Tag.QueryTags(ids)
+	if e != nil {
+		logger.Error("[Posts.Tag] Cannot get hashtags", e)
+		return
+	}
+	for _, p := range list {
+		for _, t := range m[p.ID] {
+			p.Tags = append(p.Tags, &Tag{Name: t, Url: service.getTagUrl(t)})
+		}
+	}
+}
+
+// public posts with the hashtag
+//
+// ERRORS
+//
+//   - Tag
+//   - Internal
+func (service *PostService) GetByTag(username, tag string, page utils.Page) (list []*Post, links utils.PageLinks, err error) {
+	logger := service.lg
+	t, ok := normalizeTag(tag)
+	if !ok {
+		return nil, links, ErrTag
+	}
+
+	posts, e := service.db.Tag.QueryTagTimeline(t, username, page)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Tag] Cannot query timeline of #%s", t)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+	cursors := make([]utils.Cursor, 0, len(posts))
+	items := make([]models.TimelineItem, 0, len(posts))
+	ps := make(map[string]*models.Post)
+	for _, p := range posts {
+		cursors = append(cursors, p.Cursor())
+		items = append(items, toTimelineItem(p))
+		ps[p.ID] = p
+	}
+
+	list = service.ApplyFilters(username, models.Flc_PUBLIC, service.makeTimeline(username, items, ps))
+	return list, page.Links(cursors), nil
+}
+
+// public posts with the hashtag will land in the home timeline of the user
+//
+// ERRORS
+//
+//   - UserNotFound
+//   - Tag
+//   - Internal
+func (service *PostService) FollowTag(username, tag string) error {
+	logger := service.lg
+	t, ok := normalizeTag(tag)
+	if !ok {
+		return ErrTag
+	}
+	if e := service.db.TagFollow.SetTagFollow(username, t, time.Now()); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrUserNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Tag] Cannot have %s follow #%s", username, t)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// ERRORS
+//
+//   - Tag
+//   - TagFollowNotFound
+//   - Internal
+func (service *PostService) UnfollowTag(username, tag string) error {
+	logger := service.lg
+	t, ok := normalizeTag(tag)
+	if !ok {
+		return ErrTag
+	}
+	if e := service.db.TagFollow.RemoveTagFollow(username, t); e != nil {
+		switch e {
+		case models.ErrNotFound:
+			return ErrTagFollowNotFound
+		default:
+			msg := fmt.Sprintf("[Posts.Tag] Cannot have %s unfollow #%s", username, t)
+			logger.Error(msg, e)
+			return ErrInternal
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package posts
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+// no hashtags saved, and nobody following them
+type mockingTagDb struct {
+	models.IPostTag
+	models.ITagFollow
+}
+
+func (db mockingTagDb) SetTags(id string, tags []string) error {
+	return nil
+}
+
+func (db mockingTagDb) QueryTags(ids []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (db mockingTagDb) QueryTagFollowers(tags []string) ([]string, error) {
+	return []string{}, nil
+}
+
+func TestParseTags(t *testing.T) {
+	cases := []struct {
+		content string
+		want    []string
+	}{
+		{"#Go and #go, #rust.", []string{"go", "rust"}},
+		{"#1 is not, #2024_review is", []string{"2024_review"}},
+		{"a#b https://site.sns/#c &#35; ##d", []string{}},
+		{"#日本語 #Straße", []string{"日本語", "straße"}},
+	}
+	for _, v := range cases {
+		test.AssertEqual(t, v.want, parseTags(v.content))
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	cases := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{"Go", "go", true},
+		{"#Go", "go", true},
+		{"123", "", false},
+		{"go-lang", "", false},
+		{"", "", false},
+	}
+	for _, v := range cases {
+		tag, ok := normalizeTag(v.tag)
+		test.AssertEqual(t, v.ok, ok)
+		test.AssertEqual(t, v.want, tag)
+	}
+}
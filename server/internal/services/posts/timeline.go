@@ -0,0 +1,348 @@
+package posts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/users"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+const timeline_page = 20
+
+type Timeline struct {
+	List []*Post `json:"list"`
+	Next string  `json:"next,omitempty"` // pass as "from" to get the next page
+}
+
+// position in a timeline. id is used to break ties of date
+type cursor struct {
+	date time.Time
+	id   string
+}
+
+// "<unix microseconds>[_<id>]"
+func (c cursor) String() string {
+	s := strconv.FormatInt(c.date.UnixMicro(), 10)
+	if c.id != "" {
+		s += "_" + c.id
+	}
+	return s
+}
+
+func parseCursor(from string) (c cursor, ok bool) {
+	if from == "" {
+		return c, true
+	}
+	fields := strings.SplitN(from, "_", 2)
+	us, e := strconv.ParseInt(fields[0], 10, 64)
+	if e != nil || us < 0 {
+		return c, false
+	}
+	c.date = time.UnixMicro(us)
+	if len(fields) > 1 {
+		c.id = fields[1]
+	}
+	return c, true
+}
+
+// position of an item in cached timelines, where dates are in milliseconds
+func itemCursor(item models.TimelineItem) cursor {
+	return cursor{date: item.Date.Truncate(time.Millisecond), id: item.ID()}
+}
+
+func toTimelineItem(p *models.Post) (item models.TimelineItem) {
+	item = models.TimelineItem{PostID: p.ID, SharedBy: p.SharedBy, Date: p.Date}
+	if act, e := time.Parse(time.RFC3339Nano, p.ActDate); e == nil {
+		item.Date = act
+	}
+	return item
+}
+
+// the actor, local followers of the actor and local users addressed.
+// only the actor and local recipients when direct
+func (service *PostService) audience(username string, vsb utils.Vsb, addressed []string) []string {
+	logger := service.lg
+	if vsb == utils.Vsb_DIRECT {
+		return localParticipants(username, addressed)
+	}
+	list := []string{username}
+	// remote users read their timelines on their own sites
+	fo, e := service.user.GetLocalFollowers(username)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot get followers of %s", username)
+		logger.Error(msg, e)
+	}
+	list = append(list, fo...)
+	seen := make(map[string]bool)
+	for _, u := range list {
+		seen[u] = true
+	}
+	for _, u := range localParticipants(username, addressed)[1:] {
+		if !seen[u] {
+			seen[u] = true
+			list = append(list, u)
+		}
+	}
+	return list
+}
+
+// streams of users in the audience, and public streams if the post is public and not replying
+func (service *PostService) streamsOf(us []string, vsb utils.Vsb, replying string) []string {
+	streams := make([]string, 0, len(us)+2)
+	for _, u := range us {
+		streams = append(streams, models.StreamOfUser(u))
+	}
+	if vsb == utils.Vsb_PUBLIC && replying == "" {
+		streams = append(streams, models.Stream_PUBLIC, models.Stream_PUBLIC_LOCAL)
+	}
+	return streams
+}
+
+// push a post or share into timelines of the audience and lists of the actor.
+// replyTo is the user replied, empty if not a reply
+func (service *PostService) fanout(username string, vsb utils.Vsb, replyTo string, addressed []string, item models.TimelineItem) {
+	logger := service.lg
+	us := service.audience(username, vsb, addressed)
+	if e := service.db.TimelineCache.PushTimeline(us, item, service.timelineLength); e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot fan out %s", item.PostID)
+		logger.Error(msg, e)
+	}
+	ls := service.listsOf(username, vsb, replyTo)
+	if e := service.db.ListTimelineCache.PushListTimeline(ls, item, service.timelineLength); e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot fan out %s to lists", item.PostID)
+		logger.Error(msg, e)
+	}
+	service.publish(append(service.streamsOf(us, utils.Vsb_DIRECT, ""), listStreams(ls)...), models.StreamEvent{
+		Event: models.Event_UPDATE, Item: &item,
+	})
+}
+
+// remove a post or share from timelines of the audience and lists of the actor.
+// returns the audience and the lists
+func (service *PostService) fanoutRemove(username string, vsb utils.Vsb, addressed []string, item models.TimelineItem) (us []string, ls []string) {
+	logger := service.lg
+	us = service.audience(username, vsb, addressed)
+	if e := service.db.TimelineCache.RemoveFromTimeline(us, item); e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot remove %s from timelines", item.PostID)
+		logger.Error(msg, e)
+	}
+	ls = service.listsOf(username, vsb, "")
+	if e := service.db.ListTimelineCache.RemoveFromListTimeline(ls, item); e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot remove %s from list timelines", item.PostID)
+		logger.Error(msg, e)
+	}
+	return us, ls
+}
+
+// rebuild cached home timeline from main database
+func (service *PostService) rebuildHome(username string) error {
+	logger := service.lg
+	posts, e := service.db.Timeline.QueryHomeTimeline(username, time.Time{}, "", service.timelineLength)
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot query home timeline of %s", username)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	items := make([]models.TimelineItem, 0, len(posts))
+	for _, p := range posts {
+		items = append(items, toTimelineItem(p))
+	}
+	if e := service.db.TimelineCache.SetTimeline(username, items); e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot cache home timeline of %s", username)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+	return nil
+}
+
+// turn timeline items to posts in bulk, dropping ones removed or not permitted
+func (service *PostService) hydrate(username string, items []models.TimelineItem) (list []*Post) {
+	logger := service.lg
+	ids := make([]string, 0, len(items))
+	for _, v := range items {
+		ids = append(ids, v.PostID)
+	}
+	result, e := service.db.Query.QueryPostsByIDs(ids)
+	if e != nil {
+		logger.Error("[Posts.Timeline] Cannot query posts", e)
+		return make([]*Post, 0)
+	}
+	ps := make(map[string]*models.Post)
+	for _, p := range result {
+		ps[p.ID] = p
+	}
+	return service.makeTimeline(username, items, ps)
+}
+
+// turn timeline items to posts in bulk, dropping ones removed or not permitted
+func (service *PostService) GetTimelineItems(username string, items []models.TimelineItem) []*Post {
+	return service.hydrate(username, items)
+}
+
+// make posts of the items with posts they quote, dropping ones not found or not permitted
+func (service *PostService) makeTimeline(username string, items []models.TimelineItem, ps map[string]*models.Post) (list []*Post) {
+	list = service.makePosts(username, items, ps)
+	service.setQuotes(username, list)
+	return list
+}
+
+// make posts of the items, dropping ones not found or not permitted
+func (service *PostService) makePosts(username string, items []models.TimelineItem, ps map[string]*models.Post) (list []*Post) {
+	logger := service.lg
+	us := make(map[string]*users.UserInfo)
+	gu := func(u string) *users.UserInfo {
+		if u == "" {
+			return nil
+		}
+		if us[u] != nil {
+			return us[u]
+		}
+		ui, e := service.user.GetInfo(u)
+		if e != nil {
+			msg := fmt.Sprintf("[Posts.Timeline] Cannot get info of %s", u)
+			logger.Error(msg, e)
+			return nil
+		}
+		us[u] = &ui
+		return &ui
+	}
+	permitted := make(map[string]bool)
+	list = make([]*Post, 0, len(items))
+	for _, v := range items {
+		p := ps[v.PostID]
+		if p == nil {
+			continue
+		}
+		ok, checked := permitted[p.ID]
+		if !checked {
+			ok = service.checkPermission(username, p.User, p.ID, p.Vsb)
+			permitted[p.ID] = ok
+		}
+		if !ok {
+			continue
+		}
+		u := gu(p.User)
+		if u == nil {
+			continue
+		}
+		post, e := service.makePost(p, u)
+		if e != nil {
+			continue
+		}
+		post.ReplyTo = gu(p.ReplyTo)
+		post.SharedBy = gu(v.SharedBy)
+		list = append(list, &post)
+	}
+	service.setMentions(list)
+	service.setTags(list)
+	service.setPolls(username, list)
+	service.setViewerStates(username, list)
+	service.setReactions(username, list)
+	service.setEmojis(list)
+	return list
+}
+
+func (service *PostService) GetHome(username, from string) (tl Timeline, err error) {
+	if !service.user.IsUserExist(username) {
+		return tl, ErrUserNotFound
+	}
+	return service.readCached(username, from, timelineSource{
+		name:    "home timeline of " + username,
+		context: models.Flc_HOME,
+		cached: func(before time.Time, beforeID string, count int) ([]models.TimelineItem, error) {
+			return service.db.TimelineCache.QueryTimeline(username, before, beforeID, count)
+		},
+		rebuild: func() error { return service.rebuildHome(username) },
+		query: func(before time.Time, beforeID string, limit int) ([]*models.Post, error) {
+			return service.db.Timeline.QueryHomeTimeline(username, before, beforeID, limit)
+		},
+	})
+}
+
+// a timeline cached in part, read from main database beyond the cache.
+// both are read before (date, id), where dates are in milliseconds as scores
+// of the cache, and ids are of items, breaking ties. see models.TimelineItem
+type timelineSource struct {
+	name    string // for logging
+	context models.FilterContext
+	cached  func(before time.Time, beforeID string, count int) ([]models.TimelineItem, error)
+	rebuild func() error
+	query   func(before time.Time, beforeID string, limit int) ([]*models.Post, error)
+}
+
+func (service *PostService) readCached(username, from string, src timelineSource) (tl Timeline, err error) {
+	logger := service.lg
+	c, ok := parseCursor(from)
+	if !ok {
+		return tl, ErrCursor
+	}
+	before := c.date.Truncate(time.Millisecond)
+
+	items, e := src.cached(before, c.id, timeline_page)
+	if e == models.ErrNotFound {
+		if e := src.rebuild(); e != nil {
+			return tl, e
+		}
+		items, e = src.cached(before, c.id, timeline_page)
+	}
+	if e != nil {
+		msg := fmt.Sprintf("[Posts.Timeline] Cannot get %s", src.name)
+		logger.Error(msg, e)
+		return tl, ErrInternal
+	}
+
+	// older than the cached ones. read from main database
+	if len(items) < timeline_page {
+		last := cursor{date: before, id: c.id}
+		if len(items) != 0 {
+			last = itemCursor(items[len(items)-1])
+		}
+		posts, e := src.query(last.date, last.id, timeline_page-len(items))
+		if e != nil {
+			msg := fmt.Sprintf("[Posts.Timeline] Cannot query %s", src.name)
+			logger.Error(msg, e)
+		}
+		for _, p := range posts {
+			items = append(items, toTimelineItem(p))
+		}
+	}
+
+	// pages are counted before filtering
+	tl.List = service.ApplyFilters(username, src.context, service.hydrate(username, items))
+	if len(items) != 0 {
+		tl.Next = itemCursor(items[len(items)-1]).String()
+	}
+	return tl, nil
+}
+
+// public posts not replying. when local, only posts of this site
+func (service *PostService) GetPublic(username, from string, local bool) (tl Timeline, err error) {
+	logger := service.lg
+	c, ok := parseCursor(from)
+	if !ok {
+		return tl, ErrCursor
+	}
+
+	posts, e := service.db.Timeline.QueryPublicTimeline(username, local, c.date, c.id, timeline_page)
+	if e != nil {
+		logger.Error("[Posts.Timeline] Cannot query public timeline", e)
+		return tl, ErrInternal
+	}
+	items := make([]models.TimelineItem, 0, len(posts))
+	ps := make(map[string]*models.Post)
+	for _, p := range posts {
+		items = append(items, toTimelineItem(p))
+		ps[p.ID] = p
+	}
+
+	tl.List = service.ApplyFilters(username, models.Flc_PUBLIC, service.makeTimeline(username, items, ps))
+	if len(posts) != 0 {
+		last := posts[len(posts)-1]
+		tl.Next = cursor{date: last.Date, id: last.ID}.String()
+	}
+	return tl, nil
+}
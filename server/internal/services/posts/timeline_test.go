@@ -0,0 +1,140 @@
+package posts
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
+func idsOf(list []*Post) []string {
+	ids := make([]string, 0, len(list))
+	for _, v := range list {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func TestCursor(t *testing.T) {
+	date := time.UnixMicro(1700000000123456)
+	c, ok := parseCursor(cursor{date: date, id: "a_b"}.String())
+	test.AssertEqual(t, true, ok)
+	test.AssertEqual(t, true, c.date.Equal(date))
+	test.AssertEqual(t, "a_b", c.id)
+
+	c, ok = parseCursor("")
+	test.AssertEqual(t, true, ok)
+	test.AssertEqual(t, true, c.date.IsZero())
+	for _, v := range []string{"x", "-1", "_a"} {
+		_, ok := parseCursor(v)
+		test.AssertEqual(t, false, ok)
+	}
+}
+
+func TestGetPublic(t *testing.T) {
+	db := newMockingDb("u1", "u2")
+	service := newTestService(t, db)
+	now := time.Now().Truncate(time.Microsecond)
+	db.addPost(&models.Post{ID: "a", User: "u1", Date: now.Add(-1 * time.Minute)})
+	db.addPost(&models.Post{ID: "b", User: "r@remote.example", Date: now.Add(-2 * time.Minute)})
+	db.addPost(&models.Post{ID: "c", User: "u2", Date: now.Add(-3 * time.Minute)})
+	db.addPost(&models.Post{ID: "d", User: "u2", Date: now.Add(-3 * time.Minute)})
+	db.addPost(&models.Post{ID: "e", User: "u2", Date: now, Replying: "a"})
+
+	// anonymous visitors can read it
+	tl, err := service.GetPublic("", "", false)
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"a", "b", "d", "c"}, idsOf(tl.List))
+	test.AssertEqual(t, false, db.publicArgs[1])
+
+	tl, err = service.GetPublic("u1", "", true)
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"a", "d", "c"}, idsOf(tl.List))
+	test.AssertEqual(t, true, db.publicArgs[1])
+	test.AssertEqual(t, "u1", db.publicArgs[0])
+
+	// ties of date are broken by id
+	next := cursor{date: now.Add(-3 * time.Minute), id: "d"}.String()
+	tl, err = service.GetPublic("u1", next, false)
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"c"}, idsOf(tl.List))
+	test.AssertEqual(t, cursor{date: now.Add(-3 * time.Minute), id: "c"}.String(), tl.Next)
+
+	_, err = service.GetPublic("u1", "x", false)
+	test.AssertEqual(t, ErrCursor, err)
+}
+
+func TestGetHome(t *testing.T) {
+	db := newMockingDb("u1", "u2")
+	service := newTestService(t, db)
+	db.follows["u1>u2"] = true
+	now := time.Now().Truncate(time.Microsecond)
+	list := make([]*models.Post, 0)
+	for i, id := range []string{"p1", "p2", "p3"} {
+		list = append(list, db.addPost(&models.Post{
+			ID: id, User: "u2", Vsb: utils.Vsb_FOLLOWER,
+			Date: now.Add(-time.Duration(i+1) * time.Minute),
+		}))
+	}
+	db.home["u1"] = list
+
+	// rebuilt when not cached
+	tl, err := service.GetHome("u1", "")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"p1", "p2", "p3"}, idsOf(tl.List))
+	test.AssertEqual(t, 3, len(db.timelines["u1"]))
+	next := cursor{date: now.Add(-3 * time.Minute).Truncate(time.Millisecond), id: "p3"}
+	test.AssertEqual(t, next.String(), tl.Next)
+
+	// older than the cached ones are read from main database
+	db.timelines["u1"] = db.timelines["u1"][:1]
+	tl, err = service.GetHome("u1", "")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"p1", "p2", "p3"}, idsOf(tl.List))
+
+	tl, err = service.GetHome("u1", tl.Next)
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 0, len(tl.List))
+
+	_, err = service.GetHome("u3", "")
+	test.AssertEqual(t, ErrUserNotFound, err)
+}
+
+func TestGetHomeSameMillisecond(t *testing.T) {
+	db := newMockingDb("u1", "u2")
+	service := newTestService(t, db)
+	db.follows["u1>u2"] = true
+	date := time.Now().Truncate(time.Millisecond)
+	list := make([]*models.Post, 0)
+	for i := 1; i <= 25; i++ {
+		list = append(list, db.addPost(&models.Post{
+			ID: fmt.Sprintf("p%02d", i), User: "u2", Vsb: utils.Vsb_FOLLOWER,
+			Date: date.Add(time.Duration(i) * time.Microsecond),
+		}))
+	}
+	db.home["u1"] = list
+
+	read := func() []string {
+		ids := make([]string, 0)
+		tl, err := service.GetHome("u1", "")
+		for err == nil && len(tl.List) != 0 {
+			ids = append(ids, idsOf(tl.List)...)
+			tl, err = service.GetHome("u1", tl.Next)
+		}
+		test.AssertNoError(t, err)
+		return ids
+	}
+	want := make([]string, 0)
+	for i := 25; i >= 1; i-- {
+		want = append(want, fmt.Sprintf("p%02d", i))
+	}
+
+	// pages break within the millisecond, in the cache
+	test.AssertEqual(t, want, read())
+	// and beyond the cache, in main database
+	db.timelines["u1"] = db.timelines["u1"][:10]
+	test.AssertEqual(t, want, read())
+}
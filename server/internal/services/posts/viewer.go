@@ -0,0 +1,25 @@
+package posts
+
+// set whether the user liked, shared or bookmarked posts, in bulk
+func (service *PostService) setViewerStates(username string, list []*Post) {
+	logger := service.lg
+	if username == "" || len(list) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	m, e := service.db.Viewer.QueryViewerStates(ids, username)
+	if e != nil {
+		logger.Error("[Posts.Viewer] Cannot get states of viewer", e)
+		return
+	}
+	for _, p := range list {
+		if s := m[p.ID]; s != nil {
+			p.Liked = s.Liked
+			p.Shared = s.Shared
+			p.Bookmarked = s.Bookmarked
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package posts
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+// states of one viewer
+type mockingViewerDb struct {
+	models.IPostViewer
+	viewer string
+	states map[string]*models.ViewerState
+}
+
+func (db mockingViewerDb) QueryViewerStates(ids []string, user string) (map[string]*models.ViewerState, error) {
+	m := make(map[string]*models.ViewerState)
+	if user != db.viewer {
+		return m, nil
+	}
+	for _, id := range ids {
+		if s := db.states[id]; s != nil {
+			m[id] = s
+		}
+	}
+	return m, nil
+}
+
+func TestSetViewerStates(t *testing.T) {
+	service := &PostService{lg: test.NewMockingLogger(t)}
+	service.db.Viewer = mockingViewerDb{viewer: "u1", states: map[string]*models.ViewerState{
+		"a": {Liked: true, Bookmarked: true},
+		"b": {Shared: true},
+	}}
+	list := []*Post{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	service.setViewerStates("u1", list)
+	test.AssertEqual(t, []bool{true, false, true}, []bool{list[0].Liked, list[0].Shared, list[0].Bookmarked})
+	test.AssertEqual(t, []bool{false, true, false}, []bool{list[1].Liked, list[1].Shared, list[1].Bookmarked})
+	test.AssertEqual(t, []bool{false, false, false}, []bool{list[2].Liked, list[2].Shared, list[2].Bookmarked})
+
+	// nothing of anonymous viewers
+	list = []*Post{{ID: "a"}}
+	service.setViewerStates("", list)
+	test.AssertEqual(t, false, list[0].Liked)
+}
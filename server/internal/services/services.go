@@ -8,8 +8,15 @@ import (
 	"github.com/kidommoc/gustrody/internal/logging"
 	"github.com/kidommoc/gustrody/internal/models"
 	"github.com/kidommoc/gustrody/internal/services/auth"
+	"github.com/kidommoc/gustrody/internal/services/conversations"
+	"github.com/kidommoc/gustrody/internal/services/emojis"
 	"github.com/kidommoc/gustrody/internal/services/files"
+	"github.com/kidommoc/gustrody/internal/services/filters"
+	"github.com/kidommoc/gustrody/internal/services/lists"
+	"github.com/kidommoc/gustrody/internal/services/notifications"
 	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/streaming"
+	"github.com/kidommoc/gustrody/internal/services/trends"
 	"github.com/kidommoc/gustrody/internal/services/users"
 )
 
@@ -46,6 +53,15 @@ func Init() {
 	authModel := models.AuthInstance(lg)
 	userModel := models.UserInstance(lg)
 	postModel := models.PostInstance(lg)
+	timelineModel := models.TimelineInstance(lg)
+	notificationModel := models.NotificationInstance(lg)
+	streamModel := models.StreamInstance(lg)
+	conversationModel := models.ConversationInstance(lg)
+	emojiModel := models.EmojiInstance(lg)
+	listModel := models.ListInstance(lg)
+	filterModel := models.FilterInstance(lg)
+	trendModel := models.TrendInstance(lg)
+	trendCacheModel := models.TrendCacheInstance(lg)
 
 	var ap *auth.OauthService
 	at := reflect.TypeOf(ap)
@@ -53,30 +69,117 @@ func Init() {
 		services[at] = auth.NewService(authModel, lg)
 	}
 
+	var fp *files.FileService
+	ft := reflect.TypeOf(fp)
+	if services[ft] == nil {
+		services[ft] = files.NewService(cfg, lg)
+	}
+
+	var ep *emojis.EmojiService
+	et := reflect.TypeOf(ep)
+	if services[et] == nil {
+		emojiDbs := emojis.EmojiDbs{
+			Emoji: emojiModel,
+		}
+		fs, _ := services[ft].(*files.FileService)
+		services[et] = emojis.NewService(fs, emojiDbs, cfg, lg)
+	}
+
 	var up *users.UserService
 	ut := reflect.TypeOf(up)
 	if services[ut] == nil {
 		userDbs := users.UserDbs{
 			Account: userModel, Info: userModel,
-			Follow: userModel, Auth: authModel,
+			Follow: userModel, Mute: userModel, TimelineCache: timelineModel,
+			List: listModel, ListTimelineCache: timelineModel,
+			Auth: authModel, Emoji: emojiModel, Search: userModel,
+			Notification: notificationModel, Stream: streamModel,
 		}
 		services[ut] = users.NewService(userDbs, cfg, lg)
 	}
 
+	var flp *filters.FilterService
+	flt := reflect.TypeOf(flp)
+	if services[flt] == nil {
+		filterDbs := filters.FilterDbs{
+			Filter: filterModel,
+		}
+		us, _ := services[ut].(*users.UserService)
+		services[flt] = filters.NewService(us, filterDbs, cfg, lg)
+	}
+
 	var pp *posts.PostService
 	pt := reflect.TypeOf(pp)
 	if services[pt] == nil {
 		postDbs := posts.PostDbs{
 			Query: postModel, Set: postModel,
 			Like: postModel, Share: postModel,
+			Revision: postModel, Poll: postModel,
+			Scheduled: postModel, Bookmark: postModel,
+			Viewer: postModel, Reaction: postModel, Emoji: emojiModel,
+			Search: postModel, Mention: postModel, Tag: postModel, TagFollow: postModel,
+			Link: postModel, Timeline: postModel, TimelineCache: timelineModel,
+			List: listModel, ListTimelineCache: timelineModel,
+			Notification: notificationModel, Stream: streamModel,
+			Conversation: conversationModel, Filter: filterModel,
 		}
 		us, _ := services[ut].(*users.UserService)
 		services[pt] = posts.NewService(us, postDbs, cfg, lg)
 	}
 
-	var fp *files.FileService
-	ft := reflect.TypeOf(fp)
-	if services[ft] == nil {
-		services[ft] = files.NewService(cfg, lg)
+	var tp *trends.TrendService
+	tt := reflect.TypeOf(tp)
+	if services[tt] == nil {
+		trendDbs := trends.TrendDbs{
+			Trend: trendModel, TrendCache: trendCacheModel,
+		}
+		ps, _ := services[pt].(*posts.PostService)
+		services[tt] = trends.NewService(ps, trendDbs, cfg, lg)
+	}
+
+	var np *notifications.NotificationService
+	nt := reflect.TypeOf(np)
+	if services[nt] == nil {
+		notificationDbs := notifications.NotificationDbs{
+			Notification: notificationModel,
+		}
+		us, _ := services[ut].(*users.UserService)
+		ps, _ := services[pt].(*posts.PostService)
+		services[nt] = notifications.NewService(us, ps, notificationDbs, cfg, lg)
+	}
+
+	var cp *conversations.ConversationService
+	ct := reflect.TypeOf(cp)
+	if services[ct] == nil {
+		conversationDbs := conversations.ConversationDbs{
+			Conversation: conversationModel,
+		}
+		us, _ := services[ut].(*users.UserService)
+		ps, _ := services[pt].(*posts.PostService)
+		services[ct] = conversations.NewService(us, ps, conversationDbs, cfg, lg)
+	}
+
+	var lp *lists.ListService
+	lt := reflect.TypeOf(lp)
+	if services[lt] == nil {
+		listDbs := lists.ListDbs{
+			List: listModel, Member: listModel,
+			TimelineCache: timelineModel,
+		}
+		us, _ := services[ut].(*users.UserService)
+		ps, _ := services[pt].(*posts.PostService)
+		services[lt] = lists.NewService(us, ps, listDbs, cfg, lg)
+	}
+
+	var sp *streaming.StreamingService
+	st := reflect.TypeOf(sp)
+	if services[st] == nil {
+		streamingDbs := streaming.StreamingDbs{
+			Stream: streamModel, List: listModel,
+		}
+		us, _ := services[ut].(*users.UserService)
+		ps, _ := services[pt].(*posts.PostService)
+		ns, _ := services[nt].(*notifications.NotificationService)
+		services[st] = streaming.NewService(us, ps, ns, streamingDbs, cfg, lg)
 	}
 }
@@ -0,0 +1,7 @@
+package streaming
+
+import "errors"
+
+var ErrStream = errors.New("Stream")
+var ErrNotPermitted = errors.New("NotPermitted")
+var ErrClosed = errors.New("Closed")
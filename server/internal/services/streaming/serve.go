@@ -0,0 +1,107 @@
+package streaming
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+// filters applying to posts of the stream
+func filterContextOf(stream string) models.FilterContext {
+	switch {
+	case stream == models.Stream_PUBLIC, stream == models.Stream_PUBLIC_LOCAL,
+		strings.HasPrefix(stream, models.StreamOfHashtag("")):
+		return models.Flc_PUBLIC
+	default:
+		return models.Flc_HOME
+	}
+}
+
+// filters of the subscriber applying to posts of the stream, compiled once in a while
+// rather than queried for each event
+func (service *StreamingService) filtersOf(sub *Subscription, stream string) *posts.Filters {
+	context := filterContextOf(stream)
+	now := time.Now()
+	if c, ok := sub.filters[context]; ok && now.Sub(c.date) < filters_ttl {
+		return c.filters
+	}
+	fs := service.post.GetFilters(sub.user, context)
+	sub.filters[context] = cachedFilters{filters: fs, date: now}
+	return fs
+}
+
+// render an event for the subscriber. false if it should not be sent
+func (service *StreamingService) render(sub *Subscription, r raw) (e Event, ok bool) {
+	if r.stream == "" {
+		return Event{Event: "notice", Payload: r.notice}, true
+	}
+	e = Event{Stream: r.stream, Event: string(r.event.Event)}
+	switch r.event.Event {
+	case models.Event_UPDATE:
+		if r.event.Item == nil {
+			return e, false
+		}
+		list := service.post.GetTimelineItems(sub.user, []models.TimelineItem{*r.event.Item})
+		list = service.filtersOf(sub, r.stream).Apply(list)
+		if len(list) == 0 {
+			return e, false
+		}
+		p := list[0]
+		if r.stream != models.StreamOfUser(sub.user) && service.user.IsMuting(sub.user, p.User.Username) {
+			return e, false
+		}
+		e.Payload = p
+	case models.Event_NOTIFICATION:
+		if r.event.Notification == nil {
+			return e, false
+		}
+		g := service.notification.Render(sub.user, r.event.Notification)
+		if g == nil {
+			return e, false
+		}
+		e.Payload = g
+	case models.Event_DELETE:
+		e.Payload = r.event.PostID
+	case models.Event_EDIT:
+		p := service.post.GetByIDs(sub.user, []string{r.event.PostID})[r.event.PostID]
+		if p == nil {
+			return e, false
+		}
+		list := service.filtersOf(sub, r.stream).Apply([]*posts.Post{p})
+		if len(list) == 0 {
+			return e, false
+		}
+		e.Payload = list[0]
+	default:
+		return e, false
+	}
+	return e, true
+}
+
+// deliver events to the client with heartbeats,
+// until sending fails or the subscription is closed
+func (service *StreamingService) Serve(sub *Subscription, send func(e *Event) error, beat func() error) error {
+	defer service.Unsubscribe(sub)
+	ticker := time.NewTicker(HEARTBEAT)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sub.done:
+			return ErrClosed
+		case <-ticker.C:
+			if e := beat(); e != nil {
+				return e
+			}
+		case r := <-sub.ch:
+			ev, ok := service.render(sub, r)
+			if !ok {
+				continue
+			}
+			if e := send(&ev); e != nil {
+				return e
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package streaming
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+type mockingFilterDb struct {
+	models.IFilter
+	queried int
+}
+
+func (db *mockingFilterDb) QueryActiveFilters(user string, context models.FilterContext, now time.Time) ([]*models.Filter, error) {
+	db.queried += 1
+	return []*models.Filter{{ID: "f", Phrase: "spam", Action: models.Fla_HIDE}}, nil
+}
+
+func TestFiltersCached(t *testing.T) {
+	logger := test.NewMockingLogger(t)
+	db := &mockingFilterDb{}
+	ps := posts.NewService(nil, posts.PostDbs{Filter: db}, config.Config{}, logger)
+	service := NewService(nil, ps, nil, StreamingDbs{}, config.Config{}, logger)
+	service.listening.Do(func() {})
+	sub := service.Subscribe("a")
+
+	list := []*posts.Post{{ID: "1", Content: "<p>spam</p>"}, {ID: "2", Content: "<p>ham</p>"}}
+	list = service.filtersOf(sub, models.Stream_PUBLIC).Apply(list)
+	test.AssertEqual(t, 1, len(list))
+	test.AssertEqual(t, "2", list[0].ID)
+
+	// compiled once for events of streams in the same context
+	service.filtersOf(sub, models.StreamOfHashtag("go"))
+	test.AssertEqual(t, 1, db.queried)
+	service.filtersOf(sub, models.StreamOfUser("a"))
+	test.AssertEqual(t, 2, db.queried)
+
+	// and again after a while
+	c := sub.filters[models.Flc_PUBLIC]
+	c.date = c.date.Add(-filters_ttl)
+	sub.filters[models.Flc_PUBLIC] = c
+	service.filtersOf(sub, models.Stream_PUBLIC)
+	test.AssertEqual(t, 3, db.queried)
+	service.filtersOf(sub, models.Stream_PUBLIC_LOCAL)
+	test.AssertEqual(t, 3, db.queried)
+}
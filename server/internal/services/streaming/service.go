@@ -0,0 +1,127 @@
+package streaming
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/notifications"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+	"github.com/kidommoc/gustrody/internal/services/users"
+)
+
+const (
+	HEARTBEAT   = 30 * time.Second
+	buffer_size = 64 // events buffered for a subscription
+	max_dropped = 16 // a subscription too slow to consume is closed after dropping so many events
+	retry_after = 5 * time.Second
+	filters_ttl = time.Minute // filters of a subscription are compiled again after, so changes apply
+)
+
+// event sent to clients
+type Event struct {
+	Stream  string      `json:"stream"`
+	Event   string      `json:"event"`
+	Payload interface{} `json:"payload"`
+}
+
+// service
+
+type StreamingDbs struct {
+	Stream models.IStream
+	List   models.IList
+}
+
+type StreamingService struct {
+	lg           logging.Logger
+	db           StreamingDbs
+	user         *users.UserService
+	post         *posts.PostService
+	notification *notifications.NotificationService
+
+	listening sync.Once
+	mu        sync.RWMutex
+	subs      map[string]map[*Subscription]bool // stream -> subscriptions
+}
+
+func NewService(us *users.UserService, ps *posts.PostService, ns *notifications.NotificationService, dbs StreamingDbs, cfg config.Config, lg logging.Logger) *StreamingService {
+	return &StreamingService{
+		lg:           lg,
+		db:           dbs,
+		user:         us,
+		post:         ps,
+		notification: ns,
+		subs:         make(map[string]map[*Subscription]bool),
+	}
+}
+
+// listen to events from all server processes. resubscribe when failed
+func (service *StreamingService) listen() {
+	logger := service.lg
+	for {
+		e := service.db.Stream.Listen(service.dispatch)
+		logger.Error("[Streaming] Stopped listening. Retry later", e)
+		time.Sleep(retry_after)
+	}
+}
+
+func (service *StreamingService) dispatch(stream string, e models.StreamEvent) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	for sub := range service.subs[stream] {
+		sub.push(raw{stream: stream, event: e})
+	}
+}
+
+func (service *StreamingService) Subscribe(username string) *Subscription {
+	service.listening.Do(func() {
+		go service.listen()
+	})
+	return &Subscription{
+		service: service,
+		user:    username,
+		streams: make(map[string]bool),
+		filters: make(map[models.FilterContext]cachedFilters),
+		ch:      make(chan raw, buffer_size),
+		done:    make(chan struct{}),
+	}
+}
+
+func (service *StreamingService) Unsubscribe(sub *Subscription) {
+	sub.close()
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	sub.closed = true
+	for s := range sub.streams {
+		delete(service.subs[s], sub)
+		if len(service.subs[s]) == 0 {
+			delete(service.subs, s)
+		}
+	}
+}
+
+// streams requested after unsubscribing, such as by a message still being read, are ignored
+func (service *StreamingService) add(sub *Subscription, stream string) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if sub.closed {
+		return
+	}
+	if service.subs[stream] == nil {
+		service.subs[stream] = make(map[*Subscription]bool)
+	}
+	service.subs[stream][sub] = true
+	sub.streams[stream] = true
+}
+
+func (service *StreamingService) remove(sub *Subscription, stream string) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	delete(service.subs[stream], sub)
+	if len(service.subs[stream]) == 0 {
+		delete(service.subs, stream)
+	}
+	delete(sub.streams, stream)
+}
@@ -0,0 +1,128 @@
+package streaming
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+// event from a stream, or a notice to the client when stream is empty
+type raw struct {
+	stream string
+	event  models.StreamEvent
+	notice string
+}
+
+type cachedFilters struct {
+	filters *posts.Filters
+	date    time.Time
+}
+
+type Subscription struct {
+	service *StreamingService
+	user    string
+	streams map[string]bool                        // guarded by service.mu
+	closed  bool                                   // unsubscribed. guarded by service.mu
+	filters map[models.FilterContext]cachedFilters // used only by Serve
+	ch      chan raw
+	done    chan struct{}
+	closing sync.Once
+	dropped int
+	mu      sync.Mutex
+}
+
+// resolve name and parameter of a stream requested by clients.
+//
+//   - "user": home timeline and notifications of the user
+//   - "public", "public:local"
+//   - "hashtag", param: tag
+//   - "list", param: list id, only of the user
+func (sub *Subscription) resolve(name, param string) (stream string, err error) {
+	switch name {
+	case "user":
+		if sub.user == "" {
+			return "", ErrNotPermitted
+		}
+		return models.StreamOfUser(sub.user), nil
+	case "public":
+		return models.Stream_PUBLIC, nil
+	case "public:local":
+		return models.Stream_PUBLIC_LOCAL, nil
+	case "hashtag":
+		if param == "" {
+			return "", ErrStream
+		}
+		return models.StreamOfHashtag(strings.ToLower(param)), nil
+	case "list":
+		if param == "" {
+			return "", ErrStream
+		}
+		if sub.user == "" {
+			return "", ErrNotPermitted
+		}
+		l, e := sub.service.db.List.QueryListByID(param)
+		if e != nil || l.User != sub.user {
+			return "", ErrNotPermitted
+		}
+		return models.StreamOfList(param), nil
+	}
+	return "", ErrStream
+}
+
+func (sub *Subscription) Add(name, param string) error {
+	stream, err := sub.resolve(name, param)
+	if err != nil {
+		return err
+	}
+	sub.service.add(sub, stream)
+	return nil
+}
+
+func (sub *Subscription) Remove(name, param string) error {
+	stream, err := sub.resolve(name, param)
+	if err != nil {
+		return err
+	}
+	sub.service.remove(sub, stream)
+	return nil
+}
+
+// send a notice, such as an error, to the client
+func (sub *Subscription) Notice(msg string) {
+	sub.push(raw{notice: msg})
+}
+
+func (sub *Subscription) Done() <-chan struct{} {
+	return sub.done
+}
+
+// never blocks. events are dropped when the buffer is full,
+// and the subscription is closed when it drops too many
+func (sub *Subscription) push(r raw) {
+	select {
+	case <-sub.done:
+		return
+	case sub.ch <- r:
+		return
+	default:
+	}
+	sub.mu.Lock()
+	sub.dropped += 1
+	tooSlow := sub.dropped > max_dropped
+	sub.mu.Unlock()
+	if tooSlow {
+		sub.service.lg.Warning("[Streaming] Close a subscription too slow",
+			"user", sub.user,
+		)
+		sub.close()
+	}
+}
+
+func (sub *Subscription) close() {
+	sub.closing.Do(func() {
+		close(sub.done)
+	})
+}
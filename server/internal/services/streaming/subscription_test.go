@@ -0,0 +1,120 @@
+package streaming
+
+import (
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+type mockingListDb struct {
+	models.IList
+	data map[string]*models.List
+}
+
+func (db *mockingListDb) QueryListByID(id string) (*models.List, error) {
+	if db.data[id] == nil {
+		return nil, models.ErrNotFound
+	}
+	return db.data[id], nil
+}
+
+func newTestService(t *testing.T) *StreamingService {
+	logger := test.NewMockingLogger(t)
+	lists := &mockingListDb{data: map[string]*models.List{
+		"1": {ID: "1", User: "a"},
+		"2": {ID: "2", User: "b"},
+	}}
+	service := NewService(nil, nil, nil, StreamingDbs{List: lists}, config.Config{}, logger)
+	// not listening in tests
+	service.listening.Do(func() {})
+	return service
+}
+
+func TestResolve(t *testing.T) {
+	service := newTestService(t)
+	sub := service.Subscribe("a")
+	cases := []struct {
+		name   string
+		param  string
+		stream string
+		err    error
+	}{
+		{"user", "", models.StreamOfUser("a"), nil},
+		{"public", "", models.Stream_PUBLIC, nil},
+		{"public:local", "", models.Stream_PUBLIC_LOCAL, nil},
+		{"hashtag", "Go", models.StreamOfHashtag("go"), nil},
+		{"hashtag", "", "", ErrStream},
+		{"list", "1", models.StreamOfList("1"), nil},
+		{"list", "2", "", ErrNotPermitted},
+		{"list", "3", "", ErrNotPermitted},
+		{"list", "", "", ErrStream},
+		{"somewhere", "", "", ErrStream},
+	}
+	for _, v := range cases {
+		stream, err := sub.resolve(v.name, v.param)
+		test.AssertEqual(t, v.err, err)
+		test.AssertEqual(t, v.stream, stream)
+	}
+
+	anonymous := service.Subscribe("")
+	_, err := anonymous.resolve("user", "")
+	test.AssertEqual(t, ErrNotPermitted, err)
+	_, err = anonymous.resolve("list", "1")
+	test.AssertEqual(t, ErrNotPermitted, err)
+}
+
+func TestDispatch(t *testing.T) {
+	service := newTestService(t)
+	a := service.Subscribe("a")
+	b := service.Subscribe("b")
+	test.AssertNoError(t, a.Add("public", ""))
+	test.AssertNoError(t, b.Add("user", ""))
+
+	e := models.StreamEvent{Event: models.Event_DELETE, PostID: "p"}
+	service.dispatch(models.Stream_PUBLIC, e)
+	service.dispatch(models.StreamOfUser("a"), e)
+	test.AssertEqual(t, 1, len(a.ch))
+	test.AssertEqual(t, 0, len(b.ch))
+
+	service.Unsubscribe(a)
+	service.dispatch(models.Stream_PUBLIC, e)
+	test.AssertEqual(t, 1, len(a.ch))
+	test.AssertEqual(t, 1, len(service.subs))
+}
+
+func TestAddAfterUnsubscribe(t *testing.T) {
+	service := newTestService(t)
+	sub := service.Subscribe("a")
+	test.AssertNoError(t, sub.Add("public", ""))
+	service.Unsubscribe(sub)
+
+	// a subscribe message read before the connection closed
+	test.AssertNoError(t, sub.Add("user", ""))
+	test.AssertEqual(t, 0, len(service.subs))
+	test.AssertEqual(t, false, sub.streams[models.StreamOfUser("a")])
+}
+
+func TestSlowSubscription(t *testing.T) {
+	service := newTestService(t)
+	sub := service.Subscribe("a")
+	test.AssertNoError(t, sub.Add("public", ""))
+
+	e := models.StreamEvent{Event: models.Event_DELETE, PostID: "p"}
+	for i := 0; i < buffer_size+max_dropped; i++ {
+		service.dispatch(models.Stream_PUBLIC, e)
+	}
+	select {
+	case <-sub.Done():
+		t.Fatal("closed before dropping too many events")
+	default:
+	}
+
+	service.dispatch(models.Stream_PUBLIC, e)
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("not closed after dropping too many events")
+	}
+}
@@ -0,0 +1,8 @@
+package trends
+
+import "errors"
+
+var ErrNotPermitted = errors.New("NotPermitted")
+var ErrTag = errors.New("Tag")
+var ErrLink = errors.New("Link")
+var ErrInternal = errors.New("Internal")
@@ -0,0 +1,56 @@
+package trends
+
+import (
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/logging"
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+// a hashtag trending in public posts
+type TagTrend struct {
+	Name     string `json:"name"` // lowercase, without "#"
+	Url      string `json:"url"`
+	Uses     int64  `json:"uses"`     // in the long window
+	Accounts int64  `json:"accounts"` // using it in the long window
+}
+
+// an url trending in public posts
+type LinkTrend struct {
+	Url      string `json:"url"`
+	Uses     int64  `json:"uses"`     // posts linking it in the long window
+	Accounts int64  `json:"accounts"` // linking it in the long window
+}
+
+// service
+
+type TrendDbs struct {
+	Trend      models.ITrend
+	TrendCache models.ITrendCache
+}
+
+type TrendService struct {
+	lg     logging.Logger
+	site   string
+	admins map[string]bool
+	db     TrendDbs
+	post   *posts.PostService
+}
+
+func NewService(ps *posts.PostService, dbs TrendDbs, cfg config.Config, lg logging.Logger) *TrendService {
+	admins := make(map[string]bool)
+	for _, v := range cfg.Admins {
+		admins[v] = true
+	}
+	return &TrendService{
+		lg:     lg,
+		site:   cfg.Site,
+		admins: admins,
+		db:     dbs,
+		post:   ps,
+	}
+}
+
+func (service *TrendService) IsAdmin(username string) bool {
+	return service.admins[username]
+}
@@ -0,0 +1,217 @@
+package trends
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/posts"
+)
+
+const (
+	trend_short_window = 6 * time.Hour
+	trend_long_window  = 24 * time.Hour
+	trend_candidates   = 40 // computed in each refreshing, for admins to review
+	trend_max          = 10 // surfaced
+	// trends are dropped if not refreshed in time, rather than served stale
+	trend_expire = 30 * time.Minute
+)
+
+// velocity per hour averaged over the windows, discounted when a few users make most of it
+func score(t *models.Trend) float64 {
+	if t.Long == 0 {
+		return 0
+	}
+	velocity := (float64(t.Short)/trend_short_window.Hours() + float64(t.Long)/trend_long_window.Hours()) / 2
+	return velocity * float64(t.Accounts) / float64(t.Long)
+}
+
+// candidates with reviews, without hidden ones, descending by score
+func rank(list []*models.Trend, reviews map[string]bool, now time.Time) []*models.Trend {
+	result := make([]*models.Trend, 0, len(list))
+	for _, v := range list {
+		approved, reviewed := reviews[v.Key]
+		if reviewed && !approved {
+			continue
+		}
+		v.Approved = approved
+		v.Score = score(v)
+		v.Date = now
+		result = append(result, v)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result
+}
+
+// compute trends and cache them. run by a background job
+func (service *TrendService) Refresh(now time.Time) {
+	logger := service.lg
+	// candidates are ranked by the same velocity as score in the query, so none faster is left out
+	queries := map[models.TrendKind]func(now time.Time, short, long time.Duration, limit int) ([]*models.Trend, error){
+		models.Trd_TAG:  service.db.Trend.QueryTagTrends,
+		models.Trd_POST: service.db.Trend.QueryPostTrends,
+		models.Trd_LINK: service.db.Trend.QueryLinkTrends,
+	}
+	for kind, query := range queries {
+		list, e := query(now, trend_short_window, trend_long_window, trend_candidates)
+		if e != nil {
+			msg := fmt.Sprintf("[Trends] Cannot compute trends of %s", kind)
+			logger.Error(msg, e)
+			continue
+		}
+		keys := make([]string, 0, len(list))
+		for _, v := range list {
+			keys = append(keys, v.Key)
+		}
+		reviews, e := service.db.Trend.QueryTrendReviews(kind, keys)
+		if e != nil {
+			msg := fmt.Sprintf("[Trends] Cannot get reviews of %s", kind)
+			logger.Error(msg, e)
+			continue
+		}
+		if e := service.db.TrendCache.SetTrends(kind, rank(list, reviews, now), trend_expire); e != nil {
+			msg := fmt.Sprintf("[Trends] Cannot cache trends of %s", kind)
+			logger.Error(msg, e)
+		}
+	}
+}
+
+// cached trends approved, or pending ones if asked
+func (service *TrendService) cached(kind models.TrendKind, pending bool) []*models.Trend {
+	logger := service.lg
+	list, e := service.db.TrendCache.QueryTrends(kind)
+	if e != nil {
+		if e != models.ErrNotFound {
+			msg := fmt.Sprintf("[Trends] Cannot get trends of %s", kind)
+			logger.Error(msg, e)
+		}
+		return []*models.Trend{}
+	}
+	result := make([]*models.Trend, 0, len(list))
+	for _, v := range list {
+		if v.Approved != pending {
+			result = append(result, v)
+		}
+	}
+	if !pending && len(result) > trend_max {
+		result = result[:trend_max]
+	}
+	return result
+}
+
+// hashtags trending and approved. pending ones only for admins
+//
+// ERRORS
+//
+//   - NotPermitted
+func (service *TrendService) GetTags(username string, pending bool) (list []*TagTrend, err error) {
+	if pending && !service.IsAdmin(username) {
+		return nil, ErrNotPermitted
+	}
+	trends := service.cached(models.Trd_TAG, pending)
+	list = make([]*TagTrend, 0, len(trends))
+	for _, v := range trends {
+		list = append(list, &TagTrend{
+			Name: v.Key, Url: service.site + "/tags/" + v.Key,
+			Uses: v.Long, Accounts: v.Accounts,
+		})
+	}
+	return list, nil
+}
+
+// posts trending and approved, visible to the user. pending ones only for admins
+//
+// ERRORS
+//
+//   - NotPermitted
+func (service *TrendService) GetPosts(username string, pending bool) (list []*posts.Post, err error) {
+	if pending && !service.IsAdmin(username) {
+		return nil, ErrNotPermitted
+	}
+	trends := service.cached(models.Trd_POST, pending)
+	ids := make([]string, 0, len(trends))
+	for _, v := range trends {
+		ids = append(ids, v.Key)
+	}
+	m := service.post.GetByIDs(username, ids)
+	list = make([]*posts.Post, 0, len(ids))
+	for _, id := range ids {
+		if p := m[id]; p != nil {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+// urls trending and approved. pending ones only for admins
+//
+// ERRORS
+//
+//   - NotPermitted
+func (service *TrendService) GetLinks(username string, pending bool) (list []*LinkTrend, err error) {
+	if pending && !service.IsAdmin(username) {
+		return nil, ErrNotPermitted
+	}
+	trends := service.cached(models.Trd_LINK, pending)
+	list = make([]*LinkTrend, 0, len(trends))
+	for _, v := range trends {
+		list = append(list, &LinkTrend{Url: v.Key, Uses: v.Long, Accounts: v.Accounts})
+	}
+	return list, nil
+}
+
+// approve a trend to surface it, or hide it. only by admins
+//
+// ERRORS
+//
+//   - NotPermitted
+//   - Tag
+//   - Link
+//   - Internal
+func (service *TrendService) Review(username string, kind models.TrendKind, key string, approved bool) error {
+	logger := service.lg
+	if !service.IsAdmin(username) {
+		return ErrNotPermitted
+	}
+	switch kind {
+	case models.Trd_TAG:
+		key = strings.ToLower(strings.TrimPrefix(key, "#"))
+		if key == "" {
+			return ErrTag
+		}
+	case models.Trd_LINK:
+		if key == "" {
+			return ErrLink
+		}
+	}
+	if e := service.db.Trend.SetTrendReview(kind, key, approved, username, time.Now()); e != nil {
+		msg := fmt.Sprintf("[Trends] Cannot review %s %s", kind, key)
+		logger.Error(msg, e)
+		return ErrInternal
+	}
+
+	// take effect at once rather than at the next refreshing
+	list, e := service.db.TrendCache.QueryTrends(kind)
+	if e != nil {
+		return nil
+	}
+	result := make([]*models.Trend, 0, len(list))
+	for _, v := range list {
+		if v.Key == key {
+			if !approved {
+				continue
+			}
+			v.Approved = true
+		}
+		result = append(result, v)
+	}
+	if e := service.db.TrendCache.SetTrends(kind, result, trend_expire); e != nil {
+		msg := fmt.Sprintf("[Trends] Cannot cache trends of %s", kind)
+		logger.Error(msg, e)
+	}
+	return nil
+}
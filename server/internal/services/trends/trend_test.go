@@ -0,0 +1,129 @@
+package trends
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestScore(t *testing.T) {
+	// 12 in 6 hours and 24 in a day by 24 users: 2/h and 1/h
+	test.AssertEqual(t, 1.5, score(&models.Trend{Short: 12, Long: 24, Accounts: 24}))
+	// the same made by 6 users
+	test.AssertEqual(t, 0.375, score(&models.Trend{Short: 12, Long: 24, Accounts: 6}))
+	test.AssertEqual(t, 0.0, score(&models.Trend{}))
+}
+
+func TestRank(t *testing.T) {
+	now := time.Now()
+	list := []*models.Trend{
+		{Key: "slow", Short: 0, Long: 24, Accounts: 24},
+		{Key: "fast", Short: 12, Long: 24, Accounts: 24},
+		{Key: "hidden", Short: 24, Long: 48, Accounts: 48},
+		{Key: "approved", Short: 6, Long: 24, Accounts: 24},
+	}
+	reviews := map[string]bool{"hidden": false, "approved": true}
+	result := rank(list, reviews, now)
+	keys := make([]string, 0, len(result))
+	for _, v := range result {
+		keys = append(keys, v.Key)
+	}
+	test.AssertEqual(t, []string{"fast", "approved", "slow"}, keys)
+	test.AssertEqual(t, true, result[1].Approved)
+	test.AssertEqual(t, false, result[0].Approved)
+	test.AssertEqual(t, now, result[0].Date)
+}
+
+type mockingTrendDb struct {
+	models.ITrend
+	models.ITrendCache
+	args    []interface{} // of the last query
+	cached  map[models.TrendKind][]*models.Trend
+	reviews map[string]bool // by kind:key
+}
+
+func (db *mockingTrendDb) QueryTagTrends(now time.Time, short, long time.Duration, limit int) ([]*models.Trend, error) {
+	db.args = []interface{}{now, short, long, limit}
+	return []*models.Trend{
+		{Key: "a", Short: 0, Long: 24, Accounts: 24},
+		{Key: "b", Short: 12, Long: 24, Accounts: 24},
+	}, nil
+}
+
+func (db *mockingTrendDb) QueryPostTrends(now time.Time, short, long time.Duration, limit int) ([]*models.Trend, error) {
+	db.args = []interface{}{now, short, long, limit}
+	return []*models.Trend{}, nil
+}
+
+func (db *mockingTrendDb) QueryLinkTrends(now time.Time, short, long time.Duration, limit int) ([]*models.Trend, error) {
+	db.args = []interface{}{now, short, long, limit}
+	return []*models.Trend{
+		{Key: "https://a.example/x", Short: 6, Long: 12, Accounts: 12},
+	}, nil
+}
+
+func (db *mockingTrendDb) QueryTrendReviews(kind models.TrendKind, keys []string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+func (db *mockingTrendDb) SetTrendReview(kind models.TrendKind, key string, approved bool, admin string, date time.Time) error {
+	db.reviews[string(kind)+":"+key] = approved
+	return nil
+}
+
+func (db *mockingTrendDb) QueryTrends(kind models.TrendKind) ([]*models.Trend, error) {
+	if db.cached[kind] == nil {
+		return nil, models.ErrNotFound
+	}
+	return db.cached[kind], nil
+}
+
+func (db *mockingTrendDb) SetTrends(kind models.TrendKind, list []*models.Trend, exp time.Duration) error {
+	db.cached[kind] = list
+	return nil
+}
+
+func TestRefresh(t *testing.T) {
+	db := &mockingTrendDb{cached: make(map[models.TrendKind][]*models.Trend)}
+	service := &TrendService{lg: test.NewMockingLogger(t), db: TrendDbs{Trend: db, TrendCache: db}}
+	now := time.Now()
+	service.Refresh(now)
+
+	// candidates are queried by the windows scored
+	test.AssertEqual(t, []interface{}{now, trend_short_window, trend_long_window, trend_candidates}, db.args)
+	test.AssertEqual(t, 2, len(db.cached[models.Trd_TAG]))
+	test.AssertEqual(t, "b", db.cached[models.Trd_TAG][0].Key)
+	test.AssertEqual(t, 0, len(db.cached[models.Trd_POST]))
+	test.AssertEqual(t, 1, len(db.cached[models.Trd_LINK]))
+}
+
+func TestLinks(t *testing.T) {
+	db := &mockingTrendDb{
+		cached:  make(map[models.TrendKind][]*models.Trend),
+		reviews: make(map[string]bool),
+	}
+	service := &TrendService{
+		lg: test.NewMockingLogger(t), admins: map[string]bool{"admin": true},
+		db: TrendDbs{Trend: db, TrendCache: db},
+	}
+	service.Refresh(time.Now())
+
+	// pending until approved
+	list, err := service.GetLinks("", false)
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 0, len(list))
+	_, err = service.GetLinks("u1", true)
+	test.AssertEqual(t, ErrNotPermitted, err)
+	list, err = service.GetLinks("admin", true)
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []*LinkTrend{{Url: "https://a.example/x", Uses: 12, Accounts: 12}}, list)
+
+	test.AssertEqual(t, ErrLink, service.Review("admin", models.Trd_LINK, "", true))
+	test.AssertNoError(t, service.Review("admin", models.Trd_LINK, "https://a.example/x", true))
+	test.AssertEqual(t, true, db.reviews["link:https://a.example/x"])
+	list, err = service.GetLinks("", false)
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 1, len(list))
+}
@@ -8,8 +8,10 @@ import (
 )
 
 type Preferences struct {
-	PostVsb  utils.Vsb `json:"postVsb"`
-	ShareVsb utils.Vsb `json:"shareVsb"`
+	PostVsb   utils.Vsb `json:"postVsb"`
+	ShareVsb  utils.Vsb `json:"shareVsb"`
+	Sensitive bool      `json:"sensitive"` // mark media of posts sensitive by default
+	NoSearch  bool      `json:"noSearch"`  // posts are not found by others in search
 }
 
 // DB: Account, Auth
@@ -133,12 +135,16 @@ func (service *UserService) GetPreferences(username string) (pf Preferences, err
 	}
 	pf.PostVsb, _ = utils.GetVsb(mpf.PostVsb)
 	pf.ShareVsb, _ = utils.GetVsb(mpf.ShareVsb)
+	pf.Sensitive = mpf.Sensitive
+	pf.NoSearch = mpf.NoSearch
 	return pf, nil
 }
 
 type PreferenceBody struct {
-	PostVsb  *string `json:"postVsb,omitempty"`
-	ShareVsb *string `json:"shareVsb,omitempty"`
+	PostVsb   *string `json:"postVsb,omitempty"`
+	ShareVsb  *string `json:"shareVsb,omitempty"`
+	Sensitive *bool   `json:"sensitive,omitempty"`
+	NoSearch  *bool   `json:"noSearch,omitempty"`
 }
 
 func (service *UserService) UpdatePreferences(username string, body *PreferenceBody) error {
@@ -168,6 +174,12 @@ func (service *UserService) UpdatePreferences(username string, body *PreferenceB
 			pf.ShareVsb = v.String()
 		}
 	}
+	if body.Sensitive != nil {
+		pf.Sensitive = *body.Sensitive
+	}
+	if body.NoSearch != nil {
+		pf.NoSearch = *body.NoSearch
+	}
 
 	if err := service.db.Account.UpdateUserPreferences(username, pf); err != nil {
 		msg := fmt.Sprintf("[Users.Account] Failed to update preferences of %s.", username)
@@ -94,3 +94,86 @@ func (db *MockingAuthDb) SetUserPassword(username string, password string) error
 	db.data[username] = password
 	return nil
 }
+
+// Follow DB
+
+type MockingFollowDb struct {
+	models.IUserFollow
+	data map[string]bool // "from>to"
+}
+
+func newMockingFollowDb() *MockingFollowDb {
+	return &MockingFollowDb{data: make(map[string]bool)}
+}
+
+func (db *MockingFollowDb) SetFollow(from string, to string) error {
+	if db.data[from+">"+to] {
+		return models.ErrDunplicate
+	}
+	db.data[from+">"+to] = true
+	return nil
+}
+
+func (db *MockingFollowDb) RemoveFollow(from string, to string) error {
+	if !db.data[from+">"+to] {
+		return models.ErrNotFound
+	}
+	delete(db.data, from+">"+to)
+	return nil
+}
+
+// List DB
+
+type MockingListDb struct {
+	models.IListMember
+	models.IListTimeline
+	members map[string][]string // list -> members, of one owner
+	evicted []string
+}
+
+func newMockingListDb() *MockingListDb {
+	return &MockingListDb{members: make(map[string][]string)}
+}
+
+func (db *MockingListDb) RemoveMemberFromLists(owner, member string) (ids []string, err error) {
+	ids = make([]string, 0)
+	for id, ms := range db.members {
+		for i, m := range ms {
+			if m == member {
+				db.members[id] = append(ms[:i], ms[i+1:]...)
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (db *MockingListDb) RemoveListTimeline(list string) error {
+	db.evicted = append(db.evicted, list)
+	return nil
+}
+
+// Timeline DB
+
+type MockingTimelineDb struct {
+	models.ITimeline
+	evicted []string
+}
+
+func (db *MockingTimelineDb) RemoveTimeline(user string) error {
+	db.evicted = append(db.evicted, user)
+	return nil
+}
+
+// Notification DB
+
+type MockingNotificationDb struct {
+	models.INotification
+	notified []models.Notification
+}
+
+func (db *MockingNotificationDb) SetNotification(n *models.Notification) error {
+	db.notified = append(db.notified, *n)
+	return nil
+}
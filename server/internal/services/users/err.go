@@ -8,4 +8,7 @@ var ErrSyntax = errors.New("Syntax")
 var ErrFollowFromNotFound = errors.New("FollowFromNotFound")
 var ErrFollowToNotFound = errors.New("FollowToNotFound")
 var ErrSelfFollow = errors.New("SelfFollow")
+var ErrSelfMute = errors.New("SelfMute")
+var ErrMuteToNotFound = errors.New("MuteToNotFound")
+var ErrQuery = errors.New("Query")
 var ErrInternal = errors.New("Internal")
@@ -24,6 +24,18 @@ func (service *UserService) Follow(actor, target string) error {
 			return ErrInternal
 		}
 	}
+	service.evictHome(actor)
+	n := models.Notification{User: target, Type: models.Ntf_FOLLOW, Actor: actor}
+	if err := service.db.Notification.SetNotification(&n); err != nil {
+		logger.Error("[Users.Follow] Cannot notify", err)
+		return nil
+	}
+	if n.ID != 0 {
+		e := models.StreamEvent{Event: models.Event_NOTIFICATION, Notification: &n}
+		if err := service.db.Stream.Publish([]string{models.StreamOfUser(target)}, e); err != nil {
+			logger.Error("[Users.Follow] Cannot publish notification", err)
+		}
+	}
 	return nil
 }
 
@@ -47,5 +59,26 @@ func (service *UserService) Unfollow(actor, target string) error {
 			return ErrInternal
 		}
 	}
+	service.evictHome(actor)
+	// lists only have users followed. their timelines are rebuilt without the posts
+	ids, err := service.db.List.RemoveMemberFromLists(actor, target)
+	if err != nil {
+		logger.Error("[Users.Follow] Cannot remove from lists", err)
+		return nil
+	}
+	for _, id := range ids {
+		if err := service.db.ListTimelineCache.RemoveListTimeline(id); err != nil {
+			logger.Error("[Users.Follow] Cannot evict list timeline", err)
+		}
+	}
 	return nil
 }
+
+// the cached home timeline has posts of the users followed before.
+// rebuilt with the follows changed when read
+func (service *UserService) evictHome(user string) {
+	logger := service.lg
+	if err := service.db.TimelineCache.RemoveTimeline(user); err != nil {
+		logger.Error("[Users.Follow] Cannot evict home timeline", err)
+	}
+}
@@ -0,0 +1,57 @@
+package users
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/kidommoc/gustrody/internal/config"
+	"github.com/kidommoc/gustrody/internal/test"
+)
+
+func TestUnfollowEvictsLists(t *testing.T) {
+	u := newUdb(t)
+	follow := newMockingFollowDb()
+	list := newMockingListDb()
+	home := &MockingTimelineDb{}
+	service := NewService(UserDbs{
+		Info: newMockingInfoDb(u), Follow: follow, TimelineCache: home,
+		List: list, ListTimelineCache: list,
+	}, config.Config{}, test.NewMockingLogger(t))
+	follow.data["a>b"] = true
+	list.members["l1"] = []string{"b", "c"}
+	list.members["l2"] = []string{"c"}
+	list.members["l3"] = []string{"b"}
+
+	err := service.Unfollow("a", "b")
+	test.AssertNoError(t, err)
+	sort.Strings(list.evicted)
+	test.AssertEqual(t, []string{"l1", "l3"}, list.evicted)
+	test.AssertEqual(t, []string{"c"}, list.members["l1"])
+	test.AssertEqual(t, []string{"a"}, home.evicted)
+
+	// nothing to evict when not following
+	err = service.Unfollow("a", "b")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 2, len(list.evicted))
+}
+
+func TestFollowEvictsHome(t *testing.T) {
+	u := newUdb(t)
+	follow := newMockingFollowDb()
+	home := &MockingTimelineDb{}
+	ntf := &MockingNotificationDb{}
+	service := NewService(UserDbs{
+		Info: newMockingInfoDb(u), Follow: follow,
+		TimelineCache: home, Notification: ntf,
+	}, config.Config{}, test.NewMockingLogger(t))
+
+	err := service.Follow("a", "b")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, []string{"a"}, home.evicted)
+	test.AssertEqual(t, 1, len(ntf.notified))
+
+	// nothing to evict when following already
+	err = service.Follow("a", "b")
+	test.AssertNoError(t, err)
+	test.AssertEqual(t, 1, len(home.evicted))
+}
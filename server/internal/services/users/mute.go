@@ -0,0 +1,52 @@
+package users
+
+import (
+	"github.com/kidommoc/gustrody/internal/models"
+)
+
+func (service *UserService) IsMuting(username, target string) bool {
+	return service.db.Mute.IsMuting(username, target)
+}
+
+func (service *UserService) Mute(actor, target string) error {
+	if actor == target {
+		return ErrSelfMute
+	}
+	if !service.db.Info.IsUserExist(actor) {
+		return ErrUserNotFound
+	}
+	if !service.db.Info.IsUserExist(target) {
+		return ErrMuteToNotFound
+	}
+	logger := service.lg
+	if err := service.db.Mute.SetMute(actor, target); err != nil {
+		switch err {
+		case models.ErrDunplicate:
+			return nil
+		default:
+			logger.Error("[Users.Mute] Db error", err)
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+func (service *UserService) Unmute(actor, target string) error {
+	if actor == target {
+		return ErrSelfMute
+	}
+	if !service.db.Info.IsUserExist(actor) {
+		return ErrUserNotFound
+	}
+	logger := service.lg
+	if err := service.db.Mute.RemoveMute(actor, target); err != nil {
+		switch err {
+		case models.ErrNotFound:
+			return nil
+		default:
+			logger.Error("[Users.Mute] Db error", err)
+			return ErrInternal
+		}
+	}
+	return nil
+}
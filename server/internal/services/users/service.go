@@ -4,13 +4,15 @@ import (
 	"github.com/kidommoc/gustrody/internal/config"
 	"github.com/kidommoc/gustrody/internal/logging"
 	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/emojis"
 )
 
 type UserInfo struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Nickname string `json:"nickname"`
-	Avatar   string `json:"avatar"`
+	ID       string          `json:"id"`
+	Username string          `json:"username"`
+	Nickname string          `json:"nickname"`
+	Avatar   string          `json:"avatar"`
+	Emojis   []*emojis.Emoji `json:"emojis,omitempty"` // custom emoji in the nickname
 }
 
 type UserProfile struct {
@@ -29,7 +31,17 @@ type UserDbs struct {
 	Account models.IUserAccount
 	Info    models.IUserInfo
 	Follow  models.IUserFollow
+	Mute    models.IUserMute
 	Auth    models.IAuthDb
+	Emoji   models.ICustomEmoji
+	Search  models.IUserSearch
+	List    models.IListMember
+
+	TimelineCache     models.ITimeline
+	ListTimelineCache models.IListTimeline
+
+	Notification models.INotification
+	Stream       models.IStream
 }
 
 type UserService struct {
@@ -1,5 +1,13 @@
 package users
 
+import (
+	"fmt"
+
+	"github.com/kidommoc/gustrody/internal/models"
+	"github.com/kidommoc/gustrody/internal/services/emojis"
+	"github.com/kidommoc/gustrody/internal/utils"
+)
+
 func (service *UserService) IsUserExist(username string) bool {
 	return service.db.Info.IsUserExist(username)
 }
@@ -8,6 +16,18 @@ func (service *UserService) IsFollowing(username, target string) bool {
 	return service.db.Follow.IsFollowing(username, target)
 }
 
+// custom emoji in the nickname
+func (service *UserService) emojisOf(username, nickname string) []*emojis.Emoji {
+	logger := service.lg
+	list, e := emojis.Resolve(service.db.Emoji, emojis.DomainOf(username), nickname)
+	if e != nil {
+		msg := fmt.Sprintf("[User] Cannot get custom emoji of %s", username)
+		logger.Error(msg, e)
+		return nil
+	}
+	return list
+}
+
 func (service *UserService) GetInfo(username string) (info UserInfo, err error) {
 	logger := service.lg
 	u, e := service.db.Info.QueryUser(username)
@@ -18,6 +38,7 @@ func (service *UserService) GetInfo(username string) (info UserInfo, err error)
 	info.ID = service.generateID(u.Username)
 	info.Username = u.Username
 	info.Nickname = u.Nickname
+	info.Emojis = service.emojisOf(u.Username, u.Nickname)
 	return info, nil
 }
 
@@ -33,6 +54,7 @@ func (service *UserService) GetProfile(username string) (info UserProfile, err e
 			ID:       service.generateID(u.Username),
 			Username: u.Username,
 			Nickname: u.Nickname,
+			Emojis:   service.emojisOf(u.Username, u.Nickname),
 		},
 		Summary: u.Summary,
 	}
@@ -43,40 +65,78 @@ func (service *UserService) GetProfile(username string) (info UserProfile, err e
 	return info, nil
 }
 
-func (service *UserService) GetFollowings(username string) (list []*UserInfo, err error) {
-	logger := service.lg
-	l, e := service.db.Follow.QueryUserFollowings(username)
+func (service *UserService) GetFollowings(username string, page utils.Page) (list []*UserInfo, links utils.PageLinks, err error) {
+	l, e := service.db.Follow.QueryUserFollowings(username, page)
 	if e != nil {
-		logger.Error("[User] when GetFollowings", e)
-		return list, ErrUserNotFound
+		return service.followErr("GetFollowings", e)
 	}
+	return service.makeFollows(l, page)
+}
 
-	list = make([]*UserInfo, len(l))
-	for i, u := range l {
-		list[i] = &UserInfo{
-			ID:       service.generateID(u.Username),
-			Username: u.Username,
-			Nickname: u.Nickname,
-		}
+func (service *UserService) GetFollowers(username string, page utils.Page) (list []*UserInfo, links utils.PageLinks, err error) {
+	l, e := service.db.Follow.QueryUserFollowers(username, page)
+	if e != nil {
+		return service.followErr("GetFollowers", e)
 	}
-	return list, nil
+	return service.makeFollows(l, page)
 }
 
-func (service *UserService) GetFollowers(username string) (list []*UserInfo, err error) {
+// followers of this site
+func (service *UserService) GetLocalFollowers(username string) (list []string, err error) {
 	logger := service.lg
-	l, e := service.db.Follow.QueryUserFollowers(username)
+	list, e := service.db.Follow.QueryLocalFollowers(username)
 	if e != nil {
-		logger.Error("[User] when GetFollowers", e)
-		return list, ErrUserNotFound
+		logger.Error("[User] when GetLocalFollowers", e)
+		return nil, ErrInternal
+	}
+	return list, nil
+}
+
+func (service *UserService) followErr(op string, e error) (list []*UserInfo, links utils.PageLinks, err error) {
+	logger := service.lg
+	switch e {
+	case models.ErrNotFound:
+		return nil, links, ErrUserNotFound
+	default:
+		logger.Error("[User] when "+op, e)
+		return nil, links, ErrInternal
 	}
+}
 
-	list = make([]*UserInfo, len(l))
-	for i, u := range l {
-		list[i] = &UserInfo{
-			ID:       service.generateID(u.Username),
-			Username: u.Username,
-			Nickname: u.Nickname,
+// users not found are skipped, while the links are kept
+func (service *UserService) makeFollows(l []*models.UserAct, page utils.Page) (list []*UserInfo, links utils.PageLinks, err error) {
+	logger := service.lg
+	list = make([]*UserInfo, 0, len(l))
+	cursors := make([]utils.Cursor, 0, len(l))
+	for _, v := range l {
+		cursors = append(cursors, v.Cursor())
+		u, e := service.GetInfo(v.User)
+		if e != nil {
+			logger.Error("[User] Cannot find user", e)
+			continue
 		}
+		list = append(list, &u)
 	}
-	return list, nil
+	return list, page.Links(cursors), nil
+}
+
+// users of this site matching the full-text query
+//
+// ERRORS
+//
+//   - Query
+//   - Internal
+func (service *UserService) Search(query string, page utils.Page) (list []*UserInfo, links utils.PageLinks, err error) {
+	logger := service.lg
+	q, ok := utils.ParseQuery(query)
+	if !ok {
+		return nil, links, ErrQuery
+	}
+	l, e := service.db.Search.SearchUsers(q, page)
+	if e != nil {
+		msg := fmt.Sprintf("[User] Cannot search users of \"%s\"", q)
+		logger.Error(msg, e)
+		return nil, links, ErrInternal
+	}
+	return service.makeFollows(l, page)
 }
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"html"
+	"net/url"
+	"strings"
+
+	_html "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// rel of links to other sites
+const LinkRel = "nofollow noopener noreferrer"
+
+// elements kept
+var allowedTags = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Span: true, atom.A: true,
+	atom.Del: true, atom.Pre: true, atom.Code: true, atom.Blockquote: true,
+	atom.Em: true, atom.Strong: true, atom.B: true, atom.I: true, atom.U: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true,
+}
+
+// attributes kept on elements
+var allowedAttrs = map[atom.Atom][]string{
+	atom.A:    {"href", "class"},
+	atom.Span: {"class"},
+}
+
+// elements dropped with what's inside
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+	atom.Embed: true, atom.Template: true, atom.Noscript: true, atom.Title: true,
+	atom.Textarea: true, atom.Select: true, atom.Svg: true, atom.Math: true,
+	atom.Head: true,
+}
+
+// classes kept, used by microformats of mentions and hashtags
+var allowedClasses = map[string]bool{
+	"h-card": true, "u-url": true, "mention": true, "hashtag": true,
+	"invisible": true, "ellipsis": true,
+}
+
+func IsSafeUrl(s string) bool {
+	u, e := url.Parse(s)
+	if e != nil {
+		return false
+	}
+	return u.Scheme == "http" || u.Scheme == "https"
+}
+
+func sanitizeAttr(attr _html.Attribute) (value string, ok bool) {
+	switch attr.Key {
+	case "href":
+		return attr.Val, IsSafeUrl(attr.Val)
+	case "class":
+		cs := make([]string, 0)
+		for _, c := range strings.Fields(attr.Val) {
+			if allowedClasses[c] {
+				cs = append(cs, c)
+			}
+		}
+		return strings.Join(cs, " "), len(cs) != 0
+	}
+	return "", false
+}
+
+// keep elements and attributes allowed only, dropping scripts, styles and
+// unsafe links. text is kept and escaped. unclosed elements are closed
+func SanitizeHTML(s string) string {
+	var b strings.Builder
+	z := _html.NewTokenizer(strings.NewReader(s))
+	opened := make([]atom.Atom, 0)
+	dropping := atom.Atom(0)
+	depth := 0
+	for {
+		tt := z.Next()
+		if tt == _html.ErrorToken {
+			break
+		}
+		t := z.Token()
+		if dropping != 0 {
+			switch {
+			case tt == _html.StartTagToken && t.DataAtom == dropping:
+				depth += 1
+			case tt == _html.EndTagToken && t.DataAtom == dropping:
+				depth -= 1
+				if depth == 0 {
+					dropping = 0
+				}
+			}
+			continue
+		}
+		switch tt {
+		case _html.TextToken:
+			b.WriteString(html.EscapeString(t.Data))
+		case _html.StartTagToken, _html.SelfClosingTagToken:
+			if droppedTags[t.DataAtom] {
+				if tt == _html.StartTagToken {
+					dropping, depth = t.DataAtom, 1
+				}
+				continue
+			}
+			if !allowedTags[t.DataAtom] {
+				continue
+			}
+			keys := allowedAttrs[t.DataAtom]
+			b.WriteString("<" + t.DataAtom.String())
+			for _, a := range t.Attr {
+				allowed := false
+				for _, k := range keys {
+					if a.Namespace == "" && a.Key == k {
+						allowed = true
+					}
+				}
+				if !allowed {
+					continue
+				}
+				if v, ok := sanitizeAttr(a); ok {
+					b.WriteString(" " + a.Key + `="` + html.EscapeString(v) + `"`)
+				}
+			}
+			if t.DataAtom == atom.A {
+				// rel is set by us. "tag" of hashtags is kept
+				rel := LinkRel
+				if strings.Contains(" "+attrOf(t, "rel")+" ", " tag ") {
+					rel = "tag " + rel
+				}
+				b.WriteString(` rel="` + rel + `" target="_blank"`)
+			}
+			b.WriteString(">")
+			if t.DataAtom != atom.Br && tt == _html.StartTagToken {
+				opened = append(opened, t.DataAtom)
+			}
+		case _html.EndTagToken:
+			// close up to the matching element, ignoring stray ones
+			for i := len(opened) - 1; i >= 0; i -= 1 {
+				if opened[i] != t.DataAtom {
+					continue
+				}
+				for j := len(opened) - 1; j >= i; j -= 1 {
+					b.WriteString("</" + opened[j].String() + ">")
+				}
+				opened = opened[:i]
+				break
+			}
+		}
+	}
+	for i := len(opened) - 1; i >= 0; i -= 1 {
+		b.WriteString("</" + opened[i].String() + ">")
+	}
+	return b.String()
+}
+
+func attrOf(t _html.Token, key string) string {
+	for _, a := range t.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
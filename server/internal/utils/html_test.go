@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"<p>hi <b>there</b></p>", "<p>hi <b>there</b></p>"},
+		{`<p onclick="x()">a<script>alert(1)</script>b</p>`, "<p>ab</p>"},
+		{"<style>p{}</style><div>text</div>", "text"},
+		{`<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer" target="_blank">x</a>`},
+		{
+			`<a href="https://a.sns/tags/go" class="mention hashtag evil" rel="tag">#go</a>`,
+			`<a href="https://a.sns/tags/go" class="mention hashtag" rel="tag nofollow noopener noreferrer" target="_blank">#go</a>`,
+		},
+		{"<p>unclosed <em>em", "<p>unclosed <em>em</em></p>"},
+		{"stray</p> <img src=x onerror=y>", "stray "},
+		{"a &lt;b&gt; &amp; c", "a &lt;b&gt; &amp; c"},
+	}
+	for _, v := range cases {
+		if got := SanitizeHTML(v.in); got != v.want {
+			t.Errorf("%s: want %s, got %s", v.in, v.want, got)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Page_DEFAULT = 20
+	Page_MAX     = 40
+)
+
+// position of an item in a list ordered by (date, id). id breaks ties of date
+type Cursor struct {
+	Date time.Time
+	ID   string
+}
+
+func (c Cursor) IsZero() bool {
+	return c.Date.IsZero() && c.ID == ""
+}
+
+// opaque to clients. base64 of "<unix microseconds>_<id>"
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	s := strconv.FormatInt(c.Date.UnixMicro(), 10) + "_" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func ParseCursor(s string) (c Cursor, ok bool) {
+	if s == "" {
+		return c, true
+	}
+	b, e := base64.RawURLEncoding.DecodeString(s)
+	if e != nil {
+		return c, false
+	}
+	fields := strings.SplitN(string(b), "_", 2)
+	if len(fields) != 2 {
+		return c, false
+	}
+	us, e := strconv.ParseInt(fields[0], 10, 64)
+	if e != nil || us < 0 {
+		return c, false
+	}
+	c.Date = time.UnixMicro(us).UTC()
+	c.ID = fields[1]
+	return c, true
+}
+
+// a page of a list descending by (date, id)
+//
+//   - Max: items before it
+//   - Since: items after it, from the newest
+//   - Min: items after it, from the one right after it. prior to Since
+type Page struct {
+	Max   Cursor
+	Since Cursor
+	Min   Cursor
+	Limit int
+}
+
+// limit <= 0 means the default. limit is clamped to Page_MAX
+func ParsePage(maxID, sinceID, minID string, limit int) (p Page, ok bool) {
+	if p.Max, ok = ParseCursor(maxID); !ok {
+		return p, false
+	}
+	if p.Since, ok = ParseCursor(sinceID); !ok {
+		return p, false
+	}
+	if p.Min, ok = ParseCursor(minID); !ok {
+		return p, false
+	}
+	switch {
+	case limit <= 0:
+		p.Limit = Page_DEFAULT
+	case limit > Page_MAX:
+		p.Limit = Page_MAX
+	default:
+		p.Limit = limit
+	}
+	return p, true
+}
+
+// cursors of the pages around a page queried. zero if absent
+//
+//   - Prev: pass as min_id for newer items
+//   - Next: pass as max_id for older items
+type PageLinks struct {
+	Prev Cursor
+	Next Cursor
+}
+
+// links of a page with cursors of its items, descending
+func (p Page) Links(cursors []Cursor) (links PageLinks) {
+	if len(cursors) == 0 {
+		return links
+	}
+	links.Prev = cursors[0]
+	// older items exist at least up to the cursor when paging up
+	if len(cursors) >= p.Limit || !p.Min.IsZero() || !p.Since.IsZero() {
+		links.Next = cursors[len(cursors)-1]
+	}
+	return links
+}
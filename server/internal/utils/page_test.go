@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	c := Cursor{Date: time.UnixMicro(1700000000123456).UTC(), ID: "abc_def|u@x.sns"}
+	got, ok := ParseCursor(c.String())
+	if !ok || got != c {
+		t.Errorf("want %v, got %v", c, got)
+	}
+
+	for _, s := range []string{"!", "MTIz", "eF9h"} {
+		if _, ok := ParseCursor(s); ok {
+			t.Errorf("%s should be invalid", s)
+		}
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	cases := []struct {
+		limit int
+		want  int
+	}{
+		{0, Page_DEFAULT}, {-1, Page_DEFAULT}, {5, 5}, {Page_MAX + 1, Page_MAX},
+	}
+	for _, v := range cases {
+		p, ok := ParsePage("", "", "", v.limit)
+		if !ok || p.Limit != v.want {
+			t.Errorf("limit %d: want %d, got %d", v.limit, v.want, p.Limit)
+		}
+	}
+}
+
+func TestPageLinks(t *testing.T) {
+	cs := []Cursor{
+		{Date: time.UnixMicro(3), ID: "c"},
+		{Date: time.UnixMicro(2), ID: "b"},
+	}
+	links := Page{Limit: 2}.Links(cs)
+	if links.Prev != cs[0] || links.Next != cs[1] {
+		t.Errorf("full page: got %v", links)
+	}
+	links = Page{Limit: 3}.Links(cs)
+	if links.Prev != cs[0] || !links.Next.IsZero() {
+		t.Errorf("last page: got %v", links)
+	}
+	links = Page{Limit: 3}.Links(nil)
+	if !links.Prev.IsZero() || !links.Next.IsZero() {
+		t.Errorf("empty page: got %v", links)
+	}
+}
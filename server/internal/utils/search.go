@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const Query_MAX = 200 // characters
+
+// full-text query trimmed. empty or too long ones are invalid
+func ParseQuery(q string) (query string, ok bool) {
+	query = strings.TrimSpace(q)
+	if query == "" || utf8.RuneCountInString(query) > Query_MAX {
+		return "", false
+	}
+	return query, true
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		q    string
+		want string
+		ok   bool
+	}{
+		{"  cat pictures ", "cat pictures", true},
+		{"\"exact phrase\" -dog", "\"exact phrase\" -dog", true},
+		{"   ", "", false},
+		{strings.Repeat("猫", Query_MAX), strings.Repeat("猫", Query_MAX), true},
+		{strings.Repeat("a", Query_MAX+1), "", false},
+	}
+	for _, v := range cases {
+		got, ok := ParseQuery(v.q)
+		if got != v.want || ok != v.ok {
+			t.Errorf("%q: want %q %v, got %q %v", v.q, v.want, v.ok, got, ok)
+		}
+	}
+}
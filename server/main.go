@@ -17,6 +17,7 @@ func main() {
 	db.Init()
 	models.Init()
 	services.Init()
+	services.StartJobs()
 
 	app := fiber.New()
 	router.Route(app)